@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: user/v1/auth.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AuthRule 方法的访问控制规则，服务启动时读取，由鉴权拦截器执行
+type AuthRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Public        bool                   `protobuf:"varint,1,opt,name=public,proto3" json:"public,omitempty"`     // 为 true 时不需要登录即可访问
+	Roles         []string               `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`        // 允许访问的角色，为空时任意已登录用户都可以访问
+	Internal      bool                   `protobuf:"varint,3,opt,name=internal,proto3" json:"internal,omitempty"` // 为 true 时只允许其他服务使用服务凭证调用，不接受用户的访问令牌
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthRule) Reset() {
+	*x = AuthRule{}
+	mi := &file_user_v1_auth_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthRule) ProtoMessage() {}
+
+func (x *AuthRule) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_auth_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthRule.ProtoReflect.Descriptor instead.
+func (*AuthRule) Descriptor() ([]byte, []int) {
+	return file_user_v1_auth_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthRule) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *AuthRule) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *AuthRule) GetInternal() bool {
+	if x != nil {
+		return x.Internal
+	}
+	return false
+}
+
+var file_user_v1_auth_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*AuthRule)(nil),
+		Field:         50001,
+		Name:          "user.v1.auth",
+		Tag:           "bytes,50001,opt,name=auth",
+		Filename:      "user/v1/auth.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional user.v1.AuthRule auth = 50001;
+	E_Auth = &file_user_v1_auth_proto_extTypes[0]
+)
+
+var File_user_v1_auth_proto protoreflect.FileDescriptor
+
+const file_user_v1_auth_proto_rawDesc = "" +
+	"\n" +
+	"\x12user/v1/auth.proto\x12\auser.v1\x1a google/protobuf/descriptor.proto\"T\n" +
+	"\bAuthRule\x12\x16\n" +
+	"\x06public\x18\x01 \x01(\bR\x06public\x12\x14\n" +
+	"\x05roles\x18\x02 \x03(\tR\x05roles\x12\x1a\n" +
+	"\binternal\x18\x03 \x01(\bR\binternal:G\n" +
+	"\x04auth\x12\x1e.google.protobuf.MethodOptions\x18ц\x03 \x01(\v2\x11.user.v1.AuthRuleR\x04authB1Z/github.com/kyson/e-shop/api/protobuf/user/v1;v1b\x06proto3"
+
+var (
+	file_user_v1_auth_proto_rawDescOnce sync.Once
+	file_user_v1_auth_proto_rawDescData []byte
+)
+
+func file_user_v1_auth_proto_rawDescGZIP() []byte {
+	file_user_v1_auth_proto_rawDescOnce.Do(func() {
+		file_user_v1_auth_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_user_v1_auth_proto_rawDesc), len(file_user_v1_auth_proto_rawDesc)))
+	})
+	return file_user_v1_auth_proto_rawDescData
+}
+
+var file_user_v1_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_user_v1_auth_proto_goTypes = []any{
+	(*AuthRule)(nil),                   // 0: user.v1.AuthRule
+	(*descriptorpb.MethodOptions)(nil), // 1: google.protobuf.MethodOptions
+}
+var file_user_v1_auth_proto_depIdxs = []int32{
+	1, // 0: user.v1.auth:extendee -> google.protobuf.MethodOptions
+	0, // 1: user.v1.auth:type_name -> user.v1.AuthRule
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	1, // [1:2] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_user_v1_auth_proto_init() }
+func file_user_v1_auth_proto_init() {
+	if File_user_v1_auth_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_v1_auth_proto_rawDesc), len(file_user_v1_auth_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_user_v1_auth_proto_goTypes,
+		DependencyIndexes: file_user_v1_auth_proto_depIdxs,
+		MessageInfos:      file_user_v1_auth_proto_msgTypes,
+		ExtensionInfos:    file_user_v1_auth_proto_extTypes,
+	}.Build()
+	File_user_v1_auth_proto = out.File
+	file_user_v1_auth_proto_goTypes = nil
+	file_user_v1_auth_proto_depIdxs = nil
+}
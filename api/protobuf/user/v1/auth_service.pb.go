@@ -0,0 +1,268 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: user/v1/auth_service.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IntrospectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TokenTypeHint string                 `protobuf:"bytes,2,opt,name=token_type_hint,json=tokenTypeHint,proto3" json:"token_type_hint,omitempty"` // 只支持 access_token，其他值忽略
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectRequest) Reset() {
+	*x = IntrospectRequest{}
+	mi := &file_user_v1_auth_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectRequest) ProtoMessage() {}
+
+func (x *IntrospectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_auth_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectRequest.ProtoReflect.Descriptor instead.
+func (*IntrospectRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_auth_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IntrospectRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *IntrospectRequest) GetTokenTypeHint() string {
+	if x != nil {
+		return x.TokenTypeHint
+	}
+	return ""
+}
+
+type IntrospectReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	TokenType     string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"` // 固定为 Bearer
+	Exp           int64                  `protobuf:"varint,3,opt,name=exp,proto3" json:"exp,omitempty"`                             // 过期时间，Unix 秒
+	Iat           int64                  `protobuf:"varint,4,opt,name=iat,proto3" json:"iat,omitempty"`                             // 签发时间，Unix 秒
+	Sub           string                 `protobuf:"bytes,5,opt,name=sub,proto3" json:"sub,omitempty"`                              // 用户 ID
+	Username      string                 `protobuf:"bytes,6,opt,name=username,proto3" json:"username,omitempty"`
+	Jti           string                 `protobuf:"bytes,7,opt,name=jti,proto3" json:"jti,omitempty"`
+	UserId        int32                  `protobuf:"varint,8,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Roles         []string               `protobuf:"bytes,9,rep,name=roles,proto3" json:"roles,omitempty"`
+	SessionId     string                 `protobuf:"bytes,10,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectReply) Reset() {
+	*x = IntrospectReply{}
+	mi := &file_user_v1_auth_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectReply) ProtoMessage() {}
+
+func (x *IntrospectReply) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_auth_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectReply.ProtoReflect.Descriptor instead.
+func (*IntrospectReply) Descriptor() ([]byte, []int) {
+	return file_user_v1_auth_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IntrospectReply) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *IntrospectReply) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *IntrospectReply) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *IntrospectReply) GetIat() int64 {
+	if x != nil {
+		return x.Iat
+	}
+	return 0
+}
+
+func (x *IntrospectReply) GetSub() string {
+	if x != nil {
+		return x.Sub
+	}
+	return ""
+}
+
+func (x *IntrospectReply) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *IntrospectReply) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *IntrospectReply) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *IntrospectReply) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *IntrospectReply) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+var File_user_v1_auth_service_proto protoreflect.FileDescriptor
+
+const file_user_v1_auth_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1auser/v1/auth_service.proto\x12\auser.v1\x1a\x12user/v1/auth.proto\"Q\n" +
+	"\x11IntrospectRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12&\n" +
+	"\x0ftoken_type_hint\x18\x02 \x01(\tR\rtokenTypeHint\"\xfa\x01\n" +
+	"\x0fIntrospectReply\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\x12\x10\n" +
+	"\x03exp\x18\x03 \x01(\x03R\x03exp\x12\x10\n" +
+	"\x03iat\x18\x04 \x01(\x03R\x03iat\x12\x10\n" +
+	"\x03sub\x18\x05 \x01(\tR\x03sub\x12\x1a\n" +
+	"\busername\x18\x06 \x01(\tR\busername\x12\x10\n" +
+	"\x03jti\x18\a \x01(\tR\x03jti\x12\x17\n" +
+	"\auser_id\x18\b \x01(\x05R\x06userId\x12\x14\n" +
+	"\x05roles\x18\t \x03(\tR\x05roles\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\n" +
+	" \x01(\tR\tsessionId2Y\n" +
+	"\vAuthService\x12J\n" +
+	"\n" +
+	"Introspect\x12\x1a.user.v1.IntrospectRequest\x1a\x18.user.v1.IntrospectReply\"\x06\x8a\xb5\x18\x02\x18\x01B1Z/github.com/kyson/e-shop/api/protobuf/user/v1;v1b\x06proto3"
+
+var (
+	file_user_v1_auth_service_proto_rawDescOnce sync.Once
+	file_user_v1_auth_service_proto_rawDescData []byte
+)
+
+func file_user_v1_auth_service_proto_rawDescGZIP() []byte {
+	file_user_v1_auth_service_proto_rawDescOnce.Do(func() {
+		file_user_v1_auth_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_user_v1_auth_service_proto_rawDesc), len(file_user_v1_auth_service_proto_rawDesc)))
+	})
+	return file_user_v1_auth_service_proto_rawDescData
+}
+
+var file_user_v1_auth_service_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_user_v1_auth_service_proto_goTypes = []any{
+	(*IntrospectRequest)(nil), // 0: user.v1.IntrospectRequest
+	(*IntrospectReply)(nil),   // 1: user.v1.IntrospectReply
+}
+var file_user_v1_auth_service_proto_depIdxs = []int32{
+	0, // 0: user.v1.AuthService.Introspect:input_type -> user.v1.IntrospectRequest
+	1, // 1: user.v1.AuthService.Introspect:output_type -> user.v1.IntrospectReply
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_user_v1_auth_service_proto_init() }
+func file_user_v1_auth_service_proto_init() {
+	if File_user_v1_auth_service_proto != nil {
+		return
+	}
+	file_user_v1_auth_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_v1_auth_service_proto_rawDesc), len(file_user_v1_auth_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_user_v1_auth_service_proto_goTypes,
+		DependencyIndexes: file_user_v1_auth_service_proto_depIdxs,
+		MessageInfos:      file_user_v1_auth_service_proto_msgTypes,
+	}.Build()
+	File_user_v1_auth_service_proto = out.File
+	file_user_v1_auth_service_proto_goTypes = nil
+	file_user_v1_auth_service_proto_depIdxs = nil
+}
@@ -28,17 +28,36 @@ const (
 	ErrorCode_UNKNOWN ErrorCode = 0
 	// 内部错误
 	ErrorCode_INTERNAL ErrorCode = 1
+	// 请求参数错误
+	ErrorCode_INVALID_ARGUMENT ErrorCode = 2
 	// -- 用户服务错误 (1000-1999) --
-	ErrorCode_USER_NOT_FOUND        ErrorCode = 1001
-	ErrorCode_PASSWORD_INCORRECT    ErrorCode = 1002
-	ErrorCode_USER_ALREADY_EXISTS   ErrorCode = 1003
-	ErrorCode_USERNAME_FORMAT_ERROR ErrorCode = 1004
-	ErrorCode_EMAIL_FORMAT_ERROR    ErrorCode = 1005
-	ErrorCode_PHONE_FORMAT_ERROR    ErrorCode = 1006
-	ErrorCode_PASSWORD_FORMAT_ERROR ErrorCode = 1007
+	ErrorCode_USER_NOT_FOUND                 ErrorCode = 1001
+	ErrorCode_PASSWORD_INCORRECT             ErrorCode = 1002
+	ErrorCode_USER_ALREADY_EXISTS            ErrorCode = 1003
+	ErrorCode_USERNAME_FORMAT_ERROR          ErrorCode = 1004
+	ErrorCode_EMAIL_FORMAT_ERROR             ErrorCode = 1005
+	ErrorCode_PHONE_FORMAT_ERROR             ErrorCode = 1006
+	ErrorCode_PASSWORD_FORMAT_ERROR          ErrorCode = 1007
+	ErrorCode_EMAIL_ALREADY_EXISTS           ErrorCode = 1008
+	ErrorCode_PHONE_ALREADY_EXISTS           ErrorCode = 1009
+	ErrorCode_PASSWORD_REUSED                ErrorCode = 1010
+	ErrorCode_VERIFICATION_CODE_INVALID      ErrorCode = 1011
+	ErrorCode_VERIFICATION_CODE_TOO_FREQUENT ErrorCode = 1012
+	ErrorCode_EMAIL_NOT_VERIFIED             ErrorCode = 1013
+	ErrorCode_ACCOUNT_LOCKED                 ErrorCode = 1014
+	ErrorCode_USER_DISABLED                  ErrorCode = 1015
 	// -- 认证服务错误 (2000-2999) --
-	ErrorCode_TOKEN_INVALID ErrorCode = 2001
-	ErrorCode_TOKEN_EXPIRED ErrorCode = 2002
+	ErrorCode_TOKEN_INVALID              ErrorCode = 2001
+	ErrorCode_TOKEN_EXPIRED              ErrorCode = 2002
+	ErrorCode_REFRESH_TOKEN_INVALID      ErrorCode = 2003
+	ErrorCode_REFRESH_TOKEN_REUSED       ErrorCode = 2004
+	ErrorCode_TOKEN_REVOKED              ErrorCode = 2005
+	ErrorCode_PERMISSION_DENIED          ErrorCode = 2006
+	ErrorCode_SESSION_NOT_FOUND          ErrorCode = 2007
+	ErrorCode_TWO_FACTOR_CODE_INVALID    ErrorCode = 2008
+	ErrorCode_TWO_FACTOR_ALREADY_ENABLED ErrorCode = 2009
+	ErrorCode_TWO_FACTOR_NOT_ENABLED     ErrorCode = 2010
+	ErrorCode_LOGIN_CHALLENGE_INVALID    ErrorCode = 2011
 )
 
 // Enum value maps for ErrorCode.
@@ -46,6 +65,7 @@ var (
 	ErrorCode_name = map[int32]string{
 		0:    "UNKNOWN",
 		1:    "INTERNAL",
+		2:    "INVALID_ARGUMENT",
 		1001: "USER_NOT_FOUND",
 		1002: "PASSWORD_INCORRECT",
 		1003: "USER_ALREADY_EXISTS",
@@ -53,21 +73,56 @@ var (
 		1005: "EMAIL_FORMAT_ERROR",
 		1006: "PHONE_FORMAT_ERROR",
 		1007: "PASSWORD_FORMAT_ERROR",
+		1008: "EMAIL_ALREADY_EXISTS",
+		1009: "PHONE_ALREADY_EXISTS",
+		1010: "PASSWORD_REUSED",
+		1011: "VERIFICATION_CODE_INVALID",
+		1012: "VERIFICATION_CODE_TOO_FREQUENT",
+		1013: "EMAIL_NOT_VERIFIED",
+		1014: "ACCOUNT_LOCKED",
+		1015: "USER_DISABLED",
 		2001: "TOKEN_INVALID",
 		2002: "TOKEN_EXPIRED",
+		2003: "REFRESH_TOKEN_INVALID",
+		2004: "REFRESH_TOKEN_REUSED",
+		2005: "TOKEN_REVOKED",
+		2006: "PERMISSION_DENIED",
+		2007: "SESSION_NOT_FOUND",
+		2008: "TWO_FACTOR_CODE_INVALID",
+		2009: "TWO_FACTOR_ALREADY_ENABLED",
+		2010: "TWO_FACTOR_NOT_ENABLED",
+		2011: "LOGIN_CHALLENGE_INVALID",
 	}
 	ErrorCode_value = map[string]int32{
-		"UNKNOWN":               0,
-		"INTERNAL":              1,
-		"USER_NOT_FOUND":        1001,
-		"PASSWORD_INCORRECT":    1002,
-		"USER_ALREADY_EXISTS":   1003,
-		"USERNAME_FORMAT_ERROR": 1004,
-		"EMAIL_FORMAT_ERROR":    1005,
-		"PHONE_FORMAT_ERROR":    1006,
-		"PASSWORD_FORMAT_ERROR": 1007,
-		"TOKEN_INVALID":         2001,
-		"TOKEN_EXPIRED":         2002,
+		"UNKNOWN":                        0,
+		"INTERNAL":                       1,
+		"INVALID_ARGUMENT":               2,
+		"USER_NOT_FOUND":                 1001,
+		"PASSWORD_INCORRECT":             1002,
+		"USER_ALREADY_EXISTS":            1003,
+		"USERNAME_FORMAT_ERROR":          1004,
+		"EMAIL_FORMAT_ERROR":             1005,
+		"PHONE_FORMAT_ERROR":             1006,
+		"PASSWORD_FORMAT_ERROR":          1007,
+		"EMAIL_ALREADY_EXISTS":           1008,
+		"PHONE_ALREADY_EXISTS":           1009,
+		"PASSWORD_REUSED":                1010,
+		"VERIFICATION_CODE_INVALID":      1011,
+		"VERIFICATION_CODE_TOO_FREQUENT": 1012,
+		"EMAIL_NOT_VERIFIED":             1013,
+		"ACCOUNT_LOCKED":                 1014,
+		"USER_DISABLED":                  1015,
+		"TOKEN_INVALID":                  2001,
+		"TOKEN_EXPIRED":                  2002,
+		"REFRESH_TOKEN_INVALID":          2003,
+		"REFRESH_TOKEN_REUSED":           2004,
+		"TOKEN_REVOKED":                  2005,
+		"PERMISSION_DENIED":              2006,
+		"SESSION_NOT_FOUND":              2007,
+		"TWO_FACTOR_CODE_INVALID":        2008,
+		"TWO_FACTOR_ALREADY_ENABLED":     2009,
+		"TWO_FACTOR_NOT_ENABLED":         2010,
+		"LOGIN_CHALLENGE_INVALID":        2011,
 	}
 )
 
@@ -102,19 +157,37 @@ var File_user_v1_error_code_proto protoreflect.FileDescriptor
 
 const file_user_v1_error_code_proto_rawDesc = "" +
 	"\n" +
-	"\x18user/v1/error_code.proto\x12\rapi.common.v1*\x80\x02\n" +
+	"\x18user/v1/error_code.proto\x12\rapi.common.v1*\xde\x05\n" +
 	"\tErrorCode\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\f\n" +
-	"\bINTERNAL\x10\x01\x12\x13\n" +
+	"\bINTERNAL\x10\x01\x12\x14\n" +
+	"\x10INVALID_ARGUMENT\x10\x02\x12\x13\n" +
 	"\x0eUSER_NOT_FOUND\x10\xe9\a\x12\x17\n" +
 	"\x12PASSWORD_INCORRECT\x10\xea\a\x12\x18\n" +
 	"\x13USER_ALREADY_EXISTS\x10\xeb\a\x12\x1a\n" +
 	"\x15USERNAME_FORMAT_ERROR\x10\xec\a\x12\x17\n" +
 	"\x12EMAIL_FORMAT_ERROR\x10\xed\a\x12\x17\n" +
 	"\x12PHONE_FORMAT_ERROR\x10\xee\a\x12\x1a\n" +
-	"\x15PASSWORD_FORMAT_ERROR\x10\xef\a\x12\x12\n" +
+	"\x15PASSWORD_FORMAT_ERROR\x10\xef\a\x12\x19\n" +
+	"\x14EMAIL_ALREADY_EXISTS\x10\xf0\a\x12\x19\n" +
+	"\x14PHONE_ALREADY_EXISTS\x10\xf1\a\x12\x14\n" +
+	"\x0fPASSWORD_REUSED\x10\xf2\a\x12\x1e\n" +
+	"\x19VERIFICATION_CODE_INVALID\x10\xf3\a\x12#\n" +
+	"\x1eVERIFICATION_CODE_TOO_FREQUENT\x10\xf4\a\x12\x17\n" +
+	"\x12EMAIL_NOT_VERIFIED\x10\xf5\a\x12\x13\n" +
+	"\x0eACCOUNT_LOCKED\x10\xf6\a\x12\x12\n" +
+	"\rUSER_DISABLED\x10\xf7\a\x12\x12\n" +
 	"\rTOKEN_INVALID\x10\xd1\x0f\x12\x12\n" +
-	"\rTOKEN_EXPIRED\x10\xd2\x0fBBZ@github.com/your-username/e-shop-native/api/protobuf/common/v1;v1b\x06proto3"
+	"\rTOKEN_EXPIRED\x10\xd2\x0f\x12\x1a\n" +
+	"\x15REFRESH_TOKEN_INVALID\x10\xd3\x0f\x12\x19\n" +
+	"\x14REFRESH_TOKEN_REUSED\x10\xd4\x0f\x12\x12\n" +
+	"\rTOKEN_REVOKED\x10\xd5\x0f\x12\x16\n" +
+	"\x11PERMISSION_DENIED\x10\xd6\x0f\x12\x16\n" +
+	"\x11SESSION_NOT_FOUND\x10\xd7\x0f\x12\x1c\n" +
+	"\x17TWO_FACTOR_CODE_INVALID\x10\xd8\x0f\x12\x1f\n" +
+	"\x1aTWO_FACTOR_ALREADY_ENABLED\x10\xd9\x0f\x12\x1b\n" +
+	"\x16TWO_FACTOR_NOT_ENABLED\x10\xda\x0f\x12\x1c\n" +
+	"\x17LOGIN_CHALLENGE_INVALID\x10\xdb\x0fBBZ@github.com/your-username/e-shop-native/api/protobuf/common/v1;v1b\x06proto3"
 
 var (
 	file_user_v1_error_code_proto_rawDescOnce sync.Once
@@ -0,0 +1,274 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: user/v1/internal.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UserSummary struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username           string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Nickname           string                 `protobuf:"bytes,3,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	AvatarThumbnailUrl string                 `protobuf:"bytes,4,opt,name=avatar_thumbnail_url,json=avatarThumbnailUrl,proto3" json:"avatar_thumbnail_url,omitempty"`
+	Disabled           bool                   `protobuf:"varint,5,opt,name=disabled,proto3" json:"disabled,omitempty"` // 用户已被禁用
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *UserSummary) Reset() {
+	*x = UserSummary{}
+	mi := &file_user_v1_internal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserSummary) ProtoMessage() {}
+
+func (x *UserSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_internal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserSummary.ProtoReflect.Descriptor instead.
+func (*UserSummary) Descriptor() ([]byte, []int) {
+	return file_user_v1_internal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UserSummary) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UserSummary) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserSummary) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *UserSummary) GetAvatarThumbnailUrl() string {
+	if x != nil {
+		return x.AvatarThumbnailUrl
+	}
+	return ""
+}
+
+func (x *UserSummary) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type BatchGetUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int32                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"` // 最多 internal.max_batch_size 个，重复的 ID 只查询一次
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetUsersRequest) Reset() {
+	*x = BatchGetUsersRequest{}
+	mi := &file_user_v1_internal_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersRequest) ProtoMessage() {}
+
+func (x *BatchGetUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_internal_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_internal_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchGetUsersRequest) GetIds() []int32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetUsersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         map[int32]*UserSummary `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 按用户 ID 索引
+	MissingIds    []int32                `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`                                        // 不存在或已删除的用户 ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetUsersReply) Reset() {
+	*x = BatchGetUsersReply{}
+	mi := &file_user_v1_internal_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersReply) ProtoMessage() {}
+
+func (x *BatchGetUsersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_internal_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersReply.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersReply) Descriptor() ([]byte, []int) {
+	return file_user_v1_internal_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetUsersReply) GetUsers() map[int32]*UserSummary {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *BatchGetUsersReply) GetMissingIds() []int32 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+var File_user_v1_internal_proto protoreflect.FileDescriptor
+
+const file_user_v1_internal_proto_rawDesc = "" +
+	"\n" +
+	"\x16user/v1/internal.proto\x12\auser.v1\x1a\x12user/v1/auth.proto\"\xa3\x01\n" +
+	"\vUserSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1a\n" +
+	"\bnickname\x18\x03 \x01(\tR\bnickname\x120\n" +
+	"\x14avatar_thumbnail_url\x18\x04 \x01(\tR\x12avatarThumbnailUrl\x12\x1a\n" +
+	"\bdisabled\x18\x05 \x01(\bR\bdisabled\"(\n" +
+	"\x14BatchGetUsersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x05R\x03ids\"\xc3\x01\n" +
+	"\x12BatchGetUsersReply\x12<\n" +
+	"\x05users\x18\x01 \x03(\v2&.user.v1.BatchGetUsersReply.UsersEntryR\x05users\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x05R\n" +
+	"missingIds\x1aN\n" +
+	"\n" +
+	"UsersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.user.v1.UserSummaryR\x05value:\x028\x012j\n" +
+	"\x13InternalUserService\x12S\n" +
+	"\rBatchGetUsers\x12\x1d.user.v1.BatchGetUsersRequest\x1a\x1b.user.v1.BatchGetUsersReply\"\x06\x8a\xb5\x18\x02\x18\x01B1Z/github.com/kyson/e-shop/api/protobuf/user/v1;v1b\x06proto3"
+
+var (
+	file_user_v1_internal_proto_rawDescOnce sync.Once
+	file_user_v1_internal_proto_rawDescData []byte
+)
+
+func file_user_v1_internal_proto_rawDescGZIP() []byte {
+	file_user_v1_internal_proto_rawDescOnce.Do(func() {
+		file_user_v1_internal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_user_v1_internal_proto_rawDesc), len(file_user_v1_internal_proto_rawDesc)))
+	})
+	return file_user_v1_internal_proto_rawDescData
+}
+
+var file_user_v1_internal_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_user_v1_internal_proto_goTypes = []any{
+	(*UserSummary)(nil),          // 0: user.v1.UserSummary
+	(*BatchGetUsersRequest)(nil), // 1: user.v1.BatchGetUsersRequest
+	(*BatchGetUsersReply)(nil),   // 2: user.v1.BatchGetUsersReply
+	nil,                          // 3: user.v1.BatchGetUsersReply.UsersEntry
+}
+var file_user_v1_internal_proto_depIdxs = []int32{
+	3, // 0: user.v1.BatchGetUsersReply.users:type_name -> user.v1.BatchGetUsersReply.UsersEntry
+	0, // 1: user.v1.BatchGetUsersReply.UsersEntry.value:type_name -> user.v1.UserSummary
+	1, // 2: user.v1.InternalUserService.BatchGetUsers:input_type -> user.v1.BatchGetUsersRequest
+	2, // 3: user.v1.InternalUserService.BatchGetUsers:output_type -> user.v1.BatchGetUsersReply
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_user_v1_internal_proto_init() }
+func file_user_v1_internal_proto_init() {
+	if File_user_v1_internal_proto != nil {
+		return
+	}
+	file_user_v1_auth_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_v1_internal_proto_rawDesc), len(file_user_v1_internal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_user_v1_internal_proto_goTypes,
+		DependencyIndexes: file_user_v1_internal_proto_depIdxs,
+		MessageInfos:      file_user_v1_internal_proto_msgTypes,
+	}.Build()
+	File_user_v1_internal_proto = out.File
+	file_user_v1_internal_proto_goTypes = nil
+	file_user_v1_internal_proto_depIdxs = nil
+}
@@ -0,0 +1,129 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: user/v1/internal.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InternalUserService_BatchGetUsers_FullMethodName = "/user.v1.InternalUserService/BatchGetUsers"
+)
+
+// InternalUserServiceClient is the client API for InternalUserService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InternalUserService 供订单、评价等内部服务调用的接口，只注册在 gRPC 服务上，
+// 调用方需要在 authorization 中携带服务凭证：Bearer <服务密钥>
+type InternalUserServiceClient interface {
+	// 按 ID 批量查询用户，已删除或不存在的用户在 missing_ids 中返回
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersReply, error)
+}
+
+type internalUserServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInternalUserServiceClient(cc grpc.ClientConnInterface) InternalUserServiceClient {
+	return &internalUserServiceClient{cc}
+}
+
+func (c *internalUserServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetUsersReply)
+	err := c.cc.Invoke(ctx, InternalUserService_BatchGetUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InternalUserServiceServer is the server API for InternalUserService service.
+// All implementations must embed UnimplementedInternalUserServiceServer
+// for forward compatibility.
+//
+// InternalUserService 供订单、评价等内部服务调用的接口，只注册在 gRPC 服务上，
+// 调用方需要在 authorization 中携带服务凭证：Bearer <服务密钥>
+type InternalUserServiceServer interface {
+	// 按 ID 批量查询用户，已删除或不存在的用户在 missing_ids 中返回
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersReply, error)
+	mustEmbedUnimplementedInternalUserServiceServer()
+}
+
+// UnimplementedInternalUserServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInternalUserServiceServer struct{}
+
+func (UnimplementedInternalUserServiceServer) BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetUsers not implemented")
+}
+func (UnimplementedInternalUserServiceServer) mustEmbedUnimplementedInternalUserServiceServer() {}
+func (UnimplementedInternalUserServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeInternalUserServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InternalUserServiceServer will
+// result in compilation errors.
+type UnsafeInternalUserServiceServer interface {
+	mustEmbedUnimplementedInternalUserServiceServer()
+}
+
+func RegisterInternalUserServiceServer(s grpc.ServiceRegistrar, srv InternalUserServiceServer) {
+	// If the following call pancis, it indicates UnimplementedInternalUserServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InternalUserService_ServiceDesc, srv)
+}
+
+func _InternalUserService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalUserServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InternalUserService_BatchGetUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalUserServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InternalUserService_ServiceDesc is the grpc.ServiceDesc for InternalUserService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InternalUserService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "user.v1.InternalUserService",
+	HandlerType: (*InternalUserServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _InternalUserService_BatchGetUsers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "user/v1/internal.proto",
+}
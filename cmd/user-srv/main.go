@@ -30,7 +30,9 @@ type App struct {
 	//conf_srv *conf.Server
 	mysql_dsn string
 	//data_srv*conf.Data
-	logger *zap.Logger
+	// 旧数据中没有国家码的手机号按该地区转换为 E.164 格式
+	phone_region string
+	logger       *zap.Logger
 }
 
 type Server struct {
@@ -44,6 +46,7 @@ func NewApp(grpc *server.BusinessGRPCServer,
 	http *server.BusinessHTTPServer,
 	conf_server *conf.Server,
 	data_server *conf.Data,
+	validation *conf.Validation,
 	logger *zap.Logger,
 	admin *server.AdminHTTPServer) *App {
 	return &App{
@@ -55,8 +58,9 @@ func NewApp(grpc *server.BusinessGRPCServer,
 		},
 		//conf_srv: conf_server,
 		//data_srv: data_server,
-		mysql_dsn: data_server.MySQL.DSN,
-		logger:    logger,
+		mysql_dsn:    data_server.MySQL.DSN,
+		phone_region: validation.Phone.DefaultRegion,
+		logger:       logger,
 	}
 }
 
@@ -147,7 +151,7 @@ func main() {
 	}
 	defer cleanup()
 
-	err = migrateDatabase(app.mysql_dsn)
+	err = migrateDatabase(app.mysql_dsn, app.phone_region)
 	if err != nil {
 		fmt.Printf("migrate database error: %v\n", err)
 		panic(err)
@@ -160,11 +164,19 @@ func main() {
 	}
 }
 
-func migrateDatabase(dsn string) error {
+func migrateDatabase(dsn, phoneRegion string) error {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return err
 	}
 	// 在这里同时迁移 UserPO 和 ProductPO
-	return db.AutoMigrate(&data.UserPO{})
+	err = db.AutoMigrate(&data.UserPO{}, &data.RefreshTokenPO{}, &data.ProfileChangePO{}, &data.PasswordHistoryPO{}, &data.AuditEventPO{}, &data.SessionPO{},
+		&data.TwoFactorPO{}, &data.RecoveryCodePO{})
+	if err != nil {
+		return err
+	}
+	if err := data.BackfillUserKeys(db); err != nil {
+		return err
+	}
+	return data.MigratePhonesToE164(db, phoneRegion)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/server"
 )
 
 func ProvideServerConfig(c *conf.Bootstrap) *conf.Server {
@@ -25,17 +26,64 @@ func ProvideAuthConfig(c *conf.Bootstrap) *conf.Auth {
 	return c.Auth
 }
 
+func ProvideVerificationConfig(c *conf.Bootstrap) *conf.Verification {
+	return c.Verification
+}
+
+func ProvideTwoFactorConfig(c *conf.Bootstrap) *conf.TwoFactor {
+	return c.TwoFactor
+}
+
+func ProvidePasswordConfig(c *conf.Bootstrap) *conf.Password {
+	return c.Password
+}
+
+func ProvideNotifierConfig(c *conf.Bootstrap) *conf.Notifier {
+	return c.Notifier
+}
+
+func ProvideStorageConfig(c *conf.Bootstrap) *conf.Storage {
+	return c.Storage
+}
+
+func ProvideAvatarConfig(c *conf.Bootstrap) *conf.Avatar {
+	return c.Avatar
+}
+
+func ProvideValidationConfig(c *conf.Bootstrap) *conf.Validation {
+	return c.Validation
+}
+
+func ProvideInternalConfig(c *conf.Bootstrap) *conf.Internal {
+	return c.Internal
+}
+
 func ProvideLogConfig(c *conf.Bootstrap) *conf.Log {
 	return c.Log
 }
 
+// ProvideAuthConfigLoader 重新加载签名密钥时再次读取配置文件
+func ProvideAuthConfigLoader() server.AuthConfigLoader {
+	return func() (*conf.Auth, error) {
+		bc, err := readConfig(flagconf)
+		if err != nil {
+			return nil, err
+		}
+		return bc.Auth, nil
+	}
+}
+
 func LoadConfig() (*conf.Bootstrap, error) {
 	flag.Parse()
+	return readConfig(flagconf)
+}
 
+func readConfig(path string) (*conf.Bootstrap, error) {
 	// viper
 	v := viper.New()
+	setDefaults(v)
 	// 设置配置文件
-	v.SetConfigFile(flagconf)
+	v.SetConfigFile(path)
 	v.SetConfigType("yaml")
 
 	// 读取配置文件
@@ -52,6 +100,22 @@ func LoadConfig() (*conf.Bootstrap, error) {
 	return &bc, nil
 }
 
+// setDefaults 配置文件中没有的校验规则、内部接口限制、可信代理使用默认值
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.trusted_proxies", []string{"127.0.0.1", "::1"})
+	v.SetDefault("validation.username.min_length", 3)
+	v.SetDefault("validation.username.max_length", 20)
+	v.SetDefault("validation.password.min_length", 8)
+	v.SetDefault("validation.password.max_length", 64)
+	v.SetDefault("validation.password.require_upper", true)
+	v.SetDefault("validation.password.require_lower", true)
+	v.SetDefault("validation.password.require_digit", true)
+	v.SetDefault("validation.password.require_symbol", false)
+	v.SetDefault("validation.phone.default_region", "CN")
+	v.SetDefault("validation.nickname_max_length", 32)
+	v.SetDefault("internal.max_batch_size", 100)
+}
+
 func NewLogger(c *conf.Log) (*zap.Logger, error) {
 	var level zapcore.Level
 	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
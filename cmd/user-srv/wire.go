@@ -9,6 +9,7 @@ import (
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/biz"
 	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	"github.com/kyson/e-shop-native/internal/user-srv/notifier"
 	"github.com/kyson/e-shop-native/internal/user-srv/server"
 	"github.com/kyson/e-shop-native/internal/user-srv/service"
 	"github.com/kyson/e-shop-native/internal/user-srv/validator"
@@ -20,6 +21,15 @@ func InitializeApp() (*App, func(), error) {
 		ProvideServerConfig,
 		ProvideLogConfig,
 		ProvideAuthConfig,
+		ProvideVerificationConfig,
+		ProvideTwoFactorConfig,
+		ProvidePasswordConfig,
+		ProvideNotifierConfig,
+		ProvideStorageConfig,
+		ProvideAvatarConfig,
+		ProvideValidationConfig,
+		ProvideInternalConfig,
+		ProvideAuthConfigLoader,
 
 		LoadConfig,
 		NewApp,
@@ -30,5 +40,6 @@ func InitializeApp() (*App, func(), error) {
 		server.ProviderSet,
 		auth.ProviderSet,
 		validator.ProviderSet,
+		notifier.ProviderSet,
 	))
 }
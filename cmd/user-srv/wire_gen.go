@@ -10,6 +10,7 @@ import (
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/biz"
 	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	"github.com/kyson/e-shop-native/internal/user-srv/notifier"
 	"github.com/kyson/e-shop-native/internal/user-srv/server"
 	"github.com/kyson/e-shop-native/internal/user-srv/service"
 	"github.com/kyson/e-shop-native/internal/user-srv/validator"
@@ -28,28 +29,91 @@ func InitializeApp() (*App, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	userRepo := data.NewUserRepo(dataData)
-	userValidator := validator.NewValidator()
-	passwordHash := biz.NewBcrypt()
-	userService := biz.NewUserUsecase(userRepo, userValidator, passwordHash)
-	confAuth := ProvideAuthConfig(bootstrap)
-	authAuth := auth.NewAuth(confAuth)
-	userServiceServer := service.NewUserService(userService, authAuth)
+	userRepo := data.NewUserRepo(dataData, confData)
+	profileChangeRepo := data.NewProfileChangeRepo(dataData)
+	passwordHistoryRepo := data.NewPasswordHistoryRepo(dataData)
+	password := ProvidePasswordConfig(bootstrap)
+	validation := ProvideValidationConfig(bootstrap)
+	userValidator, err := validator.NewValidator(password, validation)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	passwordHash := biz.NewPasswordHasher(password)
 	log := ProvideLogConfig(bootstrap)
 	logger, err := NewLogger(log)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	businessGRPCServer := server.NewGRPCServer(confServer, userServiceServer, authAuth, logger)
-	businessHTTPServer, err := server.NewHTTPServer(confServer, logger)
+	loginAttemptRepo := data.NewLoginAttemptRepo(dataData, logger)
+	confAuth := ProvideAuthConfig(bootstrap)
+	loginLimiter := biz.NewLoginLimiter(loginAttemptRepo, confAuth)
+	auditRepo := data.NewAuditRepo(dataData)
+	auditService := biz.NewAuditUsecase(auditRepo, logger)
+	userService := biz.NewUserUsecase(userRepo, profileChangeRepo, passwordHistoryRepo, userValidator, passwordHash, loginLimiter, auditService, confAuth)
+	refreshTokenRepo := data.NewRefreshTokenRepo(dataData)
+	tokenService := biz.NewTokenUsecase(refreshTokenRepo, auditService, confAuth)
+	verificationCodeRepo := data.NewRedisVerificationCodeRepo(dataData)
+	verification := ProvideVerificationConfig(bootstrap)
+	verificationCodeService := biz.NewVerificationCodeUsecase(verificationCodeRepo, verification)
+	confNotifier := ProvideNotifierConfig(bootstrap)
+	bizNotifier, cleanup2, err := notifier.NewNotifier(confNotifier)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	passwordResetService := biz.NewPasswordResetUsecase(userRepo, userService, verificationCodeService, bizNotifier, userValidator)
+	contactVerificationService := biz.NewContactVerificationUsecase(userRepo, verificationCodeService, bizNotifier, userValidator)
+	sessionRepo := data.NewSessionRepo(dataData)
+	sessionService := biz.NewSessionUsecase(sessionRepo, refreshTokenRepo, auditService, confAuth)
+	twoFactorRepo := data.NewTwoFactorRepo(dataData)
+	twoFactor := ProvideTwoFactorConfig(bootstrap)
+	twoFactorService := biz.NewTwoFactorUsecase(twoFactorRepo, verificationCodeRepo, loginLimiter, auditService, twoFactor)
+	authAuth, err := auth.NewAuth(confAuth)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	revocationList := data.NewRedisRevocationList(dataData)
+	storage := ProvideStorageConfig(bootstrap)
+	blobStore := data.NewLocalBlobStore(storage)
+	avatar := ProvideAvatarConfig(bootstrap)
+	avatarService := biz.NewAvatarUsecase(userRepo, blobStore, avatar)
+	userServiceServer := service.NewUserService(userService, tokenService, passwordResetService, contactVerificationService, sessionService, twoFactorService, authAuth, revocationList, auditService, avatarService)
+	adminUserService := biz.NewAdminUserUsecase(userRepo, profileChangeRepo, auditService)
+	adminUserServiceServer := service.NewAdminUserService(adminUserService, tokenService, auditService)
+	internal := ProvideInternalConfig(bootstrap)
+	internalUserService := biz.NewInternalUserUsecase(userRepo, internal)
+	internalUserServiceServer := service.NewInternalUserService(internalUserService)
+	claimsCheckers := server.NewClaimsCheckers(revocationList, userService, sessionService)
+	introspector := auth.NewIntrospector(authAuth, claimsCheckers)
+	authServiceServer := service.NewAuthService(introspector)
+	policies := auth.NewPolicies()
+	serviceCredentials, err := auth.NewServiceCredentials(confAuth)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	businessGRPCServer, err := server.NewGRPCServer(confServer, userServiceServer, adminUserServiceServer, internalUserServiceServer, authServiceServer, authAuth, policies, serviceCredentials, claimsCheckers, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	businessHTTPServer, err := server.NewHTTPServer(confServer, storage, authAuth, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	adminHTTPServer := server.NewAdminServer(confServer)
-	app := NewApp(businessGRPCServer, businessHTTPServer, confServer, confData, logger, adminHTTPServer)
+	authConfigLoader := ProvideAuthConfigLoader()
+	adminHTTPServer := server.NewAdminServer(confServer, authAuth, authConfigLoader, serviceCredentials, logger)
+	app := NewApp(businessGRPCServer, businessHTTPServer, confServer, confData, validation, logger, adminHTTPServer)
 	return app, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -2,96 +2,166 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 )
 
 // Claims struct definition
+// RegisteredClaims.ID 即 jti，用于吊销单个访问令牌
 type Claims struct {
-	Id       uint   `json:"id"`
-	UserName string `json:"userName"`
+	Id           uint     `json:"id"`
+	UserName     string   `json:"userName"`
+	TokenVersion uint     `json:"ver"`             // 用户的令牌版本，修改密码后递增，旧版本的令牌全部失效
+	Roles        []string `json:"roles,omitempty"` // 用户角色，用于方法级别的访问控制
+	SessionID    string   `json:"sid,omitempty"`   // 登录会话ID，会话被注销后令牌立即失效
 	jwt.RegisteredClaims
 }
 
+// TokenOption 为生成的访问令牌设置额外的 claims
+type TokenOption func(*Claims)
+
+func WithTokenVersion(version uint) TokenOption {
+	return func(c *Claims) {
+		c.TokenVersion = version
+	}
+}
+
+func WithRoles(roles ...string) TokenOption {
+	return func(c *Claims) {
+		c.Roles = roles
+	}
+}
+
+func WithSessionID(sessionID string) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = sessionID
+	}
+}
+
 type AuthIMP struct {
-	jwtKey         []byte
 	expireDuration time.Duration
-	algorithm      jwt.SigningMethod
-	whitelist      []string
+	keys           atomic.Pointer[keySet] // 重新加载时整体替换
 }
 
 type Auth interface {
-	GenerateToken(ctx context.Context, id uint, userName string) (string, error)
+	GenerateToken(ctx context.Context, id uint, userName string, opts ...TokenOption) (string, error)
 	ParseAndSaveToken(ctx context.Context, tokenS string) (context.Context, error)
 	// ToContext(ctx context.Context, claims *Claims) context.Context
 	// FromContext(ctx context.Context) (*Claims, bool)
-	GetWhiteList() []string
+	// JWKS 返回验签公钥，共享密钥算法返回空集合
+	JWKS() JWKS
+	// ReloadKeys 重新加载密钥配置，切换签发密钥，已签发的令牌只要 kid 仍在配置中就继续有效
+	ReloadKeys(c *conf.Auth) error
+	// KeyIDs 返回当前签发密钥的 kid 和所有验签密钥的 kid
+	KeyIDs() (active string, all []string)
 	GetJWTKey() []byte
 	GetExpireDuration() time.Duration
 	GetAlgorithm() jwt.SigningMethod
 }
 
-func NewAuth(c *conf.Auth) Auth {
-	var algorithm jwt.SigningMethod
-	switch c.Algorithm {
-	case "HS256":
-		algorithm = jwt.SigningMethodHS256
-	case "HS384":
-		algorithm = jwt.SigningMethodHS384
-	case "HS512":
-		algorithm = jwt.SigningMethodHS512
-	default:
-		algorithm = jwt.SigningMethodHS256
-	}
+// NewAuth HS256/HS384/HS512 使用共享密钥，RS256/ES256/EdDSA 从 PEM 文件读取私钥，
+// 配置了 keys 时按 kid 支持多个密钥，见 newKeySet
+func NewAuth(c *conf.Auth) (Auth, error) {
 	authIMP := &AuthIMP{
-		jwtKey:         []byte(c.JwtKey),
 		expireDuration: time.Second * time.Duration(c.ExpireDuration),
-		algorithm:      algorithm,
-		whitelist:      c.Whitelist,
 	}
-	return authIMP
+	if err := authIMP.ReloadKeys(c); err != nil {
+		return nil, err
+	}
+	return authIMP, nil
 }
 
-func (auth *AuthIMP) GenerateToken(ctx context.Context, id uint, userName string) (string, error) {
-	expirationTime := time.Now().Add(auth.expireDuration) // 每次生成时计算
+func (auth *AuthIMP) ReloadKeys(c *conf.Auth) error {
+	set, err := newKeySet(c)
+	if err != nil {
+		return err
+	}
+	auth.keys.Store(set)
+	return nil
+}
+
+func (auth *AuthIMP) KeyIDs() (string, []string) {
+	set := auth.keys.Load()
+	kids := set.kids()
+	slices.Sort(kids)
+	return set.current.kid, kids
+}
+
+func (auth *AuthIMP) GenerateToken(ctx context.Context, id uint, userName string, opts ...TokenOption) (string, error) {
+	now := time.Now()
+	expirationTime := now.Add(auth.expireDuration) // 每次生成时计算
 	claims := Claims{
 		Id:       id,
 		UserName: userName,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
+	for _, opt := range opts {
+		opt(&claims)
+	}
 
-	token := jwt.NewWithClaims(auth.algorithm, claims)  // 计算claims "指纹"
-	tokenString, err := token.SignedString(auth.jwtKey) // 密钥签名
-	return tokenString, fmt.Errorf("failed to generate token: %w", err)
+	key := auth.keys.Load().current
+	token := jwt.NewWithClaims(key.method, claims) // 计算claims "指纹"
+	if key.kid != "" {
+		token.Header["kid"] = key.kid // 验签方根据 kid 选择公钥
+	}
+	tokenString, err := token.SignedString(key.sign) // 密钥签名
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return tokenString, nil
 }
 
 // ParseToken 解析并验证一个 JWT 字符串
 func (auth *AuthIMP) ParseAndSaveToken(ctx context.Context, tokenS string) (context.Context, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenS, claims, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, apperrors.ErrTokenInvalid
-		}
-		return auth.jwtKey, nil
-	})
-	if !token.Valid {
+	token, err := jwt.ParseWithClaims(tokenS, claims, auth.keyFunc)
+	// 签名校验通过后才会检查过期时间，客户端收到 TOKEN_EXPIRED 时使用刷新令牌换取新的访问令牌
+	if errors.Is(err, jwt.ErrTokenExpired) {
 		return ctx, apperrors.ErrTokenExpired
 	}
-	if err != nil {
+	if err != nil || !token.Valid {
 		return ctx, apperrors.ErrTokenInvalid
 	}
 
 	return ToContext(ctx, claims), nil
 }
 
+// keyFunc 根据 kid 选择验签密钥，
+// 令牌声明的算法必须与密钥一致，防止用公钥作为 HMAC 密钥伪造令牌
+func (auth *AuthIMP) keyFunc(t *jwt.Token) (any, error) {
+	kid, ok := t.Header["kid"].(string)
+	key := auth.keys.Load().lookup(kid, ok)
+	if key == nil {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	if _, ok := key.method.(*jwt.SigningMethodHMAC); ok {
+		// 共享密钥兼容 HS256/HS384/HS512 签发的令牌
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, apperrors.ErrTokenInvalid
+		}
+		return key.verify, nil
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	return key.verify, nil
+}
+
 type claimKey struct{}
 
 // ToContext 和 FromContext
@@ -104,12 +174,26 @@ func FromContext(ctx context.Context) (*Claims, bool) {
 	return claims, ok
 }
 
-func (a *AuthIMP) GetWhiteList() []string {
-	return a.whitelist
+func (a *AuthIMP) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range a.keys.Load().keys {
+		if _, ok := key.method.(*jwt.SigningMethodHMAC); ok {
+			continue
+		}
+		jwk, err := publicJWK(key.kid, key.method.Alg(), key.verify)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	slices.SortFunc(set.Keys, func(a, b JWK) int { return strings.Compare(a.Kid, b.Kid) })
+	return set
 }
 
+// GetJWTKey 共享密钥，非对称算法返回 nil
 func (a *AuthIMP) GetJWTKey() []byte {
-	return a.jwtKey
+	key, _ := a.keys.Load().current.sign.([]byte)
+	return key
 }
 
 func (a *AuthIMP) GetExpireDuration() time.Duration {
@@ -117,5 +201,5 @@ func (a *AuthIMP) GetExpireDuration() time.Duration {
 }
 
 func (a *AuthIMP) GetAlgorithm() jwt.SigningMethod {
-	return a.algorithm
+	return a.keys.Load().current.method
 }
@@ -82,7 +82,6 @@ func TestGetter(t *testing.T) {
 				Algorithm:      "HS512",
 				JwtKey:         "ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs=",
 				ExpireDuration: 3600,
-				Whitelist:      []string{"/user/register", "/user/login"},
 			},
 			Algorithm:      jwt.SigningMethodHS512,
 			ExpireDuration: time.Second * time.Duration(3600),
@@ -90,11 +89,11 @@ func TestGetter(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		auth := NewAuth(tt.config)
+		auth, err := NewAuth(tt.config)
+		assert.NoError(t, err)
 		assert.Equal(t, tt.config.JwtKey, string(auth.GetJWTKey()))
 		assert.Equal(t, tt.Algorithm, auth.GetAlgorithm())
 		assert.Equal(t, tt.ExpireDuration, auth.GetExpireDuration())
-		assert.Equal(t, tt.config.Whitelist, auth.GetWhiteList())
 	}
 }
 
@@ -104,7 +103,6 @@ func TestGenerateToken(t *testing.T) {
 		Algorithm:      "HS256",
 		JwtKey:         "ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs=",
 		ExpireDuration: 3600,
-		Whitelist:      []string{"/user/register", "/user/login"},
 	}
 
 	tests := []struct {
@@ -126,7 +124,8 @@ func TestGenerateToken(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		auth := NewAuth(tt.config)
+		auth, err := NewAuth(tt.config)
+		assert.NoError(t, err)
 		token, err := auth.GenerateToken(context.Background(), tt.uid, tt.userName)
 		assert.Equal(t, tt.wantToken, token != "")
 		assert.Equal(t, tt.wantErr, err)
@@ -139,7 +138,6 @@ func TestParseToken(t *testing.T) {
 		Algorithm:      "HS512",
 		JwtKey:         string("ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs="),
 		ExpireDuration: 600,
-		Whitelist:      []string{"/user/register", "/user/login"},
 	}
 
 	// 正常的tokenString
@@ -180,12 +178,13 @@ func TestParseToken(t *testing.T) {
 		}, {
 			name:        "过期的token解析失败",
 			tokenString: expireTokenString,
-			wantErr:     apperrors.ErrTokenInvalid,
+			wantErr:     apperrors.ErrTokenExpired,
 		},
 	}
 
 	for _, tt := range tests {
-		auth := NewAuth(config)
+		auth, err := NewAuth(config)
+		assert.NoError(t, err)
 
 		ctx, err := auth.ParseAndSaveToken(context.Background(), tt.tokenString)
 		assert.Equal(t, tt.wantErr, err)
@@ -205,9 +204,9 @@ func TestPath(t *testing.T) {
 		Algorithm:      "HS512",
 		JwtKey:         string("ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs="),
 		ExpireDuration: 3600,
-		Whitelist:      []string{"/user/register", "/user/login"},
 	}
-	auth := NewAuth(&config)
+	auth, err := NewAuth(&config)
+	assert.NoError(t, err)
 
 	tokenString, err := auth.GenerateToken(context.Background(), 1, "testUser")
 	assert.NoError(t, err)
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// ClaimsChecker 在 JWT 签名校验通过后，根据服务端状态（吊销、禁用等）再次检查 claims
+type ClaimsChecker interface {
+	Check(ctx context.Context, claims *Claims) error
+}
+
+// TokenVersionSource 查询用户当前的令牌版本
+type TokenVersionSource interface {
+	GetTokenVersion(ctx context.Context, userID uint) (uint, error)
+}
+
+type tokenVersionChecker struct {
+	source TokenVersionSource
+}
+
+// NewTokenVersionChecker 拒绝令牌版本落后于用户当前版本的令牌（例如修改密码之前签发的令牌）
+func NewTokenVersionChecker(source TokenVersionSource) ClaimsChecker {
+	return &tokenVersionChecker{source: source}
+}
+
+func (c *tokenVersionChecker) Check(ctx context.Context, claims *Claims) error {
+	version, err := c.source.GetTokenVersion(ctx, claims.Id)
+	if err != nil {
+		return err
+	}
+	if claims.TokenVersion != version {
+		return apperrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+// UserStatusSource 检查用户当前是否可以使用
+type UserStatusSource interface {
+	// CheckUserStatus 用户被禁用或删除时返回错误
+	CheckUserStatus(ctx context.Context, userID uint) error
+}
+
+type userStatusChecker struct {
+	source UserStatusSource
+}
+
+// NewUserStatusChecker 拒绝已被禁用或删除的用户持有的令牌，即使令牌还没有过期
+func NewUserStatusChecker(source UserStatusSource) ClaimsChecker {
+	return &userStatusChecker{source: source}
+}
+
+func (c *userStatusChecker) Check(ctx context.Context, claims *Claims) error {
+	err := c.source.CheckUserStatus(ctx, claims.Id)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return apperrors.ErrTokenRevoked
+	}
+	return err
+}
+
+// SessionSource 检查登录会话是否仍然有效
+type SessionSource interface {
+	// CheckSession 会话已被注销、已过期或不属于该用户时返回错误
+	CheckSession(ctx context.Context, userID uint, sessionID string) error
+}
+
+type sessionChecker struct {
+	source SessionSource
+}
+
+// NewSessionChecker 拒绝会话已被注销的令牌，没有会话ID的旧令牌不检查
+func NewSessionChecker(source SessionSource) ClaimsChecker {
+	return &sessionChecker{source: source}
+}
+
+func (c *sessionChecker) Check(ctx context.Context, claims *Claims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+	err := c.source.CheckSession(ctx, claims.Id, claims.SessionID)
+	if errors.Is(err, apperrors.ErrSessionNotFound) {
+		return apperrors.ErrTokenRevoked
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// ClaimsCheckers 签名校验通过后依次执行的服务端状态检查，鉴权拦截器和令牌内省共用
+type ClaimsCheckers []ClaimsChecker
+
+// inactiveErrors 检查失败时表示令牌已不可用的错误，其他错误视为查询服务端状态失败
+var inactiveErrors = []error{
+	apperrors.ErrTokenInvalid,
+	apperrors.ErrTokenExpired,
+	apperrors.ErrTokenRevoked,
+	apperrors.ErrUserDisabled,
+	apperrors.ErrUserNotFound,
+	apperrors.ErrSessionNotFound,
+}
+
+// Introspector 令牌内省，与鉴权拦截器使用相同的校验，供其他服务判断令牌是否有效
+type Introspector struct {
+	auth     Auth
+	checkers ClaimsCheckers
+}
+
+func NewIntrospector(a Auth, checkers ClaimsCheckers) *Introspector {
+	return &Introspector{auth: a, checkers: checkers}
+}
+
+// Introspect 令牌有效时返回 claims，令牌无效、被吊销或者用户不可用时返回 nil，
+// 只有查询服务端状态失败时返回错误
+func (i *Introspector) Introspect(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, nil
+	}
+	ctx, err := i.auth.ParseAndSaveToken(ctx, token)
+	if err != nil {
+		return nil, nil
+	}
+	claims, _ := FromContext(ctx)
+	for _, checker := range i.checkers {
+		if err := checker.Check(ctx, claims); err != nil {
+			if isInactive(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+func isInactive(err error) bool {
+	for _, target := range inactiveErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// fakeStatusSource 返回每个用户预设的状态
+type fakeStatusSource map[uint]error
+
+func (f fakeStatusSource) CheckUserStatus(ctx context.Context, userID uint) error {
+	return f[userID]
+}
+
+func TestIntrospector_Introspect(t *testing.T) {
+	a, err := NewAuth(&conf.Auth{Algorithm: "HS256", JwtKey: "ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs=", ExpireDuration: 3600})
+	require.NoError(t, err)
+	dbErr := errors.New("db down")
+	introspector := NewIntrospector(a, ClaimsCheckers{NewUserStatusChecker(fakeStatusSource{
+		2: apperrors.ErrUserDisabled,
+		3: apperrors.ErrUserNotFound,
+		4: dbErr,
+	})})
+
+	token := func(id uint) string {
+		s, err := a.GenerateToken(context.Background(), id, "alice", WithRoles("customer"))
+		require.NoError(t, err)
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantActive bool
+		wantErr    error
+	}{
+		{"有效的令牌", token(1), true, nil},
+		{"用户已被禁用", token(2), false, nil},
+		{"用户已被删除", token(3), false, nil},
+		{"签名错误", token(1) + "x", false, nil},
+		{"空令牌", "", false, nil},
+		{"查询用户状态失败", token(4), false, dbErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := introspector.Introspect(context.Background(), tt.token)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantActive, claims != nil)
+			if tt.wantActive {
+				assert.Equal(t, uint(1), claims.Id)
+				assert.Equal(t, []string{"customer"}, claims.Roles)
+			}
+		})
+	}
+}
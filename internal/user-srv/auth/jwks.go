@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK 公钥的 JSON Web Key 表示（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC、OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS 发布在 /.well-known/jwks.json 的公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func publicJWK(kid, alg string, public crypto.PublicKey) (JWK, error) {
+	enc := base64.RawURLEncoding
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: alg,
+			N: enc.EncodeToString(key.N.Bytes()),
+			E: enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		// 坐标按曲线长度补齐前导 0
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC", Kid: kid, Use: "sig", Alg: alg,
+			Crv: key.Curve.Params().Name,
+			X:   enc.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   enc.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP", Kid: kid, Use: "sig", Alg: alg,
+			Crv: "Ed25519",
+			X:   enc.EncodeToString(key),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// Thumbprint 计算 RFC 7638 JWK 指纹：只包含必需成员、按字典序排列的 JSON 的 SHA-256
+func (k JWK) Thumbprint() string {
+	var members any
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	}
+	// 成员都是 base64url 字符串，json.Marshal 不会产生需要转义的字符
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// writeKey 把私钥以 PKCS#8 格式写入临时文件
+func writeKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func newKeys(t *testing.T) map[string]crypto.Signer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey}
+}
+
+// 非对称算法签发的令牌带 kid，并且可以用 JWKS 中的公钥验证
+func TestAsymmetricAuth(t *testing.T) {
+	kty := map[string]string{"RS256": "RSA", "ES256": "EC", "EdDSA": "OKP"}
+	for algorithm, key := range newKeys(t) {
+		t.Run(algorithm, func(t *testing.T) {
+			a, err := NewAuth(&conf.Auth{Algorithm: algorithm, PrivateKeyFile: writeKey(t, key), ExpireDuration: 600})
+			require.NoError(t, err)
+			assert.Nil(t, a.GetJWTKey())
+
+			tokenString, err := a.GenerateToken(context.Background(), 1, "testUser")
+			require.NoError(t, err)
+			ctx, err := a.ParseAndSaveToken(context.Background(), tokenString)
+			require.NoError(t, err)
+			claims, ok := FromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(t, uint(1), claims.Id)
+
+			set := a.JWKS()
+			require.Len(t, set.Keys, 1)
+			jwk := set.Keys[0]
+			assert.Equal(t, kty[algorithm], jwk.Kty)
+			assert.Equal(t, algorithm, jwk.Alg)
+			// 未配置 kid 时使用 JWK 指纹
+			assert.Equal(t, jwk.Thumbprint(), jwk.Kid)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, jwk.Kid, token.Header["kid"])
+		})
+	}
+}
+
+// 配置的 kid 写入令牌头，未知 kid 和与密钥不一致的算法都会被拒绝
+func TestAsymmetricAuth_KeySelection(t *testing.T) {
+	keys := newKeys(t)
+	a, err := NewAuth(&conf.Auth{Algorithm: "ES256", PrivateKeyFile: writeKey(t, keys["ES256"]), KeyID: "key-1", ExpireDuration: 600})
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", a.JWKS().Keys[0].Kid)
+
+	claims := Claims{Id: 1, UserName: "testUser"}
+	unknown := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	unknown.Header["kid"] = "key-2"
+	tokenString, err := unknown.SignedString(keys["ES256"])
+	require.NoError(t, err)
+	_, err = a.ParseAndSaveToken(context.Background(), tokenString)
+	assert.Equal(t, apperrors.ErrTokenInvalid, err)
+
+	// 其他密钥签发的令牌即使 kid 相同也无法通过验证
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	forged.Header["kid"] = "key-1"
+	tokenString, err = forged.SignedString(other)
+	require.NoError(t, err)
+	_, err = a.ParseAndSaveToken(context.Background(), tokenString)
+	assert.Equal(t, apperrors.ErrTokenInvalid, err)
+
+	// 不能把公钥当作 HMAC 密钥使用
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = "key-1"
+	tokenString, err = hmac.SignedString([]byte("key-1"))
+	require.NoError(t, err)
+	_, err = a.ParseAndSaveToken(context.Background(), tokenString)
+	assert.Equal(t, apperrors.ErrTokenInvalid, err)
+}
+
+// 密钥类型与算法不匹配、缺少私钥文件时启动失败，共享密钥算法不发布公钥
+func TestNewAuth_KeyErrors(t *testing.T) {
+	keys := newKeys(t)
+	_, err := NewAuth(&conf.Auth{Algorithm: "RS256", PrivateKeyFile: writeKey(t, keys["ES256"])})
+	assert.Error(t, err)
+	_, err = NewAuth(&conf.Auth{Algorithm: "EdDSA"})
+	assert.Error(t, err)
+	_, err = NewAuth(&conf.Auth{Algorithm: "ES256", PrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+
+	a, err := NewAuth(&conf.Auth{Algorithm: "HS256", JwtKey: "secret"})
+	require.NoError(t, err)
+	assert.Empty(t, a.JWKS().Keys)
+}
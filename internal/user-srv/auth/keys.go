@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+// signingKey 一个签名密钥，HMAC 的 sign 和 verify 都是共享密钥，非对称算法的 verify 是公钥，
+// 只用于验签的旧密钥 sign 为 nil
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   any
+	verify any
+}
+
+// keySet 签发使用 current，验签根据 kid 从 keys 中选择
+type keySet struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+// hmacMethods 共享密钥支持的算法
+var hmacMethods = map[string]jwt.SigningMethod{
+	"HS256": jwt.SigningMethodHS256,
+	"HS384": jwt.SigningMethodHS384,
+	"HS512": jwt.SigningMethodHS512,
+}
+
+// asymmetricMethods 非对称算法，密钥从 PEM 文件中读取
+var asymmetricMethods = map[string]jwt.SigningMethod{
+	"RS256": jwt.SigningMethodRS256,
+	"ES256": jwt.SigningMethodES256,
+	"EdDSA": jwt.SigningMethodEdDSA,
+}
+
+// newKeySet 未配置 keys 时使用 jwt_key、algorithm、private_key_file 组成的单个密钥；
+// 配置了 keys 时 active_key_id 对应的密钥用于签发，其余密钥只用于验签，
+// 此时 jwt_key 继续用于验证轮换前签发的、没有 kid 的令牌
+func newKeySet(c *conf.Auth) (*keySet, error) {
+	if len(c.Keys) == 0 {
+		key, err := loadKey(legacyKey(c))
+		if err != nil {
+			return nil, err
+		}
+		return &keySet{current: key, keys: map[string]*signingKey{key.kid: key}}, nil
+	}
+
+	set := &keySet{keys: make(map[string]*signingKey, len(c.Keys)+1)}
+	for _, k := range c.Keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("signing key id is required")
+		}
+		if _, ok := set.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key id %s", k.ID)
+		}
+		key, err := loadKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key %s: %w", k.ID, err)
+		}
+		set.keys[k.ID] = key
+	}
+
+	active := c.ActiveKeyID
+	if active == "" {
+		active = c.Keys[0].ID
+	}
+	set.current = set.keys[active]
+	if set.current == nil {
+		return nil, fmt.Errorf("active signing key %s not found", active)
+	}
+	if set.current.sign == nil {
+		return nil, fmt.Errorf("active signing key %s has no private key", active)
+	}
+
+	if c.JwtKey != "" {
+		set.keys[""] = &signingKey{method: jwt.SigningMethodHS256, verify: []byte(c.JwtKey)}
+	}
+	return set, nil
+}
+
+// legacyKey 把单密钥配置转换为密钥集合中的一项，未知的算法按 HS256 处理
+func legacyKey(c *conf.Auth) conf.SigningKey {
+	algorithm := c.Algorithm
+	if _, ok := asymmetricMethods[algorithm]; !ok {
+		if _, ok := hmacMethods[algorithm]; !ok {
+			algorithm = "HS256"
+		}
+	}
+	return conf.SigningKey{
+		ID:             c.KeyID,
+		Algorithm:      algorithm,
+		Secret:         c.JwtKey,
+		PrivateKeyFile: c.PrivateKeyFile,
+	}
+}
+
+// lookup 根据 kid 选择验签密钥，没有 kid 的令牌优先使用 kid 为空的密钥
+func (s *keySet) lookup(kid string, ok bool) *signingKey {
+	if !ok {
+		if key, found := s.keys[""]; found {
+			return key
+		}
+		return s.current
+	}
+	return s.keys[kid]
+}
+
+func (s *keySet) kids() []string {
+	kids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		if kid != "" {
+			kids = append(kids, kid)
+		}
+	}
+	return kids
+}
+
+func loadKey(k conf.SigningKey) (*signingKey, error) {
+	if method, ok := hmacMethods[k.Algorithm]; ok {
+		if k.Secret == "" {
+			return nil, fmt.Errorf("secret is required for %s", k.Algorithm)
+		}
+		return &signingKey{kid: k.ID, method: method, sign: []byte(k.Secret), verify: []byte(k.Secret)}, nil
+	}
+
+	method, ok := asymmetricMethods[k.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+	key := &signingKey{kid: k.ID, method: method}
+	switch {
+	case k.PrivateKeyFile != "":
+		private, err := readPEM(k.PrivateKeyFile, parsePrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		key.sign, key.verify = private, private.Public()
+	case k.PublicKeyFile != "":
+		public, err := readPEM(k.PublicKeyFile, parsePublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key.verify = public
+	default:
+		return nil, fmt.Errorf("private_key_file is required for %s", k.Algorithm)
+	}
+	if err := checkKeyType(method, key.verify); err != nil {
+		return nil, err
+	}
+
+	// 未配置 kid 时使用公钥的 JWK 指纹（RFC 7638），同一个密钥在所有实例上得到相同的 kid
+	if key.kid == "" {
+		jwk, err := publicJWK("", method.Alg(), key.verify)
+		if err != nil {
+			return nil, err
+		}
+		key.kid = jwk.Thumbprint()
+	}
+	return key, nil
+}
+
+// checkKeyType 检查密钥类型与算法是否匹配
+func checkKeyType(method jwt.SigningMethod, public crypto.PublicKey) error {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if method != jwt.SigningMethodRS256 {
+			return fmt.Errorf("RSA key can not be used with %s", method.Alg())
+		}
+	case *ecdsa.PublicKey:
+		if method != jwt.SigningMethodES256 || key.Curve != elliptic.P256() {
+			return fmt.Errorf("ECDSA key on %s can not be used with %s", key.Curve.Params().Name, method.Alg())
+		}
+	case ed25519.PublicKey:
+		if method != jwt.SigningMethodEdDSA {
+			return fmt.Errorf("Ed25519 key can not be used with %s", method.Alg())
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", public)
+	}
+	return nil
+}
+
+func readPEM[T any](path string, parse func(*pem.Block) (T, error)) (T, error) {
+	var zero T
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return zero, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return zero, fmt.Errorf("no PEM block found in %s", path)
+	}
+	key, err := parse(block)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// parsePrivateKey 支持 PKCS#8、PKCS#1（RSA）和 SEC 1（EC）格式
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// parsePublicKey 支持 PKIX 和 PKCS#1（RSA）格式
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// 轮换共享密钥：旧密钥签发的令牌在新密钥启用后仍然有效，删除旧密钥后失效
+func TestReloadKeys_HMAC(t *testing.T) {
+	ctx := context.Background()
+	legacy := &conf.Auth{Algorithm: "HS256", JwtKey: "legacy-secret", ExpireDuration: 600}
+	a, err := NewAuth(legacy)
+	require.NoError(t, err)
+	legacyToken, err := a.GenerateToken(ctx, 1, "testUser")
+	require.NoError(t, err)
+
+	// 1. 加入密钥集合，jwt_key 继续验证没有 kid 的令牌
+	c := &conf.Auth{
+		JwtKey:         "legacy-secret",
+		ExpireDuration: 600,
+		ActiveKeyID:    "k1",
+		Keys: []conf.SigningKey{
+			{ID: "k1", Algorithm: "HS256", Secret: "secret-1"},
+			{ID: "k2", Algorithm: "HS512", Secret: "secret-2"},
+		},
+	}
+	require.NoError(t, a.ReloadKeys(c))
+	k1Token, err := a.GenerateToken(ctx, 1, "testUser")
+	require.NoError(t, err)
+	for _, token := range []string{legacyToken, k1Token} {
+		_, err = a.ParseAndSaveToken(ctx, token)
+		assert.NoError(t, err)
+	}
+
+	// 2. 切换到 k2，k1 签发的令牌仍然有效
+	c.ActiveKeyID = "k2"
+	require.NoError(t, a.ReloadKeys(c))
+	active, kids := a.KeyIDs()
+	assert.Equal(t, "k2", active)
+	assert.Equal(t, []string{"k1", "k2"}, kids)
+	k2Token, err := a.GenerateToken(ctx, 1, "testUser")
+	require.NoError(t, err)
+	for _, token := range []string{legacyToken, k1Token, k2Token} {
+		_, err = a.ParseAndSaveToken(ctx, token)
+		assert.NoError(t, err)
+	}
+
+	// 3. 删除旧密钥
+	c.JwtKey = ""
+	c.Keys = c.Keys[1:]
+	require.NoError(t, a.ReloadKeys(c))
+	for _, token := range []string{legacyToken, k1Token} {
+		_, err = a.ParseAndSaveToken(ctx, token)
+		assert.Equal(t, apperrors.ErrTokenInvalid, err)
+	}
+	_, err = a.ParseAndSaveToken(ctx, k2Token)
+	assert.NoError(t, err)
+}
+
+// 只配置公钥的旧密钥可以验签并发布在 JWKS 中，但不能作为签发密钥
+func TestReloadKeys_PublicKeyOnly(t *testing.T) {
+	ctx := context.Background()
+	keys := newKeys(t)
+	oldFile := writeKey(t, keys["ES256"])
+	a, err := NewAuth(&conf.Auth{ExpireDuration: 600, Keys: []conf.SigningKey{{ID: "old", Algorithm: "ES256", PrivateKeyFile: oldFile}}})
+	require.NoError(t, err)
+	oldToken, err := a.GenerateToken(ctx, 1, "testUser")
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(keys["ES256"].Public())
+	require.NoError(t, err)
+	publicFile := filepath.Join(t.TempDir(), "old.pub.pem")
+	require.NoError(t, os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	c := &conf.Auth{
+		ExpireDuration: 600,
+		ActiveKeyID:    "new",
+		Keys: []conf.SigningKey{
+			{ID: "new", Algorithm: "EdDSA", PrivateKeyFile: writeKey(t, keys["EdDSA"])},
+			{ID: "old", Algorithm: "ES256", PublicKeyFile: publicFile},
+		},
+	}
+	require.NoError(t, a.ReloadKeys(c))
+	_, err = a.ParseAndSaveToken(ctx, oldToken)
+	assert.NoError(t, err)
+	set := a.JWKS()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "new", set.Keys[0].Kid)
+	assert.Equal(t, "old", set.Keys[1].Kid)
+
+	// 配置错误时保留原来的密钥
+	c.ActiveKeyID = "old"
+	assert.Error(t, a.ReloadKeys(c))
+	c.ActiveKeyID = "missing"
+	assert.Error(t, a.ReloadKeys(c))
+	active, _ := a.KeyIDs()
+	assert.Equal(t, "new", active)
+}
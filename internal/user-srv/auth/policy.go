@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// Policy 单个方法的访问控制规则
+type Policy struct {
+	Public   bool     // 不需要登录
+	Roles    []string // 允许访问的角色，为空时任意已登录用户都可以访问
+	Internal bool     // 只允许使用服务凭证调用
+}
+
+// Policies gRPC 完整方法名到访问控制规则的映射，没有声明规则的方法需要登录
+type Policies map[string]Policy
+
+// NewPolicies 读取 user.v1 中声明在方法上的 (user.v1.auth) 选项
+func NewPolicies() Policies {
+	return LoadPolicies(v1.File_user_v1_user_proto, v1.File_user_v1_admin_proto, v1.File_user_v1_internal_proto, v1.File_user_v1_auth_service_proto)
+}
+
+// LoadPolicies 从 proto 文件描述中读取所有服务方法的访问控制规则
+func LoadPolicies(files ...protoreflect.FileDescriptor) Policies {
+	policies := make(Policies)
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			methods := service.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				rule, ok := proto.GetExtension(method.Options(), v1.E_Auth).(*v1.AuthRule)
+				if !ok || rule == nil {
+					continue
+				}
+				fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+				policies[fullMethod] = Policy{Public: rule.GetPublic(), Roles: rule.GetRoles(), Internal: rule.GetInternal()}
+			}
+		}
+	}
+	return policies
+}
+
+// IsPublic 方法是否不需要登录
+func (p Policies) IsPublic(fullMethod string) bool {
+	return p[fullMethod].Public
+}
+
+// IsInternal 方法是否只允许其他服务使用服务凭证调用
+func (p Policies) IsInternal(fullMethod string) bool {
+	return p[fullMethod].Internal
+}
+
+// Authorize 检查 claims 中的角色是否允许访问该方法
+func (p Policies) Authorize(fullMethod string, claims *Claims) error {
+	policy := p[fullMethod]
+	if policy.Public || len(policy.Roles) == 0 {
+		return nil
+	}
+	for _, role := range claims.Roles {
+		if slices.Contains(policy.Roles, role) {
+			return nil
+		}
+	}
+	return apperrors.ErrPermissionDenied
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// 从 user.proto、admin.proto、internal.proto 中读取访问控制规则
+func TestNewPolicies(t *testing.T) {
+	policies := NewPolicies()
+
+	assert.True(t, policies.IsPublic("/user.v1.UserService/Login"))
+	assert.True(t, policies.IsPublic("/user.v1.UserService/Register"))
+	assert.False(t, policies.IsPublic("/user.v1.UserService/GetMyProfile"))
+	assert.False(t, policies.IsPublic("/user.v1.UserService/NotExists"))
+
+	// 内部接口只允许服务凭证调用
+	assert.True(t, policies.IsInternal("/user.v1.InternalUserService/BatchGetUsers"))
+	assert.False(t, policies.IsInternal("/user.v1.AdminUserService/GetUser"))
+}
+
+func TestPolicies_Authorize(t *testing.T) {
+	policies := Policies{
+		"/test.Service/Public": {Public: true},
+		"/test.Service/Admin":  {Roles: []string{"admin"}},
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		roles   []string
+		wantErr error
+	}{
+		{"公开方法", "/test.Service/Public", nil, nil},
+		{"未声明规则的方法", "/test.Service/Other", nil, nil},
+		{"拥有角色", "/test.Service/Admin", []string{"customer", "admin"}, nil},
+		{"没有角色", "/test.Service/Admin", []string{"customer"}, apperrors.ErrPermissionDenied},
+		{"角色为空", "/test.Service/Admin", nil, apperrors.ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policies.Authorize(tt.method, &Claims{Roles: tt.roles})
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
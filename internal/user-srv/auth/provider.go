@@ -2,4 +2,4 @@ package auth
 
 import "github.com/google/wire"
 
-var ProviderSet = wire.NewSet(NewAuth)
+var ProviderSet = wire.NewSet(NewAuth, NewPolicies, NewServiceCredentials, NewIntrospector)
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// RevocationList 访问令牌吊销列表，按 jti 记录。
+// 记录在令牌原本的过期时间之后自动失效，列表不会无限增长。
+type RevocationList interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type revocationChecker struct {
+	list RevocationList
+}
+
+func NewRevocationChecker(list RevocationList) ClaimsChecker {
+	return &revocationChecker{list: list}
+}
+
+func (c *revocationChecker) Check(ctx context.Context, claims *Claims) error {
+	// 没有 jti 的令牌无法被吊销，直接拒绝
+	if claims.ID == "" {
+		return apperrors.ErrTokenInvalid
+	}
+	revoked, err := c.list.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return apperrors.ErrTokenRevoked
+	}
+	return nil
+}
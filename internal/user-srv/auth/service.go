@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+// ServiceCredentials 允许调用内部接口的服务，按密钥的 SHA-256 识别调用方
+type ServiceCredentials struct {
+	services []serviceKey
+}
+
+type serviceKey struct {
+	name string
+	hash []byte
+}
+
+func NewServiceCredentials(c *conf.Auth) (*ServiceCredentials, error) {
+	s := &ServiceCredentials{}
+	for _, service := range c.Services {
+		if service.Name == "" {
+			return nil, fmt.Errorf("service credential name is required")
+		}
+		hash, err := hex.DecodeString(service.KeyHash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid key_hash for service %s: expected hex encoded SHA-256", service.Name)
+		}
+		s.services = append(s.services, serviceKey{name: service.Name, hash: hash})
+	}
+	return s, nil
+}
+
+// Authenticate 返回密钥对应的服务名，不匹配任何服务时 ok 为 false
+func (s *ServiceCredentials) Authenticate(key string) (name string, ok bool) {
+	if key == "" {
+		return "", false
+	}
+	hash := sha256.Sum256([]byte(key))
+	for _, service := range s.services {
+		if subtle.ConstantTimeCompare(hash[:], service.hash) == 1 {
+			return service.name, true
+		}
+	}
+	return "", false
+}
+
+type serviceNameKey struct{}
+
+// ServiceToContext 和 ServiceFromContext 保存通过服务凭证认证的调用方
+func ServiceToContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, serviceNameKey{}, name)
+}
+
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(serviceNameKey{}).(string)
+	return name, ok
+}
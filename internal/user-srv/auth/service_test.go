@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+func hashServiceKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestServiceCredentials_Authenticate(t *testing.T) {
+	s, err := NewServiceCredentials(&conf.Auth{Services: []conf.ServiceCredential{
+		{Name: "order-srv", KeyHash: hashServiceKey("order-key")},
+		{Name: "review-srv", KeyHash: hashServiceKey("review-key")},
+	}})
+	require.NoError(t, err)
+
+	name, ok := s.Authenticate("review-key")
+	assert.True(t, ok)
+	assert.Equal(t, "review-srv", name)
+
+	_, ok = s.Authenticate("other-key")
+	assert.False(t, ok)
+	_, ok = s.Authenticate("")
+	assert.False(t, ok)
+}
+
+func TestNewServiceCredentials(t *testing.T) {
+	// 没有配置服务时不接受任何密钥
+	s, err := NewServiceCredentials(&conf.Auth{})
+	require.NoError(t, err)
+	_, ok := s.Authenticate("order-key")
+	assert.False(t, ok)
+
+	_, err = NewServiceCredentials(&conf.Auth{Services: []conf.ServiceCredential{{Name: "order-srv", KeyHash: "order-key"}}})
+	assert.Error(t, err)
+
+	_, err = NewServiceCredentials(&conf.Auth{Services: []conf.ServiceCredential{{KeyHash: hashServiceKey("order-key")}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,251 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+)
+
+// 管理后台分页参数
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// AdminUserService 客服、管理员对用户的管理操作，operatorID 为操作人的用户ID
+type AdminUserService interface {
+	ListUsers(ctx context.Context, filter *UserFilter) ([]*User, int64, error)
+	// GetUser 查询时包括已删除的用户
+	GetUser(ctx context.Context, id uint) (*User, error)
+	DisableUser(ctx context.Context, operatorID, id uint, reason string) (*User, error)
+	EnableUser(ctx context.Context, operatorID, id uint, reason string) (*User, error)
+	DeleteUser(ctx context.Context, operatorID, id uint) error
+	RestoreUser(ctx context.Context, operatorID, id uint) (*User, error)
+}
+
+type adminUserUsecase struct {
+	repo    UserRepo
+	changes ProfileChangeRepo
+	audit   AuditService
+	now     func() time.Time
+}
+
+func NewAdminUserUsecase(repo UserRepo, changes ProfileChangeRepo, audit AuditService) AdminUserService {
+	return &adminUserUsecase{
+		repo:    repo,
+		changes: changes,
+		audit:   audit,
+		now:     time.Now,
+	}
+}
+
+func (uc *adminUserUsecase) ListUsers(ctx context.Context, filter *UserFilter) ([]*User, int64, error) {
+	filter.Offset, filter.Limit = normalizePage(filter.Offset, filter.Limit)
+	return uc.repo.List(ctx, filter)
+}
+
+// normalizePage 使用默认分页大小并限制最大分页大小
+func normalizePage(offset, limit int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return max(offset, 0), limit
+}
+
+func (uc *adminUserUsecase) GetUser(ctx context.Context, id uint) (*User, error) {
+	return uc.repo.FindByIDWithDeleted(ctx, id)
+}
+
+// DisableUser 禁用后用户不能登录，已签发的令牌也不再有效
+func (uc *adminUserUsecase) DisableUser(ctx context.Context, operatorID, id uint, reason string) (*User, error) {
+	// 1. 校验参数
+	if reason == "" {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("disable_reason_required", "禁用原因不能为空")
+	}
+	if operatorID == id {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("disable_self", "不能禁用自己的账号")
+	}
+
+	// 2. 获取用户信息并检查操作人的权限，已禁用时直接返回
+	user, err := uc.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkOperator(ctx, operatorID, user); err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return user, nil
+	}
+
+	// 3. 保存禁用状态并记录变更
+	now := uc.now()
+	user.DisabledAt = &now
+	user.DisabledReason = reason
+	if err := uc.repo.Update(ctx, user, "DisabledAt", "DisabledReason"); err != nil {
+		return nil, err
+	}
+	if err := uc.recordStatusChange(ctx, operatorID, id, "active", "disabled", reason); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: id, ActorID: operatorID, Event: logevent.EventAdminUserDisabled, Detail: reason})
+	return user, nil
+}
+
+// EnableUser 解除禁用
+func (uc *adminUserUsecase) EnableUser(ctx context.Context, operatorID, id uint, reason string) (*User, error) {
+	user, err := uc.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkOperator(ctx, operatorID, user); err != nil {
+		return nil, err
+	}
+	if user.DisabledAt == nil {
+		return user, nil
+	}
+
+	user.DisabledAt = nil
+	user.DisabledReason = ""
+	if err := uc.repo.Update(ctx, user, "DisabledAt", "DisabledReason"); err != nil {
+		return nil, err
+	}
+	if err := uc.recordStatusChange(ctx, operatorID, id, "disabled", "active", reason); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: id, ActorID: operatorID, Event: logevent.EventAdminUserEnabled, Detail: reason})
+	return user, nil
+}
+
+// roleRank 角色的权限级别，取用户所有角色中最高的
+func roleRank(roles []string) int {
+	rank := 0
+	for _, role := range roles {
+		switch role {
+		case RoleSupport:
+			rank = max(rank, 1)
+		case RoleAdmin:
+			rank = max(rank, 2)
+		}
+	}
+	return rank
+}
+
+// checkOperator 管理员可以操作所有账号，其他操作人（客服）只能操作权限级别低于自己的账号。
+// 操作人的角色从数据库读取，令牌签发后被降级的操作人立即失去权限
+func (uc *adminUserUsecase) checkOperator(ctx context.Context, operatorID uint, target *User) error {
+	operator, err := uc.repo.FindByIDUncached(ctx, operatorID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return apperrors.ErrPermissionDenied
+	}
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(operator.Roles, RoleAdmin) && roleRank(target.Roles) >= roleRank(operator.Roles) {
+		return apperrors.ErrPermissionDenied.WithMessageKey("target_role", "不能操作权限不低于自己的账号")
+	}
+	return nil
+}
+
+// DeleteUser 软删除用户
+func (uc *adminUserUsecase) DeleteUser(ctx context.Context, operatorID, id uint) error {
+	if operatorID == id {
+		return apperrors.ErrInvalidArgument.WithMessageKey("delete_self", "不能删除自己的账号")
+	}
+	user, err := uc.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := uc.recordStatusChange(ctx, operatorID, id, statusName(user), "deleted", ""); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: id, ActorID: operatorID, Event: logevent.EventAdminUserDeleted})
+	return nil
+}
+
+// RestoreUser 恢复已删除的用户
+func (uc *adminUserUsecase) RestoreUser(ctx context.Context, operatorID, id uint) (*User, error) {
+	// 1. 获取用户信息，未删除时直接返回
+	user, err := uc.repo.FindByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletedAt == nil {
+		return user, nil
+	}
+
+	// 2. 删除期间用户名、邮箱、手机号可能已被其他用户使用
+	if err := uc.checkConflict(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// 3. 恢复并记录变更
+	if err := uc.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	user.DeletedAt = nil
+	if err := uc.recordStatusChange(ctx, operatorID, id, "deleted", statusName(user), ""); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: id, ActorID: operatorID, Event: logevent.EventAdminUserRestored})
+	return user, nil
+}
+
+func (uc *adminUserUsecase) checkConflict(ctx context.Context, user *User) error {
+	checks := []struct {
+		find      func(context.Context, string) (*User, error)
+		value     string
+		errExists error
+	}{
+		{uc.repo.FindByUsername, user.UserName, apperrors.ErrUserAlreadyExists},
+		{uc.repo.FindByEmail, user.Email, apperrors.ErrEmailAlreadyExists},
+		{uc.repo.FindByPhone, user.Phone, apperrors.ErrPhoneAlreadyExists},
+	}
+	for _, c := range checks {
+		existing, err := c.find(ctx, c.value)
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if existing.ID != user.ID {
+			return c.errExists
+		}
+	}
+	return nil
+}
+
+func statusName(user *User) string {
+	switch {
+	case user.DeletedAt != nil:
+		return "deleted"
+	case user.DisabledAt != nil:
+		return "disabled"
+	default:
+		return "active"
+	}
+}
+
+// recordStatusChange 用户状态的变化记录在资料变更中，字段名为 Status
+func (uc *adminUserUsecase) recordStatusChange(ctx context.Context, operatorID, id uint, oldStatus, newStatus, reason string) error {
+	return uc.changes.Create(ctx, []*ProfileChange{{
+		UserID:    id,
+		Field:     "Status",
+		OldValue:  oldStatus,
+		NewValue:  newStatus,
+		ChangedBy: operatorID,
+		Reason:    reason,
+		ChangedAt: uc.now(),
+	}})
+}
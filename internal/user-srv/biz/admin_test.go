@@ -0,0 +1,143 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+func newTestAdminUsecase(t *testing.T) (biz.AdminUserService, *mock.MockUserRepo, *mock.MockProfileChangeRepo) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctrl)
+	changes := mock.NewMockProfileChangeRepo(ctrl)
+	return biz.NewAdminUserUsecase(repo, changes, newTestAudit()), repo, changes
+}
+
+func TestAdminUserUsecase_ListUsers(t *testing.T) {
+	uc, repo, _ := newTestAdminUsecase(t)
+
+	tests := []struct {
+		name      string
+		filter    *biz.UserFilter
+		wantLimit int
+	}{
+		{name: "默认分页大小", filter: &biz.UserFilter{}, wantLimit: biz.DefaultPageSize},
+		{name: "超过最大分页大小", filter: &biz.UserFilter{Limit: 1000}, wantLimit: biz.MaxPageSize},
+		{name: "正常分页大小", filter: &biz.UserFilter{Limit: 10, Offset: 20}, wantLimit: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, filter *biz.UserFilter) ([]*biz.User, int64, error) {
+					assert.Equal(t, tt.wantLimit, filter.Limit)
+					return nil, 0, nil
+				})
+			_, _, err := uc.ListUsers(context.Background(), tt.filter)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAdminUserUsecase_DisableUser(t *testing.T) {
+	uc, repo, changes := newTestAdminUsecase(t)
+	ctx := context.Background()
+
+	t.Run("禁用原因不能为空", func(t *testing.T) {
+		_, err := uc.DisableUser(ctx, 1, 2, "")
+		assert.ErrorIs(t, err, apperrors.ErrInvalidArgument)
+	})
+
+	t.Run("不能禁用自己", func(t *testing.T) {
+		_, err := uc.DisableUser(ctx, 1, 1, "测试")
+		assert.ErrorIs(t, err, apperrors.ErrInvalidArgument)
+	})
+
+	t.Run("客服不能禁用管理员和其他客服", func(t *testing.T) {
+		for _, role := range []string{biz.RoleAdmin, biz.RoleSupport} {
+			repo.EXPECT().FindByID(gomock.Any(), uint(2)).Return(&biz.User{ID: 2, Roles: []string{biz.RoleCustomer, role}}, nil)
+			repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Roles: []string{biz.RoleSupport}}, nil)
+			_, err := uc.DisableUser(ctx, 1, 2, "违规操作")
+			assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+		}
+	})
+
+	t.Run("管理员可以禁用管理员", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), uint(2)).Return(&biz.User{ID: 2, Roles: []string{biz.RoleAdmin}}, nil)
+		repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Roles: []string{biz.RoleAdmin}}, nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any(), "DisabledAt", "DisabledReason").Return(nil)
+		changes.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		user, err := uc.DisableUser(ctx, 1, 2, "违规操作")
+		require.NoError(t, err)
+		assert.NotNil(t, user.DisabledAt)
+	})
+
+	t.Run("禁用成功", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), uint(2)).Return(&biz.User{ID: 2, Roles: []string{biz.RoleCustomer}}, nil)
+		repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Roles: []string{biz.RoleSupport}}, nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any(), "DisabledAt", "DisabledReason").Return(nil)
+		changes.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, records []*biz.ProfileChange) error {
+				require.Len(t, records, 1)
+				assert.Equal(t, "Status", records[0].Field)
+				assert.Equal(t, "disabled", records[0].NewValue)
+				assert.Equal(t, uint(1), records[0].ChangedBy)
+				assert.Equal(t, "违规操作", records[0].Reason)
+				return nil
+			})
+		user, err := uc.DisableUser(ctx, 1, 2, "违规操作")
+		require.NoError(t, err)
+		assert.NotNil(t, user.DisabledAt)
+		assert.Equal(t, "违规操作", user.DisabledReason)
+	})
+}
+
+func TestAdminUserUsecase_EnableUser(t *testing.T) {
+	uc, repo, changes := newTestAdminUsecase(t)
+	disabledAt := time.Now()
+	repo.EXPECT().FindByID(gomock.Any(), uint(2)).
+		Return(&biz.User{ID: 2, DisabledAt: &disabledAt, DisabledReason: "违规操作"}, nil)
+	repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Roles: []string{biz.RoleSupport}}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), "DisabledAt", "DisabledReason").Return(nil)
+	changes.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	user, err := uc.EnableUser(context.Background(), 1, 2, "")
+	require.NoError(t, err)
+	assert.Nil(t, user.DisabledAt)
+	assert.Empty(t, user.DisabledReason)
+}
+
+func TestAdminUserUsecase_RestoreUser(t *testing.T) {
+	uc, repo, changes := newTestAdminUsecase(t)
+	ctx := context.Background()
+	deleted := func() *biz.User {
+		deletedAt := time.Now()
+		return &biz.User{ID: 2, UserName: "testuser", Email: "test@example.com", Phone: "13800138000", DeletedAt: &deletedAt}
+	}
+
+	t.Run("用户名已被占用", func(t *testing.T) {
+		repo.EXPECT().FindByIDWithDeleted(gomock.Any(), uint(2)).Return(deleted(), nil)
+		repo.EXPECT().FindByUsername(gomock.Any(), "testuser").Return(&biz.User{ID: 3}, nil)
+		_, err := uc.RestoreUser(ctx, 1, 2)
+		assert.Equal(t, apperrors.ErrUserAlreadyExists, err)
+	})
+
+	t.Run("恢复成功", func(t *testing.T) {
+		repo.EXPECT().FindByIDWithDeleted(gomock.Any(), uint(2)).Return(deleted(), nil)
+		repo.EXPECT().FindByUsername(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrUserNotFound)
+		repo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrUserNotFound)
+		repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrUserNotFound)
+		repo.EXPECT().Restore(gomock.Any(), uint(2)).Return(nil)
+		changes.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		user, err := uc.RestoreUser(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Nil(t, user.DeletedAt)
+	})
+}
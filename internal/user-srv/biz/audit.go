@@ -0,0 +1,83 @@
+package biz
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+	"github.com/kyson/e-shop-native/pkg/trace"
+	"github.com/kyson/e-shop-native/pkg/useragent"
+)
+
+// AuditEvent 账号安全相关的审计事件，写入后不允许修改
+type AuditEvent struct {
+	ID        uint
+	UserID    uint // 事件所属的用户，账号不存在的登录失败为 0
+	ActorID   uint // 操作人，用户自己操作时与 UserID 相同，匿名请求或系统触发的事件为 0
+	Event     logevent.Event
+	TraceID   string
+	ClientIP  string
+	UserAgent string
+	Detail    string
+	CreatedAt time.Time
+}
+
+// AuditFilter 查询条件，零值表示不过滤
+type AuditFilter struct {
+	UserID  uint
+	ActorID uint
+	Event   logevent.Event
+	Offset  int
+	Limit   int
+}
+
+// AuditRepo 只追加的审计事件存储
+type AuditRepo interface {
+	Append(ctx context.Context, event *AuditEvent) error
+	// List 按时间倒序返回符合条件的事件以及总数
+	List(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, int64, error)
+}
+
+type AuditService interface {
+	// Record 记录一个事件，trace ID、客户端 IP 和 User-Agent 从 ctx 中读取。
+	// 写入失败只记录日志，不影响正在进行的业务操作
+	Record(ctx context.Context, event *AuditEvent)
+	// ListEvents 分页查询事件
+	ListEvents(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, int64, error)
+}
+
+type auditUsecase struct {
+	repo AuditRepo
+	log  *zap.Logger
+	now  func() time.Time
+}
+
+func NewAuditUsecase(repo AuditRepo, log *zap.Logger) AuditService {
+	return &auditUsecase{
+		repo: repo,
+		log:  log,
+		now:  time.Now,
+	}
+}
+
+func (uc *auditUsecase) Record(ctx context.Context, event *AuditEvent) {
+	event.TraceID, _ = trace.FromContext(ctx)
+	event.ClientIP, _ = clientip.FromContext(ctx)
+	event.UserAgent, _ = useragent.FromContext(ctx)
+	event.CreatedAt = uc.now()
+	if err := uc.repo.Append(ctx, event); err != nil {
+		uc.log.Error("failed to record audit event",
+			zap.String("event", event.Event.String()),
+			zap.Uint("user_id", event.UserID),
+			zap.String("trace_id", event.TraceID),
+			zap.Error(err))
+	}
+}
+
+func (uc *auditUsecase) ListEvents(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, int64, error) {
+	filter.Offset, filter.Limit = normalizePage(filter.Offset, filter.Limit)
+	return uc.repo.List(ctx, filter)
+}
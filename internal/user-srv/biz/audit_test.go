@@ -0,0 +1,101 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+	"github.com/kyson/e-shop-native/pkg/trace"
+	"github.com/kyson/e-shop-native/pkg/useragent"
+)
+
+// 记录事件时从 context 中读取请求信息
+func TestAuditUsecase_Record(t *testing.T) {
+	audit := biz.NewAuditUsecase(data.NewMemoryAuditRepo(), zap.NewNop())
+	ctx := trace.ToContext(context.Background(), "trace-1")
+	ctx = clientip.ToContext(ctx, "10.0.0.1")
+	ctx = useragent.ToContext(ctx, "Mozilla/5.0")
+
+	audit.Record(ctx, &biz.AuditEvent{UserID: 1, ActorID: 1, Event: logevent.EventUserLogin})
+
+	events, total, err := audit.ListEvents(context.Background(), &biz.AuditFilter{UserID: 1})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	assert.Equal(t, "trace-1", events[0].TraceID)
+	assert.Equal(t, "10.0.0.1", events[0].ClientIP)
+	assert.Equal(t, "Mozilla/5.0", events[0].UserAgent)
+	assert.False(t, events[0].CreatedAt.IsZero())
+}
+
+// 按用户过滤，按时间倒序分页
+func TestAuditUsecase_ListEvents(t *testing.T) {
+	audit := biz.NewAuditUsecase(data.NewMemoryAuditRepo(), zap.NewNop())
+	ctx := context.Background()
+	for _, event := range []logevent.Event{logevent.EventUserCreated, logevent.EventUserLogin, logevent.EventUserLogout} {
+		audit.Record(ctx, &biz.AuditEvent{UserID: 1, ActorID: 1, Event: event})
+	}
+	audit.Record(ctx, &biz.AuditEvent{UserID: 2, ActorID: 2, Event: logevent.EventUserLogin})
+
+	events, total, err := audit.ListEvents(ctx, &biz.AuditFilter{UserID: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	require.Len(t, events, 2)
+	assert.Equal(t, logevent.EventUserLogout, events[0].Event)
+	assert.Equal(t, logevent.EventUserLogin, events[1].Event)
+
+	events, total, err = audit.ListEvents(ctx, &biz.AuditFilter{Event: logevent.EventUserLogin})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Len(t, events, 2)
+}
+
+// 登录成功和失败都会记录审计事件
+func TestUserUsecase_LoginAudit(t *testing.T) {
+	ctl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	audit := biz.NewAuditUsecase(data.NewMemoryAuditRepo(), zap.NewNop())
+	uc := biz.NewUserUsecase(repo, mock.NewMockProfileChangeRepo(ctl), mock.NewMockPasswordHistoryRepo(ctl),
+		validate, passwordHash, newTestLimiter(), audit, &conf.Auth{})
+	ctx := context.Background()
+
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
+	repo.EXPECT().FindByUsername(gomock.Any(), "testuser").Return(&biz.User{
+		ID: 1, UserName: "testuser", Password: "hashed_password",
+	}, nil).Times(2)
+	repo.EXPECT().FindByUsername(gomock.Any(), "nobody").Return(nil, apperrors.ErrUserNotFound)
+	passwordHash.EXPECT().Virefy("wrong", "hashed_password").Return(false)
+	passwordHash.EXPECT().Virefy("pAssword123", "hashed_password").Return(true)
+	passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false)
+
+	_, err := uc.Login(ctx, "nobody", "pAssword123")
+	assert.Equal(t, apperrors.ErrUserNotFound, err)
+	_, err = uc.Login(ctx, "testuser", "wrong")
+	assert.Equal(t, apperrors.ErrPasswordIncorrect, err)
+	_, err = uc.Login(ctx, "testuser", "pAssword123")
+	require.NoError(t, err)
+	require.NoError(t, uc.CompleteLogin(ctx, 1))
+
+	events, _, err := audit.ListEvents(ctx, &biz.AuditFilter{UserID: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, logevent.EventUserLogin, events[0].Event)
+	assert.Equal(t, logevent.EventUserLoginPasswordIncorrect, events[1].Event)
+
+	events, _, err = audit.ListEvents(ctx, &biz.AuditFilter{Event: logevent.EventUserLoginNotFound})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "nobody", events[0].Detail)
+}
@@ -0,0 +1,214 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// 头像默认限制，配置为 0 时使用
+const (
+	defaultAvatarMaxSize       = 2 << 20
+	defaultAvatarThumbnailSize = 128
+	// maxAvatarDimension 图片的最大宽高，避免解码时占用过多内存
+	maxAvatarDimension = 4096
+)
+
+// BlobStore 保存上传的文件，key 使用 / 分隔的相对路径
+type BlobStore interface {
+	// Put 保存文件并返回访问地址，key 已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+type AvatarService interface {
+	// UploadAvatar 校验图片后保存原图和缩略图，并替换用户当前的头像
+	UploadAvatar(ctx context.Context, userID uint, data []byte) (*User, error)
+}
+
+type avatarUsecase struct {
+	repo          UserRepo
+	store         BlobStore
+	maxSize       int64
+	thumbnailSize int
+}
+
+func NewAvatarUsecase(repo UserRepo, store BlobStore, c *conf.Avatar) AvatarService {
+	uc := &avatarUsecase{
+		repo:          repo,
+		store:         store,
+		maxSize:       defaultAvatarMaxSize,
+		thumbnailSize: defaultAvatarThumbnailSize,
+	}
+	if c != nil && c.MaxSize > 0 {
+		uc.maxSize = c.MaxSize
+	}
+	if c != nil && c.ThumbnailSize > 0 {
+		uc.thumbnailSize = c.ThumbnailSize
+	}
+	return uc
+}
+
+// avatarFormats 支持的图片类型，key 为 http.DetectContentType 的结果
+var avatarFormats = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+func (uc *avatarUsecase) UploadAvatar(ctx context.Context, userID uint, data []byte) (*User, error) {
+	// 1. 校验图片大小、类型和尺寸，类型以文件内容为准，不信任客户端声明的类型
+	img, contentType, err := uc.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 获取用户信息
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 重新编码原图（去掉 EXIF 等元数据）并生成缩略图
+	original, contentType, ext, err := encodeImage(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+	thumb, _, _, err := encodeImage(thumbnail(img, uc.thumbnailSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 保存到 BlobStore，每次上传使用新的 key，避免 CDN 缓存旧头像
+	key, err := newAvatarKey(userID, ext)
+	if err != nil {
+		return nil, err
+	}
+	url, err := uc.store.Put(ctx, key, original, contentType)
+	if err != nil {
+		return nil, err
+	}
+	thumbURL, err := uc.store.Put(ctx, avatarThumbnailKey(key), thumb, contentType)
+	if err != nil {
+		uc.deleteBlobs(ctx, key)
+		return nil, err
+	}
+
+	// 5. 更新用户头像，失败时删除刚保存的文件
+	oldKey := user.AvatarKey
+	user.AvatarKey, user.AvatarURL, user.AvatarThumbnailURL = key, url, thumbURL
+	if err := uc.repo.Update(ctx, user, "AvatarKey", "AvatarURL", "AvatarThumbnailURL"); err != nil {
+		uc.deleteBlobs(ctx, key)
+		return nil, err
+	}
+
+	// 6. 删除旧头像，失败不影响上传结果
+	if oldKey != "" {
+		uc.deleteBlobs(ctx, oldKey)
+	}
+	return user, nil
+}
+
+func (uc *avatarUsecase) decode(data []byte) (image.Image, string, error) {
+	if len(data) == 0 {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_required", "请选择头像图片")
+	}
+	if int64(len(data)) > uc.maxSize {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_too_large", "头像图片不能超过 %dKB", uc.maxSize>>10)
+	}
+	contentType := http.DetectContentType(data)
+	if !avatarFormats[contentType] {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_format", "头像只支持 JPEG、PNG、GIF 格式")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_corrupted", "头像图片已损坏")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxAvatarDimension || cfg.Height > maxAvatarDimension {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_dimension", "头像图片宽高不能超过 %d 像素", maxAvatarDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", apperrors.ErrInvalidArgument.WithMessageKey("avatar_corrupted", "头像图片已损坏")
+	}
+	return img, contentType, nil
+}
+
+// deleteBlobs 删除原图和缩略图
+func (uc *avatarUsecase) deleteBlobs(ctx context.Context, key string) {
+	_ = uc.store.Delete(ctx, key)
+	_ = uc.store.Delete(ctx, avatarThumbnailKey(key))
+}
+
+func newAvatarKey(userID uint, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate avatar key: %w", err)
+	}
+	return fmt.Sprintf("avatars/%d/%s%s", userID, hex.EncodeToString(b), ext), nil
+}
+
+// avatarThumbnailKey 缩略图与原图保存在同一目录，文件名加 _thumb 后缀
+func avatarThumbnailKey(key string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_thumb" + ext
+}
+
+// encodeImage JPEG 仍然保存为 JPEG，PNG 和 GIF 保存为 PNG 以保留透明度，GIF 动图只保留第一帧
+func encodeImage(img image.Image, contentType string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
+
+// thumbnail 从图片中间截取正方形后缩小到 size 像素，每个目标像素取对应区域的平均值。
+// 图片比 size 小时不放大
+func thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	size = min(size, side)
+
+	dst := image.NewRGBA64(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		sy0, sy1 := y0+dy*side/size, y0+(dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			sx0, sx1 := x0+dx*side/size, x0+(dx+1)*side/size
+			var r, g, bl, a, n uint64
+			for y := sy0; y < sy1; y++ {
+				for x := sx0; x < sx1; x++ {
+					cr, cg, cb, ca := src.At(x, y).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(dx, dy, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
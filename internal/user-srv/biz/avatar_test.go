@@ -0,0 +1,142 @@
+package biz_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type fakeBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (s *fakeBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	s.blobs[key] = data
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (s *fakeBlobStore) Delete(ctx context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func newTestAvatarUsecase(t *testing.T) (biz.AvatarService, *mock.MockUserRepo, *fakeBlobStore) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctrl)
+	store := &fakeBlobStore{blobs: make(map[string][]byte)}
+	return biz.NewAvatarUsecase(repo, store, &conf.Avatar{MaxSize: 1 << 20, ThumbnailSize: 16}), repo, store
+}
+
+func newTestImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestAvatarUsecase_UploadAvatar(t *testing.T) {
+	uc, repo, store := newTestAvatarUsecase(t)
+	oldKey := "avatars/1/old.png"
+	store.blobs[oldKey] = []byte("old")
+	store.blobs["avatars/1/old_thumb.png"] = []byte("old")
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, AvatarKey: oldKey}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), "AvatarKey", "AvatarURL", "AvatarThumbnailURL").Return(nil)
+
+	user, err := uc.UploadAvatar(context.Background(), 1, encodeTestPNG(t, newTestImage(64, 40)))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(user.AvatarKey, "avatars/1/"))
+	assert.Equal(t, "https://cdn.example.com/"+user.AvatarKey, user.AvatarURL)
+	assert.True(t, strings.HasSuffix(user.AvatarThumbnailURL, "_thumb.png"))
+
+	// 旧头像被删除，只剩新的原图和缩略图
+	assert.Len(t, store.blobs, 2)
+	assert.NotContains(t, store.blobs, oldKey)
+
+	// 缩略图从中间截取为正方形
+	thumbKey := strings.TrimPrefix(user.AvatarThumbnailURL, "https://cdn.example.com/")
+	thumb, err := png.DecodeConfig(bytes.NewReader(store.blobs[thumbKey]))
+	require.NoError(t, err)
+	assert.Equal(t, 16, thumb.Width)
+	assert.Equal(t, 16, thumb.Height)
+}
+
+func TestAvatarUsecase_UploadAvatarJPEG(t *testing.T) {
+	uc, repo, store := newTestAvatarUsecase(t)
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, newTestImage(8, 8), nil))
+	user, err := uc.UploadAvatar(context.Background(), 1, buf.Bytes())
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(user.AvatarKey, ".jpg"))
+
+	// 图片比缩略图小时不放大
+	thumbKey := strings.TrimPrefix(user.AvatarThumbnailURL, "https://cdn.example.com/")
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(store.blobs[thumbKey]))
+	require.NoError(t, err)
+	assert.Equal(t, 8, thumb.Width)
+}
+
+func TestAvatarUsecase_InvalidImage(t *testing.T) {
+	uc, _, _ := newTestAvatarUsecase(t)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "空文件", data: nil,
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("avatar_required", "请选择头像图片")},
+		{name: "超过大小限制", data: make([]byte, 1<<20+1),
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("avatar_too_large", "头像图片不能超过 %dKB", int64(1024))},
+		{name: "不支持的类型", data: []byte("<html><body>hi</body></html>"),
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("avatar_format", "头像只支持 JPEG、PNG、GIF 格式")},
+		{name: "图片已损坏", data: encodeTestPNG(t, newTestImage(4, 4))[:40],
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("avatar_corrupted", "头像图片已损坏")},
+		{name: "尺寸过大", data: encodeTestPNG(t, image.NewGray(image.Rect(0, 0, 5000, 1))),
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("avatar_dimension", "头像图片宽高不能超过 %d 像素", 4096)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.UploadAvatar(context.Background(), 1, tt.data)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAvatarUsecase_UpdateFailed(t *testing.T) {
+	uc, repo, store := newTestAvatarUsecase(t)
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+	_, err := uc.UploadAvatar(context.Background(), 1, encodeTestPNG(t, newTestImage(4, 4)))
+	require.Error(t, err)
+	// 保存用户失败时删除已上传的文件
+	assert.Empty(t, store.blobs)
+}
@@ -0,0 +1,178 @@
+package biz
+
+//go:generate mockgen -source=code.go -destination=mock/mocker_code.go -package=mock
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// 验证码用途，不同用途的验证码互不通用
+const (
+	CodePurposePasswordReset = "password_reset"
+	CodePurposeVerifyEmail   = "verify_email"
+	CodePurposeVerifyPhone   = "verify_phone"
+)
+
+// 通知渠道
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// VerificationCode 一次性验证码，只保存哈希值
+type VerificationCode struct {
+	Purpose   string
+	Target    string // 接收验证码的邮箱或手机号
+	UserID    uint
+	CodeHash  string
+	ExpiresAt time.Time
+}
+
+type VerificationCodeRepo interface {
+	// Save 保存验证码，同一 purpose+target 只保留最新的一个，过期后自动删除
+	Save(ctx context.Context, code *VerificationCode) error
+	// Get 验证码不存在或已过期时返回 ErrVerificationCodeInvalid
+	Get(ctx context.Context, purpose, target string) (*VerificationCode, error)
+	// IncrAttempts 记录一次错误尝试，返回累计错误次数
+	IncrAttempts(ctx context.Context, purpose, target string) (int, error)
+	// Delete 删除验证码，返回 false 说明验证码已经不存在（被其他请求使用了）
+	Delete(ctx context.Context, purpose, target string) (bool, error)
+	// SetCooldown 开始一个发送冷却期，返回 false 说明上一个冷却期还没有结束
+	SetCooldown(ctx context.Context, purpose, target string, d time.Duration) (bool, error)
+}
+
+// Notification 发送给用户的一条通知
+type Notification struct {
+	Channel string // email、sms
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier 负责把通知发送给用户，具体实现可以是邮件、短信或者控制台
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+type VerificationCodeService interface {
+	// CheckSendRate 在查找账号之前调用，冷却期内返回 ErrVerificationCodeTooFrequent，
+	// 结果与账号是否存在无关，不会暴露账号信息
+	CheckSendRate(ctx context.Context, purpose, target string) error
+	// Issue 生成一个新的验证码并返回明文，旧的验证码随之失效
+	Issue(ctx context.Context, purpose, target string, userID uint) (string, error)
+	// Verify 校验并消费验证码，每个验证码只能成功使用一次
+	Verify(ctx context.Context, purpose, target, code string) (*VerificationCode, error)
+}
+
+type verificationCodeUsecase struct {
+	repo        VerificationCodeRepo
+	ttl         time.Duration
+	maxAttempts int
+	interval    time.Duration
+	now         func() time.Time
+}
+
+func NewVerificationCodeUsecase(repo VerificationCodeRepo, c *conf.Verification) VerificationCodeService {
+	return &verificationCodeUsecase{
+		repo:        repo,
+		ttl:         time.Second * time.Duration(c.CodeExpireDuration),
+		maxAttempts: c.MaxAttempts,
+		interval:    time.Second * time.Duration(c.SendInterval),
+		now:         time.Now,
+	}
+}
+
+// CheckSendRate limits how often codes can be sent to the same target.
+func (uc *verificationCodeUsecase) CheckSendRate(ctx context.Context, purpose, target string) error {
+	if uc.interval <= 0 {
+		return nil
+	}
+	ok, err := uc.repo.SetCooldown(ctx, purpose, target, uc.interval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.ErrVerificationCodeTooFrequent
+	}
+	return nil
+}
+
+// Issue generates a new one-time code for the target.
+func (uc *verificationCodeUsecase) Issue(ctx context.Context, purpose, target string, userID uint) (string, error) {
+	code, err := newVerificationCode()
+	if err != nil {
+		return "", err
+	}
+	err = uc.repo.Save(ctx, &VerificationCode{
+		Purpose:   purpose,
+		Target:    target,
+		UserID:    userID,
+		CodeHash:  hashVerificationCode(purpose, target, code),
+		ExpiresAt: uc.now().Add(uc.ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Verify checks the code and consumes it on success.
+func (uc *verificationCodeUsecase) Verify(ctx context.Context, purpose, target, code string) (*VerificationCode, error) {
+	// 1. 获取验证码
+	stored, err := uc.repo.Get(ctx, purpose, target)
+	if err != nil {
+		return nil, err
+	}
+	if uc.now().After(stored.ExpiresAt) {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+
+	// 2. 比较哈希，错误次数过多时作废验证码
+	hash := hashVerificationCode(purpose, target, code)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(stored.CodeHash)) != 1 {
+		attempts, err := uc.repo.IncrAttempts(ctx, purpose, target)
+		if err != nil {
+			return nil, err
+		}
+		if uc.maxAttempts > 0 && attempts >= uc.maxAttempts {
+			if _, err := uc.repo.Delete(ctx, purpose, target); err != nil {
+				return nil, err
+			}
+		}
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+
+	// 3. 消费验证码，并发请求中只有一个能成功
+	deleted, err := uc.repo.Delete(ctx, purpose, target)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+	return stored, nil
+}
+
+// 6 位数字验证码
+func newVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashVerificationCode(purpose, target, code string) string {
+	sum := sha256.Sum256([]byte(purpose + ":" + target + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
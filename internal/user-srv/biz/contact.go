@@ -0,0 +1,158 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type ContactVerificationService interface {
+	// SendCode 向邮箱或手机号发送验证码，账号不存在或已验证时同样返回成功
+	SendCode(ctx context.Context, target string) error
+	// Verify 校验验证码，并把对应的邮箱或手机号标记为已验证
+	Verify(ctx context.Context, target, code string) (*User, error)
+}
+
+type contactVerificationUsecase struct {
+	repo      UserRepo
+	codes     VerificationCodeService
+	notifier  Notifier
+	validator UserValidator
+	now       func() time.Time
+}
+
+func NewContactVerificationUsecase(repo UserRepo, codes VerificationCodeService, notifier Notifier,
+	validator UserValidator) ContactVerificationService {
+	return &contactVerificationUsecase{
+		repo:      repo,
+		codes:     codes,
+		notifier:  notifier,
+		validator: validator,
+		now:       time.Now,
+	}
+}
+
+// SendCode sends a verification code to the email or phone of a user.
+func (uc *contactVerificationUsecase) SendCode(ctx context.Context, target string) error {
+	target, err := normalizeContact(uc.validator, target)
+	if err != nil {
+		return err
+	}
+	purpose := contactPurpose(target)
+
+	// 1. 限制发送频率
+	if err := uc.codes.CheckSendRate(ctx, purpose, target); err != nil {
+		return err
+	}
+
+	// 2. 查找账号，不存在或已验证时静默返回
+	user, channel, err := findByContact(ctx, uc.repo, target)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if contactVerifiedAt(user, channel) != nil {
+		return nil
+	}
+
+	// 3. 生成并发送验证码
+	code, err := uc.codes.Issue(ctx, purpose, target, user.ID)
+	if err != nil {
+		return err
+	}
+	return uc.notifier.Notify(ctx, &Notification{
+		Channel: channel,
+		To:      target,
+		Subject: "验证联系方式",
+		Body:    fmt.Sprintf("您的验证码为 %s，请勿泄露给他人。", code),
+	})
+}
+
+// Verify checks the code and marks the email or phone as verified.
+func (uc *contactVerificationUsecase) Verify(ctx context.Context, target, code string) (*User, error) {
+	target, err := normalizeContact(uc.validator, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 校验并消费验证码
+	vc, err := uc.codes.Verify(ctx, contactPurpose(target), target, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 发送验证码之后用户可能已经修改了联系方式，此时验证码作废
+	user, err := uc.repo.FindByID(ctx, vc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+	var field string
+	switch {
+	case contactChannel(target) == ChannelEmail && normalizeEmail(user.Email) == target:
+		user.EmailVerifiedAt = &now
+		field = "EmailVerifiedAt"
+	case contactChannel(target) == ChannelSMS && user.Phone == target:
+		user.PhoneVerifiedAt = &now
+		field = "PhoneVerifiedAt"
+	default:
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+
+	// 3. 保存验证状态
+	if err := uc.repo.Update(ctx, user, field); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// findByContact 按邮箱或手机号查找用户，并返回对应的通知渠道
+func findByContact(ctx context.Context, repo UserRepo, contact string) (*User, string, error) {
+	if contactChannel(contact) == ChannelEmail {
+		user, err := repo.FindByEmail(ctx, contact)
+		return user, ChannelEmail, err
+	}
+	user, err := repo.FindByPhone(ctx, contact)
+	return user, ChannelSMS, err
+}
+
+// normalizeContact 手机号转换为与保存时一致的 E.164 格式，邮箱去掉首尾空白并转为小写，
+// 与 email_key 的规则一致，大小写不同的邮箱使用同一个验证码和发送频率限制
+func normalizeContact(validator UserValidator, contact string) (string, error) {
+	if contactChannel(contact) == ChannelEmail {
+		return normalizeEmail(contact), nil
+	}
+	return validator.NormalizePhone(contact, "")
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// contactChannel 根据联系方式的格式判断是邮箱还是手机号
+func contactChannel(contact string) string {
+	if strings.Contains(contact, "@") {
+		return ChannelEmail
+	}
+	return ChannelSMS
+}
+
+func contactPurpose(contact string) string {
+	if contactChannel(contact) == ChannelEmail {
+		return CodePurposeVerifyEmail
+	}
+	return CodePurposeVerifyPhone
+}
+
+func contactVerifiedAt(user *User, channel string) *time.Time {
+	if channel == ChannelEmail {
+		return user.EmailVerifiedAt
+	}
+	return user.PhoneVerifiedAt
+}
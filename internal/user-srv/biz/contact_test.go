@@ -0,0 +1,137 @@
+package biz_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+func newTestContactUsecase(t *testing.T) (biz.ContactVerificationService, *mock.MockUserRepo, *fakeNotifier) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctrl)
+	validator := mock.NewMockUserValidator(ctrl)
+	stubNormalizePhone(validator)
+	notifier := &fakeNotifier{}
+	codes := biz.NewVerificationCodeUsecase(data.NewMemoryVerificationCodeRepo(),
+		&conf.Verification{CodeExpireDuration: 600, MaxAttempts: 3, SendInterval: 60})
+	return biz.NewContactVerificationUsecase(repo, codes, notifier, validator), repo, notifier
+}
+
+// stubNormalizePhone 没有国家码的手机号按 +86 转换为 E.164 格式
+func stubNormalizePhone(validator *mock.MockUserValidator) {
+	validator.EXPECT().NormalizePhone(gomock.Any(), "").DoAndReturn(func(phone, region string) (string, error) {
+		if strings.HasPrefix(phone, "+") {
+			return phone, nil
+		}
+		return "+86" + phone, nil
+	}).AnyTimes()
+}
+
+// 验证邮箱
+func TestContactVerification_Email(t *testing.T) {
+	uc, repo, notifier := newTestContactUsecase(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Email: "test@example.com"}
+	repo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+
+	require.NoError(t, uc.SendCode(ctx, user.Email))
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, biz.ChannelEmail, notifier.sent[0].Channel)
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+	repo.EXPECT().Update(gomock.Any(), user, "EmailVerifiedAt").Return(nil)
+	got, err := uc.Verify(ctx, user.Email, notifier.lastCode(t))
+	require.NoError(t, err)
+	assert.NotNil(t, got.EmailVerifiedAt)
+	assert.Nil(t, got.PhoneVerifiedAt)
+}
+
+// 邮箱不区分大小写，发送时和保存的邮箱大小写不同也可以验证
+func TestContactVerification_EmailCase(t *testing.T) {
+	uc, repo, notifier := newTestContactUsecase(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Email: "Foo@example.com"}
+	repo.EXPECT().FindByEmail(gomock.Any(), "foo@example.com").Return(user, nil)
+
+	require.NoError(t, uc.SendCode(ctx, " Foo@Example.com"))
+	require.Len(t, notifier.sent, 1)
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+	repo.EXPECT().Update(gomock.Any(), user, "EmailVerifiedAt").Return(nil)
+	got, err := uc.Verify(ctx, "FOO@example.com", notifier.lastCode(t))
+	require.NoError(t, err)
+	assert.NotNil(t, got.EmailVerifiedAt)
+}
+
+// 账号不存在或已验证时不发送验证码
+func TestContactVerification_Silent(t *testing.T) {
+	uc, repo, notifier := newTestContactUsecase(t)
+	ctx := context.Background()
+	verifiedAt := time.Now()
+
+	repo.EXPECT().FindByPhone(gomock.Any(), "+8613800138000").Return(nil, apperrors.ErrUserNotFound)
+	assert.NoError(t, uc.SendCode(ctx, "13800138000"))
+
+	repo.EXPECT().FindByPhone(gomock.Any(), "+8613900139000").
+		Return(&biz.User{ID: 1, Phone: "+8613900139000", PhoneVerifiedAt: &verifiedAt}, nil)
+	assert.NoError(t, uc.SendCode(ctx, "+8613900139000"))
+
+	assert.Empty(t, notifier.sent)
+}
+
+// 冷却期内不能重复发送，账号不存在时同样限制
+func TestContactVerification_RateLimit(t *testing.T) {
+	uc, repo, _ := newTestContactUsecase(t)
+	ctx := context.Background()
+	repo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrUserNotFound).Times(2)
+
+	assert.NoError(t, uc.SendCode(ctx, "a@example.com"))
+	assert.Equal(t, apperrors.ErrVerificationCodeTooFrequent, uc.SendCode(ctx, "a@example.com"))
+	// 修改大小写不能绕过冷却期
+	assert.Equal(t, apperrors.ErrVerificationCodeTooFrequent, uc.SendCode(ctx, "A@Example.com"))
+	// 不同的目标互不影响
+	assert.NoError(t, uc.SendCode(ctx, "b@example.com"))
+}
+
+// 发送验证码后修改了手机号，旧手机号的验证码作废
+func TestContactVerification_ContactChanged(t *testing.T) {
+	uc, repo, notifier := newTestContactUsecase(t)
+	ctx := context.Background()
+	repo.EXPECT().FindByPhone(gomock.Any(), "+8613800138000").Return(&biz.User{ID: 1, Phone: "+8613800138000"}, nil)
+	require.NoError(t, uc.SendCode(ctx, "13800138000"))
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Phone: "+8613900139000"}, nil)
+	_, err := uc.Verify(ctx, "13800138000", notifier.lastCode(t))
+	assert.Equal(t, apperrors.ErrVerificationCodeInvalid, err)
+}
+
+// 本地格式的手机号转换为 E.164 格式后发送和验证
+func TestContactVerification_Phone(t *testing.T) {
+	uc, repo, notifier := newTestContactUsecase(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Phone: "+8613800138000"}
+	repo.EXPECT().FindByPhone(gomock.Any(), user.Phone).Return(user, nil)
+
+	require.NoError(t, uc.SendCode(ctx, "13800138000"))
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, biz.ChannelSMS, notifier.sent[0].Channel)
+	assert.Equal(t, user.Phone, notifier.sent[0].To)
+
+	// 验证时使用 E.164 格式同样可以匹配
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+	repo.EXPECT().Update(gomock.Any(), user, "PhoneVerifiedAt").Return(nil)
+	got, err := uc.Verify(ctx, user.Phone, notifier.lastCode(t))
+	require.NoError(t, err)
+	assert.NotNil(t, got.PhoneVerifiedAt)
+}
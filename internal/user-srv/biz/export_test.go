@@ -0,0 +1,8 @@
+package biz
+
+import "time"
+
+// SetTwoFactorClock 替换两步验证使用的时钟，只用于测试
+func SetTwoFactorClock(s TwoFactorService, now func() time.Time) {
+	s.(*twoFactorUsecase).now = now
+}
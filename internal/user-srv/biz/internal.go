@@ -0,0 +1,63 @@
+package biz
+
+import (
+	"context"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// InternalUserService 供订单、评价等内部服务调用的用户查询
+type InternalUserService interface {
+	// BatchGetUsers 按 ID 批量查询用户，返回按 ID 索引的用户，以及不存在或已删除的用户 ID
+	BatchGetUsers(ctx context.Context, ids []uint) (map[uint]*User, []uint, error)
+}
+
+type internalUserUsecase struct {
+	repo         UserRepo
+	maxBatchSize int
+}
+
+func NewInternalUserUsecase(repo UserRepo, c *conf.Internal) InternalUserService {
+	return &internalUserUsecase{
+		repo:         repo,
+		maxBatchSize: c.MaxBatchSize,
+	}
+}
+
+func (uc *internalUserUsecase) BatchGetUsers(ctx context.Context, ids []uint) (map[uint]*User, []uint, error) {
+	// 1. 限制数量，重复的 ID 只查询一次
+	if len(ids) > uc.maxBatchSize {
+		return nil, nil, apperrors.ErrInvalidArgument.WithMessageKey("batch_too_large", "一次最多查询 %d 个用户", uc.maxBatchSize)
+	}
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return map[uint]*User{}, nil, nil
+	}
+
+	// 2. 一次查询所有用户
+	users, err := uc.repo.FindByIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := make(map[uint]*User, len(users))
+	for _, user := range users {
+		found[user.ID] = user
+	}
+
+	// 3. 按请求中的顺序返回没有查到的 ID
+	var missing []uint
+	for _, id := range unique {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
@@ -0,0 +1,49 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+func newTestInternalUsecase(t *testing.T) (biz.InternalUserService, *mock.MockUserRepo) {
+	repo := mock.NewMockUserRepo(gomock.NewController(t))
+	return biz.NewInternalUserUsecase(repo, &conf.Internal{MaxBatchSize: 4}), repo
+}
+
+func TestInternalUserUsecase_BatchGetUsers(t *testing.T) {
+	uc, repo := newTestInternalUsecase(t)
+
+	// 重复的 ID 只查询一次，不存在或已删除的用户不会被查到
+	repo.EXPECT().FindByIDs(gomock.Any(), []uint{3, 1, 2}).
+		Return([]*biz.User{{ID: 1, UserName: "alice"}, {ID: 3, UserName: "carol"}}, nil)
+
+	users, missing, err := uc.BatchGetUsers(context.Background(), []uint{3, 1, 3, 2})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "alice", users[1].UserName)
+	assert.Equal(t, "carol", users[3].UserName)
+	assert.Equal(t, []uint{2}, missing)
+}
+
+func TestInternalUserUsecase_BatchGetUsersLimit(t *testing.T) {
+	uc, _ := newTestInternalUsecase(t)
+
+	// 超过数量限制时不查询
+	_, _, err := uc.BatchGetUsers(context.Background(), []uint{1, 2, 3, 4, 5})
+	assert.Equal(t, apperrors.ErrInvalidArgument.WithMessageKey("batch_too_large", "一次最多查询 %d 个用户", 4), err)
+
+	// 没有 ID 时直接返回
+	users, missing, err := uc.BatchGetUsers(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,147 @@
+package biz
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// LoginAttemptRepo 保存登录失败次数和锁定状态
+type LoginAttemptRepo interface {
+	// IncrFailures 记录一次失败，返回 window 内的累计失败次数，每次失败都会重新计时
+	IncrFailures(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock 锁定 d 时长
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// LockedFor 返回剩余的锁定时长，未锁定时返回 0
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Reset 清除失败次数和锁定状态
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginLimiter 按账号和客户端 IP 限制登录失败次数
+type LoginLimiter interface {
+	// CheckIP IP 处于锁定期时返回 ErrAccountLocked
+	CheckIP(ctx context.Context, ip string) error
+	// CheckAccount 账号处于锁定期时返回 ErrAccountLocked
+	CheckAccount(ctx context.Context, userID uint) error
+	// Fail 记录一次失败，userID 为 0 表示账号不存在，ip 为空表示未知来源
+	Fail(ctx context.Context, userID uint, ip string) error
+	// Succeed 登录成功后清除账号的失败记录，IP 的记录不清除，避免攻击者用自己的账号重置计数
+	Succeed(ctx context.Context, userID uint) error
+}
+
+// lockoutPolicy 达到 threshold 次失败后锁定，之后每多失败一次锁定时长翻倍，最长 max
+type lockoutPolicy struct {
+	threshold int
+	base      time.Duration
+	max       time.Duration
+}
+
+func (p lockoutPolicy) lockDuration(failures int) time.Duration {
+	if p.threshold <= 0 || failures < p.threshold {
+		return 0
+	}
+	d := p.base
+	for i := p.threshold; i < failures; i++ {
+		if (p.max > 0 && d >= p.max) || d >= math.MaxInt64/2 {
+			break
+		}
+		d *= 2
+	}
+	if p.max > 0 && d > p.max {
+		d = p.max
+	}
+	return d
+}
+
+type loginLimiter struct {
+	repo    LoginAttemptRepo
+	window  time.Duration
+	account lockoutPolicy
+	ip      lockoutPolicy
+}
+
+func NewLoginLimiter(repo LoginAttemptRepo, c *conf.Auth) LoginLimiter {
+	base := time.Second * time.Duration(c.LockoutDuration)
+	maxLock := time.Second * time.Duration(c.MaxLockoutDuration)
+	return &loginLimiter{
+		repo:    repo,
+		window:  time.Second * time.Duration(c.LoginFailureWindow),
+		account: lockoutPolicy{threshold: c.MaxLoginFailures, base: base, max: maxLock},
+		ip:      lockoutPolicy{threshold: c.MaxIPLoginFailures, base: base, max: maxLock},
+	}
+}
+
+func accountAttemptKey(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (l *loginLimiter) CheckIP(ctx context.Context, ip string) error {
+	if ip == "" || l.ip.threshold <= 0 {
+		return nil
+	}
+	return l.check(ctx, ipAttemptKey(ip))
+}
+
+func (l *loginLimiter) CheckAccount(ctx context.Context, userID uint) error {
+	if l.account.threshold <= 0 {
+		return nil
+	}
+	return l.check(ctx, accountAttemptKey(userID))
+}
+
+func (l *loginLimiter) check(ctx context.Context, key string) error {
+	d, err := l.repo.LockedFor(ctx, key)
+	if err != nil {
+		return err
+	}
+	if d > 0 {
+		return lockedError(d)
+	}
+	return nil
+}
+
+func (l *loginLimiter) Fail(ctx context.Context, userID uint, ip string) error {
+	if userID != 0 && l.account.threshold > 0 {
+		if err := l.fail(ctx, accountAttemptKey(userID), l.account); err != nil {
+			return err
+		}
+	}
+	if ip != "" && l.ip.threshold > 0 {
+		if err := l.fail(ctx, ipAttemptKey(ip), l.ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loginLimiter) fail(ctx context.Context, key string, policy lockoutPolicy) error {
+	failures, err := l.repo.IncrFailures(ctx, key, l.window)
+	if err != nil {
+		return err
+	}
+	if d := policy.lockDuration(failures); d > 0 {
+		return l.repo.Lock(ctx, key, d)
+	}
+	return nil
+}
+
+func (l *loginLimiter) Succeed(ctx context.Context, userID uint) error {
+	if l.account.threshold <= 0 {
+		return nil
+	}
+	return l.repo.Reset(ctx, accountAttemptKey(userID))
+}
+
+func lockedError(d time.Duration) error {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	return apperrors.ErrAccountLocked.WithMessageKey("retry_after", "登录失败次数过多，请 %d 秒后重试", seconds)
+}
@@ -0,0 +1,132 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+)
+
+var lockoutConf = &conf.Auth{
+	MaxLoginFailures:   3,
+	MaxIPLoginFailures: 5,
+	LoginFailureWindow: 900,
+	LockoutDuration:    60,
+	MaxLockoutDuration: 120,
+}
+
+// 账号连续失败达到阈值后锁定，锁定时长指数增长且不超过上限
+func TestLoginLimiter_AccountLockout(t *testing.T) {
+	repo := data.NewMemoryLoginAttemptRepo()
+	limiter := biz.NewLoginLimiter(repo, lockoutConf)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, 1, ""))
+		require.NoError(t, limiter.CheckAccount(ctx, 1))
+	}
+
+	require.NoError(t, limiter.Fail(ctx, 1, ""))
+	err := limiter.CheckAccount(ctx, 1)
+	assert.ErrorIs(t, err, apperrors.ErrAccountLocked)
+	locked, err := repo.LockedFor(ctx, "user:1")
+	require.NoError(t, err)
+	assert.InDelta(t, 60, locked.Seconds(), 1)
+
+	// 第 4 次失败锁定时长翻倍，第 5 次达到上限
+	require.NoError(t, limiter.Fail(ctx, 1, ""))
+	locked, _ = repo.LockedFor(ctx, "user:1")
+	assert.InDelta(t, 120, locked.Seconds(), 1)
+	require.NoError(t, limiter.Fail(ctx, 1, ""))
+	locked, _ = repo.LockedFor(ctx, "user:1")
+	assert.InDelta(t, 120, locked.Seconds(), 1)
+
+	// 其他账号不受影响
+	assert.NoError(t, limiter.CheckAccount(ctx, 2))
+
+	// 登录成功后清除
+	require.NoError(t, limiter.Succeed(ctx, 1))
+	assert.NoError(t, limiter.CheckAccount(ctx, 1))
+}
+
+// 同一 IP 对不同账号的失败累计计数
+func TestLoginLimiter_IPLockout(t *testing.T) {
+	limiter := biz.NewLoginLimiter(data.NewMemoryLoginAttemptRepo(), lockoutConf)
+	ctx := context.Background()
+
+	for i := uint(1); i <= 5; i++ {
+		require.NoError(t, limiter.CheckIP(ctx, "10.0.0.1"))
+		require.NoError(t, limiter.Fail(ctx, i, "10.0.0.1"))
+	}
+	assert.ErrorIs(t, limiter.CheckIP(ctx, "10.0.0.1"), apperrors.ErrAccountLocked)
+	assert.NoError(t, limiter.CheckIP(ctx, "10.0.0.2"))
+
+	// 账号登录成功不会清除 IP 的计数
+	require.NoError(t, limiter.Succeed(ctx, 1))
+	assert.ErrorIs(t, limiter.CheckIP(ctx, "10.0.0.1"), apperrors.ErrAccountLocked)
+}
+
+// 账号锁定后即使密码正确也不能登录，并且不再校验密码
+func TestUserUsecase_LoginLocked(t *testing.T) {
+	ctl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	limiter := biz.NewLoginLimiter(data.NewMemoryLoginAttemptRepo(), lockoutConf)
+	uc := biz.NewUserUsecase(repo, mock.NewMockProfileChangeRepo(ctl), mock.NewMockPasswordHistoryRepo(ctl),
+		validate, passwordHash, limiter, newTestAudit(), lockoutConf)
+	ctx := clientip.ToContext(context.Background(), "10.0.0.1")
+
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
+	repo.EXPECT().FindByUsername(gomock.Any(), "testuser").Return(&biz.User{
+		ID: 1, UserName: "testuser", Password: "hashed_password",
+	}, nil).AnyTimes()
+	passwordHash.EXPECT().Virefy("wrong", "hashed_password").Return(false).Times(3)
+
+	for i := 0; i < 3; i++ {
+		_, err := uc.Login(ctx, "testuser", "wrong")
+		assert.Equal(t, apperrors.ErrPasswordIncorrect, err)
+	}
+	_, err := uc.Login(ctx, "testuser", "pAssword123")
+	assert.ErrorIs(t, err, apperrors.ErrAccountLocked)
+}
+
+// 密码正确不会清除失败记录，登录完成（通过两步验证）后才清除
+func TestUserUsecase_LoginSucceedAfterComplete(t *testing.T) {
+	ctl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	attempts := data.NewMemoryLoginAttemptRepo()
+	limiter := biz.NewLoginLimiter(attempts, lockoutConf)
+	uc := biz.NewUserUsecase(repo, mock.NewMockProfileChangeRepo(ctl), mock.NewMockPasswordHistoryRepo(ctl),
+		validate, passwordHash, limiter, newTestAudit(), lockoutConf)
+	ctx := context.Background()
+
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
+	repo.EXPECT().FindByUsername(gomock.Any(), "testuser").DoAndReturn(func(ctx context.Context, username string) (*biz.User, error) {
+		return &biz.User{ID: 1, UserName: "testuser", Password: "hashed_password"}, nil
+	}).AnyTimes()
+	passwordHash.EXPECT().Virefy("pAssword123", "hashed_password").Return(true).AnyTimes()
+	passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false).AnyTimes()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, 1, ""))
+		_, err := uc.Login(ctx, "testuser", "pAssword123")
+		require.NoError(t, err)
+	}
+	require.NoError(t, limiter.Fail(ctx, 1, ""))
+	assert.ErrorIs(t, limiter.CheckAccount(ctx, 1), apperrors.ErrAccountLocked)
+
+	require.NoError(t, uc.CompleteLogin(ctx, 1))
+	assert.NoError(t, limiter.CheckAccount(ctx, 1))
+}
@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/user-srv/biz/code.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+)
+
+// MockVerificationCodeRepo is a mock of VerificationCodeRepo interface.
+type MockVerificationCodeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerificationCodeRepoMockRecorder
+}
+
+// MockVerificationCodeRepoMockRecorder is the mock recorder for MockVerificationCodeRepo.
+type MockVerificationCodeRepoMockRecorder struct {
+	mock *MockVerificationCodeRepo
+}
+
+// NewMockVerificationCodeRepo creates a new mock instance.
+func NewMockVerificationCodeRepo(ctrl *gomock.Controller) *MockVerificationCodeRepo {
+	mock := &MockVerificationCodeRepo{ctrl: ctrl}
+	mock.recorder = &MockVerificationCodeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerificationCodeRepo) EXPECT() *MockVerificationCodeRepoMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockVerificationCodeRepo) Delete(ctx context.Context, purpose, target string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, purpose, target)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockVerificationCodeRepoMockRecorder) Delete(ctx, purpose, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockVerificationCodeRepo)(nil).Delete), ctx, purpose, target)
+}
+
+// Get mocks base method.
+func (m *MockVerificationCodeRepo) Get(ctx context.Context, purpose, target string) (*biz.VerificationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, purpose, target)
+	ret0, _ := ret[0].(*biz.VerificationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockVerificationCodeRepoMockRecorder) Get(ctx, purpose, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockVerificationCodeRepo)(nil).Get), ctx, purpose, target)
+}
+
+// IncrAttempts mocks base method.
+func (m *MockVerificationCodeRepo) IncrAttempts(ctx context.Context, purpose, target string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrAttempts", ctx, purpose, target)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrAttempts indicates an expected call of IncrAttempts.
+func (mr *MockVerificationCodeRepoMockRecorder) IncrAttempts(ctx, purpose, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrAttempts", reflect.TypeOf((*MockVerificationCodeRepo)(nil).IncrAttempts), ctx, purpose, target)
+}
+
+// Save mocks base method.
+func (m *MockVerificationCodeRepo) Save(ctx context.Context, code *biz.VerificationCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockVerificationCodeRepoMockRecorder) Save(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockVerificationCodeRepo)(nil).Save), ctx, code)
+}
+
+// SetCooldown mocks base method.
+func (m *MockVerificationCodeRepo) SetCooldown(ctx context.Context, purpose, target string, d time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCooldown", ctx, purpose, target, d)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCooldown indicates an expected call of SetCooldown.
+func (mr *MockVerificationCodeRepoMockRecorder) SetCooldown(ctx, purpose, target, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCooldown", reflect.TypeOf((*MockVerificationCodeRepo)(nil).SetCooldown), ctx, purpose, target, d)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockNotifier) Notify(ctx context.Context, n *biz.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotifierMockRecorder) Notify(ctx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), ctx, n)
+}
+
+// MockVerificationCodeService is a mock of VerificationCodeService interface.
+type MockVerificationCodeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerificationCodeServiceMockRecorder
+}
+
+// MockVerificationCodeServiceMockRecorder is the mock recorder for MockVerificationCodeService.
+type MockVerificationCodeServiceMockRecorder struct {
+	mock *MockVerificationCodeService
+}
+
+// NewMockVerificationCodeService creates a new mock instance.
+func NewMockVerificationCodeService(ctrl *gomock.Controller) *MockVerificationCodeService {
+	mock := &MockVerificationCodeService{ctrl: ctrl}
+	mock.recorder = &MockVerificationCodeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerificationCodeService) EXPECT() *MockVerificationCodeServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckSendRate mocks base method.
+func (m *MockVerificationCodeService) CheckSendRate(ctx context.Context, purpose, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSendRate", ctx, purpose, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSendRate indicates an expected call of CheckSendRate.
+func (mr *MockVerificationCodeServiceMockRecorder) CheckSendRate(ctx, purpose, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSendRate", reflect.TypeOf((*MockVerificationCodeService)(nil).CheckSendRate), ctx, purpose, target)
+}
+
+// Issue mocks base method.
+func (m *MockVerificationCodeService) Issue(ctx context.Context, purpose, target string, userID uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, purpose, target, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockVerificationCodeServiceMockRecorder) Issue(ctx, purpose, target, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockVerificationCodeService)(nil).Issue), ctx, purpose, target, userID)
+}
+
+// Verify mocks base method.
+func (m *MockVerificationCodeService) Verify(ctx context.Context, purpose, target, code string) (*biz.VerificationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, purpose, target, code)
+	ret0, _ := ret[0].(*biz.VerificationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockVerificationCodeServiceMockRecorder) Verify(ctx, purpose, target, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockVerificationCodeService)(nil).Verify), ctx, purpose, target, code)
+}
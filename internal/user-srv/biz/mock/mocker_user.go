@@ -50,6 +50,35 @@ func (mr *MockUserRepoMockRecorder) Create(ctx, user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepo)(nil).Create), ctx, user)
 }
 
+// Delete mocks base method.
+func (m *MockUserRepo) Delete(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserRepoMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepo)(nil).Delete), ctx, id)
+}
+
+// FindByEmail mocks base method.
+func (m *MockUserRepo) FindByEmail(ctx context.Context, email string) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, email)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockUserRepoMockRecorder) FindByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserRepo)(nil).FindByEmail), ctx, email)
+}
+
 // FindByID mocks base method.
 func (m *MockUserRepo) FindByID(ctx context.Context, id uint) (*biz.User, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +94,66 @@ func (mr *MockUserRepoMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockUserRepo)(nil).FindByID), ctx, id)
 }
 
+// FindByIDUncached mocks base method.
+func (m *MockUserRepo) FindByIDUncached(ctx context.Context, id uint) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDUncached", ctx, id)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDUncached indicates an expected call of FindByIDUncached.
+func (mr *MockUserRepoMockRecorder) FindByIDUncached(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDUncached", reflect.TypeOf((*MockUserRepo)(nil).FindByIDUncached), ctx, id)
+}
+
+// FindByIDWithDeleted mocks base method.
+func (m *MockUserRepo) FindByIDWithDeleted(ctx context.Context, id uint) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDWithDeleted", ctx, id)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDWithDeleted indicates an expected call of FindByIDWithDeleted.
+func (mr *MockUserRepoMockRecorder) FindByIDWithDeleted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDWithDeleted", reflect.TypeOf((*MockUserRepo)(nil).FindByIDWithDeleted), ctx, id)
+}
+
+// FindByIDs mocks base method.
+func (m *MockUserRepo) FindByIDs(ctx context.Context, ids []uint) ([]*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockUserRepoMockRecorder) FindByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockUserRepo)(nil).FindByIDs), ctx, ids)
+}
+
+// FindByPhone mocks base method.
+func (m *MockUserRepo) FindByPhone(ctx context.Context, phone string) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPhone", ctx, phone)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPhone indicates an expected call of FindByPhone.
+func (mr *MockUserRepoMockRecorder) FindByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserRepo)(nil).FindByPhone), ctx, phone)
+}
+
 // FindByUsername mocks base method.
 func (m *MockUserRepo) FindByUsername(ctx context.Context, username string) (*biz.User, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +169,159 @@ func (mr *MockUserRepoMockRecorder) FindByUsername(ctx, username interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsername", reflect.TypeOf((*MockUserRepo)(nil).FindByUsername), ctx, username)
 }
 
+// List mocks base method.
+func (m *MockUserRepo) List(ctx context.Context, filter *biz.UserFilter) ([]*biz.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]*biz.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockUserRepoMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepo)(nil).List), ctx, filter)
+}
+
+// Restore mocks base method.
+func (m *MockUserRepo) Restore(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUserRepoMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserRepo)(nil).Restore), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockUserRepo) Update(ctx context.Context, user *biz.User, fields ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, user}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Update", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserRepoMockRecorder) Update(ctx, user interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, user}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepo)(nil).Update), varargs...)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepo) UpdatePassword(ctx context.Context, id uint, password string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepoMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepo)(nil).UpdatePassword), ctx, id, password)
+}
+
+// MockProfileChangeRepo is a mock of ProfileChangeRepo interface.
+type MockProfileChangeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileChangeRepoMockRecorder
+}
+
+// MockProfileChangeRepoMockRecorder is the mock recorder for MockProfileChangeRepo.
+type MockProfileChangeRepoMockRecorder struct {
+	mock *MockProfileChangeRepo
+}
+
+// NewMockProfileChangeRepo creates a new mock instance.
+func NewMockProfileChangeRepo(ctrl *gomock.Controller) *MockProfileChangeRepo {
+	mock := &MockProfileChangeRepo{ctrl: ctrl}
+	mock.recorder = &MockProfileChangeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileChangeRepo) EXPECT() *MockProfileChangeRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProfileChangeRepo) Create(ctx context.Context, changes []*biz.ProfileChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, changes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProfileChangeRepoMockRecorder) Create(ctx, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProfileChangeRepo)(nil).Create), ctx, changes)
+}
+
+// MockPasswordHistoryRepo is a mock of PasswordHistoryRepo interface.
+type MockPasswordHistoryRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHistoryRepoMockRecorder
+}
+
+// MockPasswordHistoryRepoMockRecorder is the mock recorder for MockPasswordHistoryRepo.
+type MockPasswordHistoryRepoMockRecorder struct {
+	mock *MockPasswordHistoryRepo
+}
+
+// NewMockPasswordHistoryRepo creates a new mock instance.
+func NewMockPasswordHistoryRepo(ctrl *gomock.Controller) *MockPasswordHistoryRepo {
+	mock := &MockPasswordHistoryRepo{ctrl: ctrl}
+	mock.recorder = &MockPasswordHistoryRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHistoryRepo) EXPECT() *MockPasswordHistoryRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPasswordHistoryRepo) Create(ctx context.Context, userID uint, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPasswordHistoryRepoMockRecorder) Create(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasswordHistoryRepo)(nil).Create), ctx, userID, passwordHash)
+}
+
+// ListRecent mocks base method.
+func (m *MockPasswordHistoryRepo) ListRecent(ctx context.Context, userID uint, n int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecent", ctx, userID, n)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecent indicates an expected call of ListRecent.
+func (mr *MockPasswordHistoryRepoMockRecorder) ListRecent(ctx, userID, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecent", reflect.TypeOf((*MockPasswordHistoryRepo)(nil).ListRecent), ctx, userID, n)
+}
+
 // MockUserService is a mock of UserService interface.
 type MockUserService struct {
 	ctrl     *gomock.Controller
@@ -103,6 +345,49 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserService) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, oldPassword, newPassword)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserServiceMockRecorder) ChangePassword(ctx, userID, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserService)(nil).ChangePassword), ctx, userID, oldPassword, newPassword)
+}
+
+// CheckUserStatus mocks base method.
+func (m *MockUserService) CheckUserStatus(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUserStatus", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckUserStatus indicates an expected call of CheckUserStatus.
+func (mr *MockUserServiceMockRecorder) CheckUserStatus(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserStatus", reflect.TypeOf((*MockUserService)(nil).CheckUserStatus), ctx, userID)
+}
+
+// CompleteLogin mocks base method.
+func (m *MockUserService) CompleteLogin(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockUserServiceMockRecorder) CompleteLogin(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockUserService)(nil).CompleteLogin), ctx, userID)
+}
+
 // GetMyProfile mocks base method.
 func (m *MockUserService) GetMyProfile(ctx context.Context, userID uint) (*biz.User, error) {
 	m.ctrl.T.Helper()
@@ -118,19 +403,34 @@ func (mr *MockUserServiceMockRecorder) GetMyProfile(ctx, userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMyProfile", reflect.TypeOf((*MockUserService)(nil).GetMyProfile), ctx, userID)
 }
 
+// GetTokenVersion mocks base method.
+func (m *MockUserService) GetTokenVersion(ctx context.Context, userID uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenVersion", ctx, userID)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenVersion indicates an expected call of GetTokenVersion.
+func (mr *MockUserServiceMockRecorder) GetTokenVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenVersion", reflect.TypeOf((*MockUserService)(nil).GetTokenVersion), ctx, userID)
+}
+
 // Login mocks base method.
-func (m *MockUserService) Login(ctx context.Context, username, password string) (*biz.User, error) {
+func (m *MockUserService) Login(ctx context.Context, identifier, password string) (*biz.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, username, password)
+	ret := m.ctrl.Call(m, "Login", ctx, identifier, password)
 	ret0, _ := ret[0].(*biz.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserServiceMockRecorder) Login(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Login(ctx, identifier, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, identifier, password)
 }
 
 // RegisterUser mocks base method.
@@ -148,6 +448,36 @@ func (mr *MockUserServiceMockRecorder) RegisterUser(ctx, user interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUserService)(nil).RegisterUser), ctx, user)
 }
 
+// ResetPassword mocks base method.
+func (m *MockUserService) ResetPassword(ctx context.Context, userID uint, newPassword string) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, userID, newPassword)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServiceMockRecorder) ResetPassword(ctx, userID, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserService)(nil).ResetPassword), ctx, userID, newPassword)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserService) UpdateProfile(ctx context.Context, userID uint, update *biz.User, fields []string) (*biz.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, update, fields)
+	ret0, _ := ret[0].(*biz.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserServiceMockRecorder) UpdateProfile(ctx, userID, update, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserService)(nil).UpdateProfile), ctx, userID, update, fields)
+}
+
 // MockUserValidator is a mock of UserValidator interface.
 type MockUserValidator struct {
 	ctrl     *gomock.Controller
@@ -171,6 +501,36 @@ func (m *MockUserValidator) EXPECT() *MockUserValidatorMockRecorder {
 	return m.recorder
 }
 
+// DetectIdentifier mocks base method.
+func (m *MockUserValidator) DetectIdentifier(identifier string) (biz.IdentifierType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetectIdentifier", identifier)
+	ret0, _ := ret[0].(biz.IdentifierType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectIdentifier indicates an expected call of DetectIdentifier.
+func (mr *MockUserValidatorMockRecorder) DetectIdentifier(identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectIdentifier", reflect.TypeOf((*MockUserValidator)(nil).DetectIdentifier), identifier)
+}
+
+// NormalizePhone mocks base method.
+func (m *MockUserValidator) NormalizePhone(phone, region string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NormalizePhone", phone, region)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NormalizePhone indicates an expected call of NormalizePhone.
+func (mr *MockUserValidatorMockRecorder) NormalizePhone(phone, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NormalizePhone", reflect.TypeOf((*MockUserValidator)(nil).NormalizePhone), phone, region)
+}
+
 // Validate mocks base method.
 func (m *MockUserValidator) Validate(user *biz.User) error {
 	m.ctrl.T.Helper()
@@ -185,6 +545,25 @@ func (mr *MockUserValidatorMockRecorder) Validate(user interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockUserValidator)(nil).Validate), user)
 }
 
+// ValidatePartial mocks base method.
+func (m *MockUserValidator) ValidatePartial(user *biz.User, fields ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{user}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ValidatePartial", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidatePartial indicates an expected call of ValidatePartial.
+func (mr *MockUserValidatorMockRecorder) ValidatePartial(user interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{user}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePartial", reflect.TypeOf((*MockUserValidator)(nil).ValidatePartial), varargs...)
+}
+
 // MockPasswordHash is a mock of PasswordHash interface.
 type MockPasswordHash struct {
 	ctrl     *gomock.Controller
@@ -223,6 +602,20 @@ func (mr *MockPasswordHashMockRecorder) Hash(password interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHash)(nil).Hash), password)
 }
 
+// NeedsRehash mocks base method.
+func (m *MockPasswordHash) NeedsRehash(hashedPassword string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hashedPassword)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockPasswordHashMockRecorder) NeedsRehash(hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHash)(nil).NeedsRehash), hashedPassword)
+}
+
 // Virefy mocks base method.
 func (m *MockPasswordHash) Virefy(password, hashedPassword string) bool {
 	m.ctrl.T.Helper()
@@ -0,0 +1,160 @@
+package biz
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+// 支持的密码哈希算法
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+// argon2Params argon2id 的参数，保存在 PHC 字符串中：
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+// PasswordHasher 同时支持 argon2id 和 bcrypt，新密码使用配置的算法，
+// 验证时根据哈希字符串的前缀选择算法
+type PasswordHasher struct {
+	algorithm  string
+	argon2     argon2Params
+	bcryptCost int
+}
+
+func NewPasswordHasher(c *conf.Password) PasswordHash {
+	h := &PasswordHasher{
+		algorithm: c.Algorithm,
+		argon2: argon2Params{
+			memory:      c.Argon2Memory,
+			iterations:  c.Argon2Iterations,
+			parallelism: c.Argon2Parallelism,
+			saltLength:  c.Argon2SaltLength,
+			keyLength:   c.Argon2KeyLength,
+		},
+		bcryptCost: c.BcryptCost,
+	}
+	// 未配置的参数使用 RFC 9106 推荐的值
+	if h.algorithm == "" {
+		h.algorithm = PasswordAlgorithmArgon2id
+	}
+	if h.argon2.memory == 0 {
+		h.argon2.memory = 64 * 1024
+	}
+	if h.argon2.iterations == 0 {
+		h.argon2.iterations = 3
+	}
+	if h.argon2.parallelism == 0 {
+		h.argon2.parallelism = 4
+	}
+	if h.argon2.saltLength == 0 {
+		h.argon2.saltLength = 16
+	}
+	if h.argon2.keyLength == 0 {
+		h.argon2.keyLength = 32
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	return h
+}
+
+// NewBcrypt 只使用 bcrypt 的哈希器
+func NewBcrypt() PasswordHash {
+	return NewPasswordHasher(&conf.Password{Algorithm: PasswordAlgorithmBcrypt})
+}
+
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	switch h.algorithm {
+	case PasswordAlgorithmArgon2id:
+		salt := make([]byte, h.argon2.saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return encodeArgon2(h.argon2, salt, password), nil
+	case PasswordAlgorithmBcrypt:
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return string(hashedPassword), nil
+	default:
+		return "", fmt.Errorf("failed to hash password: unsupported algorithm %s", h.algorithm)
+	}
+}
+
+func (h *PasswordHasher) Virefy(password, hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		params, salt, key, err := decodeArgon2(hashedPassword)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, params.keyLength)
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return err == nil
+}
+
+// NeedsRehash 哈希使用的算法或参数与当前配置不一致
+func (h *PasswordHasher) NeedsRehash(hashedPassword string) bool {
+	switch h.algorithm {
+	case PasswordAlgorithmArgon2id:
+		params, _, _, err := decodeArgon2(hashedPassword)
+		if err != nil {
+			return true
+		}
+		return params != h.argon2
+	case PasswordAlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != h.bcryptCost
+	default:
+		return false
+	}
+}
+
+func encodeArgon2(p argon2Params, salt []byte, password string) string {
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version %s", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id params: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	p.saltLength, p.keyLength = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,74 @@
+package biz_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+// 测试中使用较小的 argon2 参数，加快运行速度
+func newTestPasswordConfig() *conf.Password {
+	return &conf.Password{
+		Algorithm:         biz.PasswordAlgorithmArgon2id,
+		Argon2Memory:      1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		BcryptCost:        4,
+	}
+}
+
+func TestPasswordHasher_Argon2id(t *testing.T) {
+	hasher := biz.NewPasswordHasher(newTestPasswordConfig())
+	hashed, err := hasher.Hash("pAssword123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	assert.True(t, hasher.Virefy("pAssword123", hashed))
+	assert.False(t, hasher.Virefy("pAssword124", hashed))
+	assert.False(t, hasher.NeedsRehash(hashed))
+
+	// 相同的密码每次使用不同的盐
+	other, err := hasher.Hash("pAssword123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, other)
+}
+
+// 修改算法或参数后旧的哈希仍然可以验证，但需要重新计算
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	c := newTestPasswordConfig()
+	c.Algorithm = biz.PasswordAlgorithmBcrypt
+	bcryptHasher := biz.NewPasswordHasher(c)
+	bcryptHash, err := bcryptHasher.Hash("pAssword123")
+	require.NoError(t, err)
+	assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+
+	argon2Hasher := biz.NewPasswordHasher(newTestPasswordConfig())
+	assert.True(t, argon2Hasher.Virefy("pAssword123", bcryptHash))
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+
+	argon2Hash, err := argon2Hasher.Hash("pAssword123")
+	require.NoError(t, err)
+	c = newTestPasswordConfig()
+	c.Argon2Iterations = 2
+	stronger := biz.NewPasswordHasher(c)
+	assert.True(t, stronger.Virefy("pAssword123", argon2Hash))
+	assert.True(t, stronger.NeedsRehash(argon2Hash))
+
+	// bcrypt cost 变化同样需要重新计算
+	c = newTestPasswordConfig()
+	c.Algorithm, c.BcryptCost = biz.PasswordAlgorithmBcrypt, 5
+	assert.True(t, biz.NewPasswordHasher(c).NeedsRehash(bcryptHash))
+}
+
+func TestPasswordHasher_InvalidHash(t *testing.T) {
+	hasher := biz.NewPasswordHasher(newTestPasswordConfig())
+	for _, hashed := range []string{"", "plain", "$argon2id$v=19$m=1024$c2FsdA$a2V5", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5"} {
+		assert.False(t, hasher.Virefy("pAssword123", hashed))
+		assert.True(t, hasher.NeedsRehash(hashed))
+	}
+}
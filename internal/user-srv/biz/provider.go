@@ -3,4 +3,18 @@ package biz
 import "github.com/google/wire"
 
 // ProviderSet is a provider set for non-test builds.
-var ProviderSet = wire.NewSet(NewUserUsecase, NewBcrypt)
+var ProviderSet = wire.NewSet(
+	NewUserUsecase,
+	NewPasswordHasher,
+	NewTokenUsecase,
+	NewVerificationCodeUsecase,
+	NewPasswordResetUsecase,
+	NewContactVerificationUsecase,
+	NewLoginLimiter,
+	NewAdminUserUsecase,
+	NewAuditUsecase,
+	NewSessionUsecase,
+	NewTwoFactorUsecase,
+	NewAvatarUsecase,
+	NewInternalUserUsecase,
+)
@@ -0,0 +1,92 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type PasswordResetService interface {
+	// RequestReset 向账号绑定的邮箱或手机发送重置验证码，账号不存在时同样返回成功
+	RequestReset(ctx context.Context, account string) error
+	// ConfirmReset 校验验证码并设置新密码
+	ConfirmReset(ctx context.Context, account, code, newPassword string) (*User, error)
+}
+
+type passwordResetUsecase struct {
+	repo      UserRepo
+	users     UserService
+	codes     VerificationCodeService
+	notifier  Notifier
+	validator UserValidator
+}
+
+func NewPasswordResetUsecase(repo UserRepo, users UserService, codes VerificationCodeService,
+	notifier Notifier, validator UserValidator) PasswordResetService {
+	return &passwordResetUsecase{
+		repo:      repo,
+		users:     users,
+		codes:     codes,
+		notifier:  notifier,
+		validator: validator,
+	}
+}
+
+// RequestReset sends a password reset code to the email or phone of the account.
+func (uc *passwordResetUsecase) RequestReset(ctx context.Context, account string) error {
+	account, err := normalizeContact(uc.validator, account)
+	if err != nil {
+		return err
+	}
+
+	// 1. 限制发送频率
+	if err := uc.codes.CheckSendRate(ctx, CodePurposePasswordReset, account); err != nil {
+		return err
+	}
+
+	// 2. 查找账号，不存在时静默返回，不暴露账号是否存在
+	user, channel, err := findByContact(ctx, uc.repo, account)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 3. 生成验证码
+	code, err := uc.codes.Issue(ctx, CodePurposePasswordReset, account, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// 4. 发送验证码
+	return uc.notifier.Notify(ctx, &Notification{
+		Channel: channel,
+		To:      account,
+		Subject: "重置密码",
+		Body:    fmt.Sprintf("您正在重置密码，验证码为 %s，请勿泄露给他人。", code),
+	})
+}
+
+// ConfirmReset verifies the reset code and sets the new password.
+func (uc *passwordResetUsecase) ConfirmReset(ctx context.Context, account, code, newPassword string) (*User, error) {
+	// 1. 先校验新密码格式，避免验证码被消费后才发现密码不合法
+	if err := uc.validator.ValidatePartial(&User{Password: newPassword}, "Password"); err != nil {
+		return nil, err
+	}
+
+	// 2. 校验并消费验证码
+	account, err := normalizeContact(uc.validator, account)
+	if err != nil {
+		return nil, err
+	}
+	vc, err := uc.codes.Verify(ctx, CodePurposePasswordReset, account, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 设置新密码
+	return uc.users.ResetPassword(ctx, vc.UserID, newPassword)
+}
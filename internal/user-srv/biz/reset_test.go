@@ -0,0 +1,182 @@
+package biz_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// fakeNotifier 记录发出的通知
+type fakeNotifier struct {
+	sent []*biz.Notification
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, msg *biz.Notification) error {
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+// lastCode 从最后一条通知中取出验证码
+func (n *fakeNotifier) lastCode(t *testing.T) string {
+	require.NotEmpty(t, n.sent)
+	code := codePattern.FindString(n.sent[len(n.sent)-1].Body)
+	require.NotEmpty(t, code)
+	return code
+}
+
+type resetFixture struct {
+	uc        biz.PasswordResetService
+	repo      *mock.MockUserRepo
+	users     *mock.MockUserService
+	validator *mock.MockUserValidator
+	notifier  *fakeNotifier
+}
+
+func newResetFixture(t *testing.T) *resetFixture {
+	ctrl := gomock.NewController(t)
+	f := &resetFixture{
+		repo:      mock.NewMockUserRepo(ctrl),
+		users:     mock.NewMockUserService(ctrl),
+		validator: mock.NewMockUserValidator(ctrl),
+		notifier:  &fakeNotifier{},
+	}
+	codes := biz.NewVerificationCodeUsecase(data.NewMemoryVerificationCodeRepo(),
+		&conf.Verification{CodeExpireDuration: 600, MaxAttempts: 3})
+	f.uc = biz.NewPasswordResetUsecase(f.repo, f.users, codes, f.notifier, f.validator)
+	f.validator.EXPECT().ValidatePartial(gomock.Any(), "Password").Return(nil).AnyTimes()
+	stubNormalizePhone(f.validator)
+	return f
+}
+
+const resetEmail = "test@example.com"
+
+// 账号不存在时不发送通知，也不返回错误
+func TestPasswordReset_UnknownAccount(t *testing.T) {
+	f := newResetFixture(t)
+	f.repo.EXPECT().FindByEmail(gomock.Any(), resetEmail).Return(nil, apperrors.ErrUserNotFound)
+
+	err := f.uc.RequestReset(context.Background(), resetEmail)
+	assert.NoError(t, err)
+	assert.Empty(t, f.notifier.sent)
+}
+
+// 通过手机号申请，并使用验证码重置密码
+func TestPasswordReset_Success(t *testing.T) {
+	f := newResetFixture(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Phone: "+8613800138000"}
+	f.repo.EXPECT().FindByPhone(gomock.Any(), user.Phone).Return(user, nil)
+
+	// 本地格式的手机号转换为 E.164 格式后查找和发送
+	require.NoError(t, f.uc.RequestReset(ctx, "13800138000"))
+	require.Len(t, f.notifier.sent, 1)
+	assert.Equal(t, biz.ChannelSMS, f.notifier.sent[0].Channel)
+	assert.Equal(t, user.Phone, f.notifier.sent[0].To)
+
+	f.users.EXPECT().ResetPassword(gomock.Any(), uint(1), "NewPass123").Return(user, nil)
+	got, err := f.uc.ConfirmReset(ctx, "13800138000", f.notifier.lastCode(t), "NewPass123")
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+}
+
+// 验证码只能使用一次
+func TestPasswordReset_SingleUse(t *testing.T) {
+	f := newResetFixture(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Email: resetEmail}
+	f.repo.EXPECT().FindByEmail(gomock.Any(), resetEmail).Return(user, nil)
+	f.users.EXPECT().ResetPassword(gomock.Any(), uint(1), gomock.Any()).Return(user, nil).Times(1)
+
+	require.NoError(t, f.uc.RequestReset(ctx, resetEmail))
+	code := f.notifier.lastCode(t)
+
+	_, err := f.uc.ConfirmReset(ctx, resetEmail, code, "NewPass123")
+	require.NoError(t, err)
+	_, err = f.uc.ConfirmReset(ctx, resetEmail, code, "NewPass456")
+	assert.Equal(t, apperrors.ErrVerificationCodeInvalid, err)
+}
+
+// 错误次数达到上限后验证码作废，正确的验证码也不能再使用
+func TestPasswordReset_MaxAttempts(t *testing.T) {
+	f := newResetFixture(t)
+	ctx := context.Background()
+	user := &biz.User{ID: 1, Email: resetEmail}
+	f.repo.EXPECT().FindByEmail(gomock.Any(), resetEmail).Return(user, nil)
+
+	require.NoError(t, f.uc.RequestReset(ctx, resetEmail))
+	code := f.notifier.lastCode(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := f.uc.ConfirmReset(ctx, resetEmail, wrong, "NewPass123")
+		assert.Equal(t, apperrors.ErrVerificationCodeInvalid, err)
+	}
+	_, err := f.uc.ConfirmReset(ctx, resetEmail, code, "NewPass123")
+	assert.Equal(t, apperrors.ErrVerificationCodeInvalid, err)
+}
+
+// 新密码格式错误时不消费验证码
+func TestPasswordReset_InvalidPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctrl)
+	users := mock.NewMockUserService(ctrl)
+	validator := mock.NewMockUserValidator(ctrl)
+	notifier := &fakeNotifier{}
+	codes := biz.NewVerificationCodeUsecase(data.NewMemoryVerificationCodeRepo(),
+		&conf.Verification{CodeExpireDuration: 600, MaxAttempts: 3})
+	uc := biz.NewPasswordResetUsecase(repo, users, codes, notifier, validator)
+	ctx := context.Background()
+
+	user := &biz.User{ID: 1, Email: resetEmail}
+	repo.EXPECT().FindByEmail(gomock.Any(), resetEmail).Return(user, nil)
+	require.NoError(t, uc.RequestReset(ctx, resetEmail))
+	code := notifier.lastCode(t)
+
+	validator.EXPECT().ValidatePartial(gomock.Any(), "Password").Return(apperrors.ErrPasswordFormat)
+	_, err := uc.ConfirmReset(ctx, resetEmail, code, "short")
+	assert.Equal(t, apperrors.ErrPasswordFormat, err)
+
+	validator.EXPECT().ValidatePartial(gomock.Any(), "Password").Return(nil)
+	users.EXPECT().ResetPassword(gomock.Any(), uint(1), "NewPass123").Return(user, nil)
+	_, err = uc.ConfirmReset(ctx, resetEmail, code, "NewPass123")
+	assert.NoError(t, err)
+}
+
+// 邮箱不区分大小写，修改大小写不能绕过冷却期，也不会得到新的验证码和错误次数
+func TestPasswordReset_EmailCase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctrl)
+	users := mock.NewMockUserService(ctrl)
+	validator := mock.NewMockUserValidator(ctrl)
+	validator.EXPECT().ValidatePartial(gomock.Any(), "Password").Return(nil).AnyTimes()
+	notifier := &fakeNotifier{}
+	codes := biz.NewVerificationCodeUsecase(data.NewMemoryVerificationCodeRepo(),
+		&conf.Verification{CodeExpireDuration: 600, MaxAttempts: 3, SendInterval: 60})
+	uc := biz.NewPasswordResetUsecase(repo, users, codes, notifier, validator)
+	ctx := context.Background()
+
+	user := &biz.User{ID: 1, Email: "Test@Example.com"}
+	repo.EXPECT().FindByEmail(gomock.Any(), resetEmail).Return(user, nil).Times(1)
+	require.NoError(t, uc.RequestReset(ctx, "Test@Example.com"))
+	assert.Equal(t, apperrors.ErrVerificationCodeTooFrequent, uc.RequestReset(ctx, "TEST@example.com"))
+	require.Len(t, notifier.sent, 1)
+
+	users.EXPECT().ResetPassword(gomock.Any(), uint(1), "NewPass123").Return(user, nil)
+	_, err := uc.ConfirmReset(ctx, " test@EXAMPLE.com ", notifier.lastCode(t), "NewPass123")
+	require.NoError(t, err)
+}
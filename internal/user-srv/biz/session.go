@@ -0,0 +1,177 @@
+package biz
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+	"github.com/kyson/e-shop-native/pkg/useragent"
+)
+
+// 最近活跃时间的更新间隔，避免每个请求都写一次数据库
+const sessionTouchInterval = time.Minute
+
+// Session 一次成功登录产生的会话，会话ID同时也是刷新令牌的 family ID
+type Session struct {
+	ID         string
+	UserID     uint
+	DeviceName string
+	ClientIP   string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+type SessionRepo interface {
+	Create(ctx context.Context, session *Session) error
+	// 会话不存在时返回 ErrSessionNotFound
+	FindByID(ctx context.Context, id string) (*Session, error)
+	// ListByUser 返回用户所有未注销的会话
+	ListByUser(ctx context.Context, userID uint) ([]*Session, error)
+	Touch(ctx context.Context, id string, lastSeenAt time.Time) error
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+type SessionService interface {
+	// Create 登录成功后创建会话，客户端 IP 和 User-Agent 从 ctx 中读取
+	Create(ctx context.Context, userID uint) (*Session, error)
+	// List 返回用户所有有效的会话，按最近活跃时间倒序
+	List(ctx context.Context, userID uint) ([]*Session, error)
+	// Revoke 注销用户的一个会话，同时吊销该会话的刷新令牌
+	Revoke(ctx context.Context, userID uint, sessionID string) error
+	// RevokeOthers 注销除 currentID 以外的所有会话，currentID 为空时注销全部，返回注销的数量
+	RevokeOthers(ctx context.Context, userID uint, currentID string) (int, error)
+	// CheckSession 会话无效时返回 ErrSessionNotFound，有效时更新最近活跃时间
+	CheckSession(ctx context.Context, userID uint, sessionID string) error
+}
+
+type sessionUsecase struct {
+	repo   SessionRepo
+	tokens RefreshTokenRepo
+	audit  AuditService
+	// 会话在最近一次活跃之后多久失效，与刷新令牌的有效期一致
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewSessionUsecase(repo SessionRepo, tokens RefreshTokenRepo, audit AuditService, c *conf.Auth) SessionService {
+	return &sessionUsecase{
+		repo:   repo,
+		tokens: tokens,
+		audit:  audit,
+		ttl:    time.Second * time.Duration(c.RefreshExpireDuration),
+		now:    time.Now,
+	}
+}
+
+func (uc *sessionUsecase) Create(ctx context.Context, userID uint) (*Session, error) {
+	ip, _ := clientip.FromContext(ctx)
+	ua, _ := useragent.FromContext(ctx)
+	now := uc.now()
+	session := &Session{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		DeviceName: useragent.DeviceName(ua),
+		ClientIP:   ip,
+		UserAgent:  ua,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := uc.repo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *sessionUsecase) List(ctx context.Context, userID uint) ([]*Session, error) {
+	sessions, err := uc.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+	sessions = slices.DeleteFunc(sessions, func(s *Session) bool {
+		return uc.expired(s, now)
+	})
+	slices.SortFunc(sessions, func(a, b *Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (uc *sessionUsecase) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	session, err := uc.find(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return uc.revoke(ctx, session)
+}
+
+func (uc *sessionUsecase) RevokeOthers(ctx context.Context, userID uint, currentID string) (int, error) {
+	sessions, err := uc.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentID {
+			continue
+		}
+		if err := uc.revoke(ctx, session); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (uc *sessionUsecase) CheckSession(ctx context.Context, userID uint, sessionID string) error {
+	session, err := uc.find(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	now := uc.now()
+	if now.Sub(session.LastSeenAt) < sessionTouchInterval {
+		return nil
+	}
+	return uc.repo.Touch(ctx, session.ID, now)
+}
+
+// find 查找用户有效的会话，不属于该用户的会话同样视为不存在
+func (uc *sessionUsecase) find(ctx context.Context, userID uint, sessionID string) (*Session, error) {
+	session, err := uc.repo.FindByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID || session.RevokedAt != nil || uc.expired(session, uc.now()) {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (uc *sessionUsecase) expired(session *Session, now time.Time) bool {
+	return uc.ttl > 0 && now.Sub(session.LastSeenAt) > uc.ttl
+}
+
+func (uc *sessionUsecase) revoke(ctx context.Context, session *Session) error {
+	now := uc.now()
+	if err := uc.repo.Revoke(ctx, session.ID, now); err != nil {
+		return err
+	}
+	if err := uc.tokens.RevokeFamily(ctx, session.ID, now); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{
+		UserID:  session.UserID,
+		ActorID: session.UserID,
+		Event:   logevent.EventUserSessionRevoked,
+		Detail:  session.DeviceName,
+	})
+	return nil
+}
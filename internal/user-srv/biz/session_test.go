@@ -0,0 +1,93 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/useragent"
+)
+
+const testUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+func newTestSessionUsecase() (biz.SessionService, biz.TokenService) {
+	tokenRepo := data.NewMemoryRefreshTokenRepo()
+	c := &conf.Auth{RefreshExpireDuration: 3600}
+	sessions := biz.NewSessionUsecase(data.NewMemorySessionRepo(), tokenRepo, newTestAudit(), c)
+	return sessions, biz.NewTokenUsecase(tokenRepo, newTestAudit(), c)
+}
+
+// 登录时记录设备信息
+func TestSessionUsecase_Create(t *testing.T) {
+	sessions, _ := newTestSessionUsecase()
+	ctx := clientip.ToContext(context.Background(), "10.0.0.1")
+	ctx = useragent.ToContext(ctx, testUserAgent)
+
+	session, err := sessions.Create(ctx, 1)
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.ID)
+	assert.Equal(t, "Chrome on Windows", session.DeviceName)
+	assert.Equal(t, "10.0.0.1", session.ClientIP)
+
+	list, err := sessions.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, session.ID, list[0].ID)
+	assert.NoError(t, sessions.CheckSession(ctx, 1, session.ID))
+}
+
+// 注销会话后会话和刷新令牌都失效
+func TestSessionUsecase_Revoke(t *testing.T) {
+	sessions, tokens := newTestSessionUsecase()
+	ctx := context.Background()
+
+	session, err := sessions.Create(ctx, 1)
+	require.NoError(t, err)
+	refreshToken, err := tokens.IssueRefreshToken(ctx, 1, session.ID)
+	require.NoError(t, err)
+
+	// 不能注销其他用户的会话
+	assert.Equal(t, apperrors.ErrSessionNotFound, sessions.Revoke(ctx, 2, session.ID))
+
+	require.NoError(t, sessions.Revoke(ctx, 1, session.ID))
+	assert.Equal(t, apperrors.ErrSessionNotFound, sessions.CheckSession(ctx, 1, session.ID))
+	_, _, err = tokens.RotateRefreshToken(ctx, refreshToken)
+	assert.Equal(t, apperrors.ErrRefreshTokenInvalid, err)
+
+	list, err := sessions.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+// 注销其他会话时保留当前会话
+func TestSessionUsecase_RevokeOthers(t *testing.T) {
+	sessions, _ := newTestSessionUsecase()
+	ctx := context.Background()
+
+	current, err := sessions.Create(ctx, 1)
+	require.NoError(t, err)
+	for range 2 {
+		_, err := sessions.Create(ctx, 1)
+		require.NoError(t, err)
+	}
+	other, err := sessions.Create(ctx, 2)
+	require.NoError(t, err)
+
+	revoked, err := sessions.RevokeOthers(ctx, 1, current.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+
+	list, err := sessions.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, current.ID, list[0].ID)
+	// 其他用户的会话不受影响
+	assert.NoError(t, sessions.CheckSession(ctx, 2, other.ID))
+}
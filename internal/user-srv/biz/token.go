@@ -0,0 +1,177 @@
+package biz
+
+//go:generate mockgen -source=token.go -destination=mock/mocker_token.go -package=mock
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+)
+
+// RefreshToken 服务端保存的刷新令牌，只保存哈希值，不保存明文
+type RefreshToken struct {
+	ID        uint
+	UserID    uint
+	FamilyID  string // 同一次登录轮换出来的所有刷新令牌属于同一个 family
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time // 已经被轮换过的时间，非空说明令牌已被使用
+	RevokedAt *time.Time // 整个 family 被吊销的时间
+}
+
+type RefreshTokenRepo interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	// 令牌不存在时返回 ErrRefreshTokenInvalid
+	FindByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkUsed 原子地把一个未使用、未吊销的令牌标记为已使用，返回 false 说明令牌已经被别人用过了
+	MarkUsed(ctx context.Context, id uint, usedAt time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeByUser(ctx context.Context, userID uint, revokedAt time.Time) error
+}
+
+type TokenService interface {
+	// IssueRefreshToken 登录成功后签发一个新的刷新令牌，family ID 使用登录会话的ID
+	IssueRefreshToken(ctx context.Context, userID uint, sessionID string) (string, error)
+	// RotateRefreshToken 校验并轮换刷新令牌，返回新令牌的存储记录（包含用户和会话）以及新的刷新令牌
+	RotateRefreshToken(ctx context.Context, token string) (*RefreshToken, string, error)
+	// RevokeRefreshToken 吊销刷新令牌所在的整个 family，令牌不属于该用户时忽略
+	RevokeRefreshToken(ctx context.Context, userID uint, token string) error
+	// RevokeAllRefreshTokens 吊销用户所有的刷新令牌，例如修改密码之后
+	RevokeAllRefreshTokens(ctx context.Context, userID uint) error
+}
+
+type tokenUsecase struct {
+	repo  RefreshTokenRepo
+	audit AuditService
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func NewTokenUsecase(repo RefreshTokenRepo, audit AuditService, c *conf.Auth) TokenService {
+	return &tokenUsecase{
+		repo:  repo,
+		audit: audit,
+		ttl:   time.Second * time.Duration(c.RefreshExpireDuration),
+		now:   time.Now,
+	}
+}
+
+// IssueRefreshToken issues a refresh token in the token family of the login session.
+func (uc *tokenUsecase) IssueRefreshToken(ctx context.Context, userID uint, sessionID string) (string, error) {
+	token, _, err := uc.issue(ctx, userID, sessionID)
+	return token, err
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same family.
+func (uc *tokenUsecase) RotateRefreshToken(ctx context.Context, token string) (*RefreshToken, string, error) {
+	// 1. 查找令牌
+	stored, err := uc.repo.FindByHash(ctx, HashRefreshToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 2. 整个 family 已经被吊销
+	if stored.RevokedAt != nil {
+		return nil, "", apperrors.ErrRefreshTokenInvalid
+	}
+
+	// 3. 令牌已经被轮换过，说明旧令牌被重放，吊销整个 family
+	now := uc.now()
+	if stored.UsedAt != nil {
+		return nil, "", uc.revokeReused(ctx, stored, now)
+	}
+
+	// 4. 令牌过期
+	if now.After(stored.ExpiresAt) {
+		return nil, "", apperrors.ErrRefreshTokenInvalid
+	}
+
+	// 5. 标记为已使用，并发请求中只有一个能成功
+	ok, err := uc.repo.MarkUsed(ctx, stored.ID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", uc.revokeReused(ctx, stored, now)
+	}
+
+	// 6. 在同一个 family 中签发新的令牌
+	newToken, issued, err := uc.issue(ctx, stored.UserID, stored.FamilyID)
+	if err != nil {
+		return nil, "", err
+	}
+	return issued, newToken, nil
+}
+
+// RevokeRefreshToken revokes the family of the given refresh token.
+func (uc *tokenUsecase) RevokeRefreshToken(ctx context.Context, userID uint, token string) error {
+	stored, err := uc.repo.FindByHash(ctx, HashRefreshToken(token))
+	if errors.Is(err, apperrors.ErrRefreshTokenInvalid) {
+		return nil // 退出登录是幂等的，无效的令牌无需处理
+	}
+	if err != nil {
+		return err
+	}
+	if stored.UserID != userID || stored.RevokedAt != nil {
+		return nil
+	}
+	return uc.repo.RevokeFamily(ctx, stored.FamilyID, uc.now())
+}
+
+// RevokeAllRefreshTokens revokes every refresh token of the user.
+func (uc *tokenUsecase) RevokeAllRefreshTokens(ctx context.Context, userID uint) error {
+	if err := uc.repo.RevokeByUser(ctx, userID, uc.now()); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: userID, Event: logevent.EventUserTokensRevoked})
+	return nil
+}
+
+func (uc *tokenUsecase) issue(ctx context.Context, userID uint, familyID string) (string, *RefreshToken, error) {
+	token, err := newRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+	stored := &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: HashRefreshToken(token),
+		ExpiresAt: uc.now().Add(uc.ttl),
+	}
+	if err := uc.repo.Create(ctx, stored); err != nil {
+		return "", nil, err
+	}
+	return token, stored, nil
+}
+
+func (uc *tokenUsecase) revokeReused(ctx context.Context, stored *RefreshToken, now time.Time) error {
+	if err := uc.repo.RevokeFamily(ctx, stored.FamilyID, now); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: stored.UserID, Event: logevent.EventUserRefreshTokenReused})
+	return apperrors.ErrRefreshTokenReused
+}
+
+// 刷新令牌是不透明的随机串，不携带任何信息
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashRefreshToken 计算刷新令牌的存储哈希，令牌本身是高熵随机串，无需加盐
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
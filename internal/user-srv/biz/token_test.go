@@ -0,0 +1,105 @@
+package biz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+func newTestTokenUsecase() biz.TokenService {
+	return biz.NewTokenUsecase(data.NewMemoryRefreshTokenRepo(), newTestAudit(), &conf.Auth{RefreshExpireDuration: 3600})
+}
+
+// 正常轮换
+func TestTokenUsecase_Rotate(t *testing.T) {
+	uc := newTestTokenUsecase()
+	ctx := context.Background()
+
+	token, err := uc.IssueRefreshToken(ctx, 1, "session-1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	issued, rotated, err := uc.RotateRefreshToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), issued.UserID)
+	assert.Equal(t, "session-1", issued.FamilyID)
+	assert.NotEqual(t, token, rotated)
+
+	// 新令牌可以继续轮换
+	issued, _, err = uc.RotateRefreshToken(ctx, rotated)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), issued.UserID)
+}
+
+// 旧令牌被重放时吊销整个 family
+func TestTokenUsecase_ReuseDetection(t *testing.T) {
+	uc := newTestTokenUsecase()
+	ctx := context.Background()
+
+	token, err := uc.IssueRefreshToken(ctx, 1, "session-1")
+	require.NoError(t, err)
+	_, rotated, err := uc.RotateRefreshToken(ctx, token)
+	require.NoError(t, err)
+
+	// 另一个登录产生的 family 不受影响
+	other, err := uc.IssueRefreshToken(ctx, 1, "session-2")
+	require.NoError(t, err)
+
+	// 重放旧令牌
+	_, _, err = uc.RotateRefreshToken(ctx, token)
+	assert.Equal(t, apperrors.ErrRefreshTokenReused, err)
+
+	// 同一 family 中最新的令牌也失效了
+	_, _, err = uc.RotateRefreshToken(ctx, rotated)
+	assert.Equal(t, apperrors.ErrRefreshTokenInvalid, err)
+
+	_, _, err = uc.RotateRefreshToken(ctx, other)
+	assert.NoError(t, err)
+}
+
+func TestTokenUsecase_InvalidToken(t *testing.T) {
+	uc := newTestTokenUsecase()
+
+	_, _, err := uc.RotateRefreshToken(context.Background(), "not-a-token")
+	assert.Equal(t, apperrors.ErrRefreshTokenInvalid, err)
+}
+
+// 过期的令牌不能轮换
+func TestTokenUsecase_Expired(t *testing.T) {
+	uc := biz.NewTokenUsecase(data.NewMemoryRefreshTokenRepo(), newTestAudit(), &conf.Auth{RefreshExpireDuration: -1})
+	ctx := context.Background()
+
+	token, err := uc.IssueRefreshToken(ctx, 1, "session-1")
+	require.NoError(t, err)
+
+	_, _, err = uc.RotateRefreshToken(ctx, token)
+	assert.Equal(t, apperrors.ErrRefreshTokenInvalid, err)
+}
+
+// 退出登录时吊销刷新令牌
+func TestTokenUsecase_Revoke(t *testing.T) {
+	uc := newTestTokenUsecase()
+	ctx := context.Background()
+
+	token, err := uc.IssueRefreshToken(ctx, 1, "session-1")
+	require.NoError(t, err)
+
+	// 不属于该用户的令牌不会被吊销
+	require.NoError(t, uc.RevokeRefreshToken(ctx, 2, token))
+	_, token, err = uc.RotateRefreshToken(ctx, token)
+	require.NoError(t, err)
+
+	require.NoError(t, uc.RevokeRefreshToken(ctx, 1, token))
+	_, _, err = uc.RotateRefreshToken(ctx, token)
+	assert.Equal(t, apperrors.ErrRefreshTokenInvalid, err)
+
+	// 无效的令牌直接忽略
+	assert.NoError(t, uc.RevokeRefreshToken(ctx, 1, "not-a-token"))
+}
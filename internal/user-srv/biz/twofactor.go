@@ -0,0 +1,328 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+	"github.com/kyson/e-shop-native/pkg/totp"
+)
+
+// CodePurposeLoginChallenge 两步验证的登录挑战，复用验证码的存储，target 为挑战的哈希
+const CodePurposeLoginChallenge = "login_challenge"
+
+// 动态验证码允许前后各一个时间步的时钟误差
+const totpSkew = 1
+
+// 恢复码字符集，去掉了容易混淆的 0、o、1、l、i
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// TwoFactor 用户的 TOTP 两步验证，ConfirmedAt 为空说明还没有完成注册
+type TwoFactor struct {
+	UserID       uint
+	Secret       string // base32 编码的 TOTP 密钥，计算动态验证码需要明文
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 // 最近一次使用的时间步，同一个动态验证码不能使用两次
+}
+
+type TwoFactorRepo interface {
+	// Find 不存在时返回 ErrTwoFactorNotEnabled
+	Find(ctx context.Context, userID uint) (*TwoFactor, error)
+	// Save 创建或覆盖未完成注册的密钥
+	Save(ctx context.Context, tf *TwoFactor) error
+	// Confirm 完成注册，同时替换所有恢复码
+	Confirm(ctx context.Context, userID uint, confirmedAt time.Time, recoveryCodeHashes []string) error
+	// Delete 删除密钥和所有恢复码
+	Delete(ctx context.Context, userID uint) error
+	// UseStep 只有 step 大于上次使用的时间步时才更新，返回 false 说明验证码已经被使用过
+	UseStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCode 把未使用的恢复码标记为已使用，返回 false 说明恢复码不存在或已被使用
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error)
+}
+
+type TwoFactorService interface {
+	// Enroll 生成新的密钥，返回密钥和 otpauth:// 链接，account 显示在验证器应用中
+	Enroll(ctx context.Context, userID uint, account string) (string, string, error)
+	// Confirm 校验动态验证码完成注册，返回一次性恢复码的明文
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	// Disable 校验动态验证码或恢复码后关闭两步验证
+	Disable(ctx context.Context, userID uint, code string) error
+	// Enabled 用户是否已开启两步验证
+	Enabled(ctx context.Context, userID uint) (bool, error)
+	// StartLoginChallenge 密码校验通过后创建登录挑战
+	StartLoginChallenge(ctx context.Context, userID uint) (string, error)
+	// CompleteLoginChallenge 校验挑战和动态验证码（或恢复码），成功后返回用户ID，挑战只能使用一次。
+	// 错误的验证码和错误的密码一样计入登录失败次数
+	CompleteLoginChallenge(ctx context.Context, challenge, code string) (uint, error)
+}
+
+type twoFactorUsecase struct {
+	repo          TwoFactorRepo
+	challenges    VerificationCodeRepo
+	limiter       LoginLimiter
+	audit         AuditService
+	issuer        string
+	challengeTTL  time.Duration
+	maxAttempts   int
+	recoveryCodes int
+	now           func() time.Time
+}
+
+func NewTwoFactorUsecase(repo TwoFactorRepo, challenges VerificationCodeRepo, limiter LoginLimiter, audit AuditService, c *conf.TwoFactor) TwoFactorService {
+	return &twoFactorUsecase{
+		repo:          repo,
+		challenges:    challenges,
+		limiter:       limiter,
+		audit:         audit,
+		issuer:        c.Issuer,
+		challengeTTL:  time.Second * time.Duration(c.ChallengeExpireDuration),
+		maxAttempts:   c.MaxAttempts,
+		recoveryCodes: c.RecoveryCodes,
+		now:           time.Now,
+	}
+}
+
+func (uc *twoFactorUsecase) Enroll(ctx context.Context, userID uint, account string) (string, string, error) {
+	// 已完成注册的用户需要先关闭再重新注册，未完成的注册直接覆盖
+	tf, err := uc.repo.Find(ctx, userID)
+	if err != nil && !errors.Is(err, apperrors.ErrTwoFactorNotEnabled) {
+		return "", "", err
+	}
+	if tf != nil && tf.ConfirmedAt != nil {
+		return "", "", apperrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := uc.repo.Save(ctx, &TwoFactor{UserID: userID, Secret: secret}); err != nil {
+		return "", "", err
+	}
+	return secret, totp.URI(uc.issuer, account, secret), nil
+}
+
+func (uc *twoFactorUsecase) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	// 1. 获取未完成注册的密钥
+	tf, err := uc.repo.Find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.ConfirmedAt != nil {
+		return nil, apperrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	// 2. 校验动态验证码，确认用户已经把密钥添加到验证器应用中
+	step, ok := totp.Validate(tf.Secret, code, uc.now(), totpSkew)
+	if !ok {
+		return nil, apperrors.ErrTwoFactorCodeInvalid
+	}
+	ok, err = uc.repo.UseStep(ctx, userID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperrors.ErrTwoFactorCodeInvalid
+	}
+
+	// 3. 生成恢复码，只保存哈希
+	codes, hashes, err := newRecoveryCodes(uc.recoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Confirm(ctx, userID, uc.now(), hashes); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: userID, ActorID: userID, Event: logevent.EventUserTwoFactorEnabled})
+	return codes, nil
+}
+
+func (uc *twoFactorUsecase) Disable(ctx context.Context, userID uint, code string) error {
+	tf, err := uc.findConfirmed(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.verify(ctx, tf, code); err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: userID, ActorID: userID, Event: logevent.EventUserTwoFactorDisabled})
+	return nil
+}
+
+func (uc *twoFactorUsecase) Enabled(ctx context.Context, userID uint) (bool, error) {
+	_, err := uc.findConfirmed(ctx, userID)
+	if errors.Is(err, apperrors.ErrTwoFactorNotEnabled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (uc *twoFactorUsecase) StartLoginChallenge(ctx context.Context, userID uint) (string, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return "", err
+	}
+	err = uc.challenges.Save(ctx, &VerificationCode{
+		Purpose:   CodePurposeLoginChallenge,
+		Target:    hashChallenge(challenge),
+		UserID:    userID,
+		ExpiresAt: uc.now().Add(uc.challengeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+func (uc *twoFactorUsecase) CompleteLoginChallenge(ctx context.Context, challenge, code string) (uint, error) {
+	// 1. 获取挑战
+	target := hashChallenge(challenge)
+	stored, err := uc.challenges.Get(ctx, CodePurposeLoginChallenge, target)
+	if errors.Is(err, apperrors.ErrVerificationCodeInvalid) {
+		return 0, apperrors.ErrLoginChallengeInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	if uc.now().After(stored.ExpiresAt) {
+		return 0, apperrors.ErrLoginChallengeInvalid
+	}
+
+	// 2. 检查客户端 IP 和账号是否被锁定，锁定期间不再校验验证码
+	ip, _ := clientip.FromContext(ctx)
+	if err := uc.limiter.CheckIP(ctx, ip); err != nil {
+		return 0, err
+	}
+	if err := uc.limiter.CheckAccount(ctx, stored.UserID); err != nil {
+		return 0, err
+	}
+
+	// 3. 校验动态验证码，错误次数过多时作废挑战，需要重新输入密码
+	tf, err := uc.findConfirmed(ctx, stored.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.verify(ctx, tf, code); err != nil {
+		if !errors.Is(err, apperrors.ErrTwoFactorCodeInvalid) {
+			return 0, err
+		}
+		if failErr := uc.limiter.Fail(ctx, stored.UserID, ip); failErr != nil {
+			return 0, failErr
+		}
+		attempts, incrErr := uc.challenges.IncrAttempts(ctx, CodePurposeLoginChallenge, target)
+		if incrErr != nil {
+			return 0, incrErr
+		}
+		if uc.maxAttempts > 0 && attempts >= uc.maxAttempts {
+			if _, err := uc.challenges.Delete(ctx, CodePurposeLoginChallenge, target); err != nil {
+				return 0, err
+			}
+		}
+		return 0, err
+	}
+
+	// 4. 消费挑战，并发请求中只有一个能成功
+	deleted, err := uc.challenges.Delete(ctx, CodePurposeLoginChallenge, target)
+	if err != nil {
+		return 0, err
+	}
+	if !deleted {
+		return 0, apperrors.ErrLoginChallengeInvalid
+	}
+	return stored.UserID, nil
+}
+
+func (uc *twoFactorUsecase) findConfirmed(ctx context.Context, userID uint) (*TwoFactor, error) {
+	tf, err := uc.repo.Find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.ConfirmedAt == nil {
+		return nil, apperrors.ErrTwoFactorNotEnabled
+	}
+	return tf, nil
+}
+
+// verify 校验 6 位动态验证码或者恢复码，两者都只能使用一次
+func (uc *twoFactorUsecase) verify(ctx context.Context, tf *TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	var ok bool
+	var err error
+	if len(code) == totp.Digits {
+		var step int64
+		if step, ok = totp.Validate(tf.Secret, code, uc.now(), totpSkew); ok {
+			ok, err = uc.repo.UseStep(ctx, tf.UserID, step)
+		}
+	} else {
+		ok, err = uc.repo.UseRecoveryCode(ctx, tf.UserID, HashRecoveryCode(code), uc.now())
+		if ok {
+			uc.audit.Record(ctx, &AuditEvent{UserID: tf.UserID, ActorID: tf.UserID, Event: logevent.EventUserRecoveryCodeUsed})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		uc.audit.Record(ctx, &AuditEvent{UserID: tf.UserID, Event: logevent.EventUserTwoFactorCodeInvalid})
+		return apperrors.ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// newRecoveryCodes 生成 n 个形如 abcde-fghjk 的恢复码，返回明文和哈希
+func newRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	size := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for range n {
+		b := make([]byte, 10)
+		for i := range b {
+			idx, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+			}
+			b[i] = recoveryCodeAlphabet[idx.Int64()]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode 计算恢复码的存储哈希，忽略大小写和分隔符
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func newChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate login challenge: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashChallenge(challenge string) string {
+	sum := sha256.Sum256([]byte(challenge))
+	return hex.EncodeToString(sum[:])
+}
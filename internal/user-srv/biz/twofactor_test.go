@@ -0,0 +1,186 @@
+package biz_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/totp"
+)
+
+// fakeClock 测试中手动拨动的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestTwoFactorUsecase() (biz.TwoFactorService, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	uc := biz.NewTwoFactorUsecase(data.NewMemoryTwoFactorRepo(), data.NewMemoryVerificationCodeRepo(), newTestLimiter(), newTestAudit(),
+		&conf.TwoFactor{Issuer: "e-shop", ChallengeExpireDuration: 300, MaxAttempts: 3, RecoveryCodes: 4})
+	biz.SetTwoFactorClock(uc, clock.Now)
+	return uc, clock
+}
+
+// enrollTestUser 为用户开启两步验证，返回密钥和恢复码
+func enrollTestUser(t *testing.T, uc biz.TwoFactorService, clock *fakeClock, userID uint) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	secret, uri, err := uc.Enroll(ctx, userID, "testuser")
+	require.NoError(t, err)
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, secret, u.Query().Get("secret"))
+
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := uc.Confirm(ctx, userID, code)
+	require.NoError(t, err)
+	return secret, recoveryCodes
+}
+
+func TestTwoFactorUsecase_Enroll(t *testing.T) {
+	uc, clock := newTestTwoFactorUsecase()
+	ctx := context.Background()
+
+	// 未完成注册时不算开启
+	secret, _, err := uc.Enroll(ctx, 1, "testuser")
+	require.NoError(t, err)
+	enabled, err := uc.Enabled(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	// 错误的动态验证码不能完成注册
+	_, err = uc.Confirm(ctx, 1, "000000")
+	assert.Equal(t, apperrors.ErrTwoFactorCodeInvalid, err)
+
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := uc.Confirm(ctx, 1, code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 4)
+
+	enabled, err = uc.Enabled(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	// 已开启时不能重复注册
+	_, _, err = uc.Enroll(ctx, 1, "testuser")
+	assert.Equal(t, apperrors.ErrTwoFactorAlreadyEnabled, err)
+}
+
+func TestTwoFactorUsecase_LoginChallenge(t *testing.T) {
+	uc, clock := newTestTwoFactorUsecase()
+	ctx := context.Background()
+	secret, _ := enrollTestUser(t, uc, clock, 1)
+
+	challenge, err := uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+
+	// 注册时用过的动态验证码不能再次使用
+	used, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, used)
+	assert.Equal(t, apperrors.ErrTwoFactorCodeInvalid, err)
+
+	// 下一个时间步的验证码可以使用
+	clock.Advance(totp.Period)
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	userID, err := uc.CompleteLoginChallenge(ctx, challenge, code)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+
+	// 挑战只能使用一次
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, code)
+	assert.Equal(t, apperrors.ErrLoginChallengeInvalid, err)
+}
+
+// 挑战过期或者错误次数过多后作废
+func TestTwoFactorUsecase_LoginChallengeExpired(t *testing.T) {
+	uc, clock := newTestTwoFactorUsecase()
+	ctx := context.Background()
+	secret, _ := enrollTestUser(t, uc, clock, 1)
+
+	challenge, err := uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+	clock.Advance(301 * time.Second)
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, code)
+	assert.Equal(t, apperrors.ErrLoginChallengeInvalid, err)
+
+	challenge, err = uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = uc.CompleteLoginChallenge(ctx, challenge, "000000")
+		assert.Equal(t, apperrors.ErrTwoFactorCodeInvalid, err)
+	}
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, code)
+	assert.Equal(t, apperrors.ErrLoginChallengeInvalid, err)
+}
+
+// 错误的动态验证码计入账号的登录失败次数，锁定后不再校验验证码
+func TestTwoFactorUsecase_LoginChallengeLockout(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := biz.NewLoginLimiter(data.NewMemoryLoginAttemptRepo(), lockoutConf)
+	uc := biz.NewTwoFactorUsecase(data.NewMemoryTwoFactorRepo(), data.NewMemoryVerificationCodeRepo(), limiter, newTestAudit(),
+		&conf.TwoFactor{Issuer: "e-shop", ChallengeExpireDuration: 300, MaxAttempts: 5, RecoveryCodes: 4})
+	biz.SetTwoFactorClock(uc, clock.Now)
+	ctx := context.Background()
+	secret, _ := enrollTestUser(t, uc, clock, 1)
+
+	challenge, err := uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = uc.CompleteLoginChallenge(ctx, challenge, "000000")
+		assert.Equal(t, apperrors.ErrTwoFactorCodeInvalid, err)
+	}
+	assert.ErrorIs(t, limiter.CheckAccount(ctx, 1), apperrors.ErrAccountLocked)
+
+	clock.Advance(totp.Period)
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, code)
+	assert.ErrorIs(t, err, apperrors.ErrAccountLocked)
+}
+
+// 恢复码可以代替动态验证码，每个只能使用一次
+func TestTwoFactorUsecase_RecoveryCode(t *testing.T) {
+	uc, clock := newTestTwoFactorUsecase()
+	ctx := context.Background()
+	_, recoveryCodes := enrollTestUser(t, uc, clock, 1)
+
+	challenge, err := uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+	userID, err := uc.CompleteLoginChallenge(ctx, challenge, recoveryCodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+
+	challenge, err = uc.StartLoginChallenge(ctx, 1)
+	require.NoError(t, err)
+	_, err = uc.CompleteLoginChallenge(ctx, challenge, recoveryCodes[0])
+	assert.Equal(t, apperrors.ErrTwoFactorCodeInvalid, err)
+
+	// 关闭两步验证
+	require.NoError(t, uc.Disable(ctx, 1, recoveryCodes[1]))
+	enabled, err := uc.Enabled(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.Equal(t, apperrors.ErrTwoFactorNotEnabled, uc.Disable(ctx, 1, recoveryCodes[2]))
+}
@@ -5,65 +5,224 @@ package biz
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+	"github.com/kyson/e-shop-native/pkg/logevent"
 )
 
+// 用户角色
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+// User 各字段的校验规则由 UserValidator 按配置生成
 type User struct {
 	ID       uint
-	UserName string `validate:"required,min=3,max=20,username"`
-	Password string `validate:"required,min=8,max=64,password"`
-	Phone    string `validate:"required,phone"`
-	Email    string `validate:"required,email"`
+	UserName string
+	Password string
+	Phone    string // E.164 格式，如 +8615012345678
+	Email    string
+	// PhoneRegion 输入的手机号没有国家码时所属的地区，如 CN，为空时使用默认地区，不保存
+	PhoneRegion string
+	// 个人资料，都是可选的
+	Nickname string
+	Gender   Gender
+	Birthday *time.Time
+	// 头像在 BlobStore 中的 key 以及原图、缩略图的访问地址
+	AvatarKey          string
+	AvatarURL          string
+	AvatarThumbnailURL string
+	// 令牌版本，修改密码时递增，使之前签发的访问令牌全部失效
+	TokenVersion uint
+	// 邮箱、手机号的验证时间，为 nil 表示未验证，修改邮箱或手机号后重置
+	EmailVerifiedAt *time.Time
+	PhoneVerifiedAt *time.Time
+	// 用户角色，新注册的用户默认为 customer
+	Roles []string
+	// 被禁用的时间和原因，为 nil 表示正常
+	DisabledAt     *time.Time
+	DisabledReason string
+	CreatedAt      time.Time
+	// 软删除时间，为 nil 表示未删除
+	DeletedAt *time.Time
+}
+
+// Gender 性别，取值与 v1.Gender 一致
+type Gender int32
+
+const (
+	GenderUnspecified Gender = iota
+	GenderMale
+	GenderFemale
+	GenderOther
+)
+
+// birthdayLayout 生日的格式，同时用于资料变更记录
+const birthdayLayout = "2006-01-02"
+
+// UserStatus 用户状态，用于管理后台查询
+type UserStatus int
+
+const (
+	UserStatusAny UserStatus = iota // 未删除的用户
+	UserStatusActive
+	UserStatusDisabled
+	UserStatusDeleted
+)
+
+// UserFilter 用户列表的查询条件
+type UserFilter struct {
+	Keyword string // 用户名、邮箱、手机号前缀
+	Role    string
+	Status  UserStatus
+	Offset  int
+	Limit   int
+}
+
+// IdentifierType 登录标识的类型
+type IdentifierType int
+
+const (
+	IdentifierUsername IdentifierType = iota
+	IdentifierEmail
+	IdentifierPhone
+)
+
+// ProfileChange 记录一次资料字段的修改，便于追溯谁在什么时候改了什么
+type ProfileChange struct {
+	UserID    uint
+	Field     string
+	OldValue  string
+	NewValue  string
+	ChangedBy uint   // 操作人的用户ID
+	Reason    string // 管理员操作时填写的原因
+	ChangedAt time.Time
 }
 
 type UserRepo interface {
 	Create(ctx context.Context, user *User) (*User, error)
+	// Update 只更新 fields 中列出的字段，字段名使用 User 的结构体字段名
+	Update(ctx context.Context, user *User, fields ...string) error
+	// UpdatePassword 保存新的密码哈希并原子地递增令牌版本，返回递增后的令牌版本
+	UpdatePassword(ctx context.Context, id uint, password string) (uint, error)
 	FindByUsername(ctx context.Context, username string) (*User, error)
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByPhone(ctx context.Context, phone string) (*User, error)
+	// FindByID 可能读取缓存，返回的用户不包括密码哈希
 	FindByID(ctx context.Context, id uint) (*User, error)
+	// FindByIDUncached 不经过缓存，从数据库读取包括密码哈希在内的最新数据
+	FindByIDUncached(ctx context.Context, id uint) (*User, error)
+	// FindByIDs 一次查询多个用户，不包括已软删除的用户，不存在的 ID 不返回
+	FindByIDs(ctx context.Context, ids []uint) ([]*User, error)
+	// FindByIDWithDeleted 查询时包括已软删除的用户
+	FindByIDWithDeleted(ctx context.Context, id uint) (*User, error)
+	// List 按条件分页查询，同时返回符合条件的总数
+	List(ctx context.Context, filter *UserFilter) ([]*User, int64, error)
+	// Delete 软删除
+	Delete(ctx context.Context, id uint) error
+	// Restore 恢复软删除的用户
+	Restore(ctx context.Context, id uint) error
+}
+
+type ProfileChangeRepo interface {
+	Create(ctx context.Context, changes []*ProfileChange) error
+}
+
+// PasswordHistoryRepo 保存用户用过的密码哈希，用于禁止重复使用最近的密码
+type PasswordHistoryRepo interface {
+	Create(ctx context.Context, userID uint, passwordHash string) error
+	// ListRecent 按时间倒序返回最近 n 个密码哈希
+	ListRecent(ctx context.Context, userID uint, n int) ([]string, error)
 }
 
 type UserService interface {
 	RegisterUser(ctx context.Context, user *User) (*User, error)
-	Login(ctx context.Context, username, password string) (*User, error)
+	// Login 使用用户名、邮箱或手机号登录，只校验密码和账号状态，登录完成后需要调用 CompleteLogin
+	Login(ctx context.Context, identifier, password string) (*User, error)
+	// CompleteLogin 登录完成（开启两步验证时为通过挑战）后清除账号的失败记录并记录审计
+	CompleteLogin(ctx context.Context, userID uint) error
 	GetMyProfile(ctx context.Context, userID uint) (*User, error)
+	// UpdateProfile 修改 fields 中列出的资料字段，支持 Email、Phone、Nickname、Gender、Birthday
+	UpdateProfile(ctx context.Context, userID uint, update *User, fields []string) (*User, error)
+	// ChangePassword 校验旧密码后修改密码，并递增令牌版本
+	ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) (*User, error)
+	// ResetPassword 不校验旧密码直接设置新密码，调用方需要先通过验证码等方式确认身份
+	ResetPassword(ctx context.Context, userID uint, newPassword string) (*User, error)
+	GetTokenVersion(ctx context.Context, userID uint) (uint, error)
+	// CheckUserStatus 用户被禁用时返回 ErrUserDisabled，被删除时返回 ErrUserNotFound
+	CheckUserStatus(ctx context.Context, userID uint) error
 }
 
 // 验证用户信息是否符合要求
 type UserValidator interface {
 	Validate(user *User) error
+	// ValidatePartial 只验证 fields 中列出的字段
+	ValidatePartial(user *User, fields ...string) error
+	// DetectIdentifier 按邮箱、手机号的校验规则判断登录标识的类型，都不符合时视为用户名
+	DetectIdentifier(identifier string) (IdentifierType, error)
+	// NormalizePhone 把手机号转换为 E.164 格式，没有国家码时按 region 解析，region 为空时使用默认地区
+	NormalizePhone(phone, region string) (string, error)
 }
 
 type PasswordHash interface {
 	Hash(password string) (string, error)
 	Virefy(password, hashedPassword string) bool
+	// NeedsRehash 哈希的算法或参数已过时，需要用当前配置重新计算
+	NeedsRehash(hashedPassword string) bool
 }
 
 type userUsecase struct {
 	repo      UserRepo
+	changes   ProfileChangeRepo
+	passwords PasswordHistoryRepo
 	validator UserValidator
 	bcrypt    PasswordHash
+	limiter   LoginLimiter
+	audit     AuditService
+	// 修改密码时不能与最近多少个密码相同
+	passwordHistory int
+	// 邮箱未验证的用户不能登录
+	requireVerifiedEmail bool
 }
 
-func NewUserUsecase(repo UserRepo, validator UserValidator, bcrypt PasswordHash) UserService {
+func NewUserUsecase(repo UserRepo, changes ProfileChangeRepo, passwords PasswordHistoryRepo,
+	validator UserValidator, bcrypt PasswordHash, limiter LoginLimiter, audit AuditService, c *conf.Auth) UserService {
 	return &userUsecase{
-		repo:      repo,
-		validator: validator,
-		bcrypt:    bcrypt,
+		repo:                 repo,
+		changes:              changes,
+		passwords:            passwords,
+		validator:            validator,
+		bcrypt:               bcrypt,
+		limiter:              limiter,
+		audit:                audit,
+		passwordHistory:      c.PasswordHistory,
+		requireVerifiedEmail: c.RequireVerifiedEmail,
 	}
 }
 
 // RegisterUser registers a new user with the provided details.
 func (uc *userUsecase) RegisterUser(ctx context.Context, user *User) (*User, error) {
-	// 1. 校验格式（用户名、邮箱、密码、手机号）
+	// 1. 手机号转换为 E.164 格式后校验格式（用户名、邮箱、密码、手机号）
+	phone, err := uc.validator.NormalizePhone(user.Phone, user.PhoneRegion)
+	if err != nil {
+		return nil, err
+	}
+	user.Phone = phone
 	if err := uc.validator.Validate(user); err != nil {
 		return nil, err
 	}
 
-	// 2. 检查用户名是否已存在
-	_, err := uc.repo.FindByUsername(ctx, user.UserName)
+	// 2. 检查用户名是否已存在（不区分大小写），避免为已存在的用户计算密码哈希，
+	// 并发注册以及邮箱、手机号重复由数据库的唯一索引保证，Create 返回 ErrUserAlreadyExists
+	_, err = uc.repo.FindByUsername(ctx, user.UserName)
 	if err == nil {
-		return nil, apperrors.ErrUserAlreadyExists // 用户名已存在
+		return nil, apperrors.ErrUserAlreadyExists.WithMessageKey("username", "用户名已存在")
 	}
 	if !errors.Is(err, apperrors.ErrUserNotFound) { // 非用户不存在错误
 		// 其他数据库错误
@@ -76,34 +235,117 @@ func (uc *userUsecase) RegisterUser(ctx context.Context, user *User) (*User, err
 		return nil, err
 	}
 	user.Password = ps
+	user.Roles = []string{RoleCustomer}
 
 	// 4. 创建新用户
 	createdUser, err := uc.repo.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: createdUser.ID, ActorID: createdUser.ID, Event: logevent.EventUserCreated})
 	return createdUser, nil
 }
 
-// Login authenticates a user with the provided username and password.
-func (uc *userUsecase) Login(ctx context.Context, username, password string) (*User, error) {
-	// 1. 获取用户信息
-	user, err := uc.repo.FindByUsername(ctx, username)
+// Login authenticates a user with the provided username, email or phone and password.
+func (uc *userUsecase) Login(ctx context.Context, identifier, password string) (*User, error) {
+	// 1. 检查客户端 IP 是否被锁定
+	ip, _ := clientip.FromContext(ctx)
+	if err := uc.limiter.CheckIP(ctx, ip); err != nil {
+		return nil, err
+	}
+
+	// 2. 获取用户信息
+	user, err := uc.findByIdentifier(ctx, identifier)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		uc.audit.Record(ctx, &AuditEvent{Event: logevent.EventUserLoginNotFound, Detail: identifier})
+		if err := uc.limiter.Fail(ctx, 0, ip); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 验证密码
+	// 3. 检查账号是否被锁定，锁定期间不再校验密码
+	if err := uc.limiter.CheckAccount(ctx, user.ID); err != nil {
+		if errors.Is(err, apperrors.ErrAccountLocked) {
+			uc.audit.Record(ctx, &AuditEvent{UserID: user.ID, Event: logevent.EventUserLoginLocked})
+		}
+		return nil, err
+	}
+
+	// 4. 验证密码
 	ok := uc.bcrypt.Virefy(password, user.Password)
 	if !ok {
+		uc.audit.Record(ctx, &AuditEvent{UserID: user.ID, Event: logevent.EventUserLoginPasswordIncorrect})
+		if err := uc.limiter.Fail(ctx, user.ID, ip); err != nil {
+			return nil, err
+		}
 		return nil, apperrors.ErrPasswordIncorrect
 	}
 
+	// 5. 被禁用的账号不能登录，同样放在密码校验之后
+	if user.DisabledAt != nil {
+		uc.audit.Record(ctx, &AuditEvent{UserID: user.ID, Event: logevent.EventUserLoginDisabled})
+		return nil, apperrors.ErrUserDisabled
+	}
+
+	// 6. 检查邮箱是否已验证，放在密码校验之后，避免暴露账号状态
+	if uc.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, apperrors.ErrEmailNotVerified
+	}
+
+	// 7. 用当前的算法和参数重新计算旧的密码哈希，失败时不影响登录，下次登录时再次尝试
+	if uc.bcrypt.NeedsRehash(user.Password) {
+		uc.rehash(ctx, user, password)
+	}
+
 	user.Password = password
-	// 3. 返回用户信息
+	// 8. 返回用户信息
 	return user, nil
 }
 
+func (uc *userUsecase) CompleteLogin(ctx context.Context, userID uint) error {
+	if err := uc.limiter.Succeed(ctx, userID); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: userID, ActorID: userID, Event: logevent.EventUserLogin})
+	return nil
+}
+
+func (uc *userUsecase) rehash(ctx context.Context, user *User, password string) {
+	hashed, err := uc.bcrypt.Hash(password)
+	if err != nil {
+		return
+	}
+	old := user.Password
+	user.Password = hashed
+	if err := uc.repo.Update(ctx, user, "Password"); err != nil {
+		user.Password = old
+	}
+}
+
+// findByIdentifier 根据登录标识的类型查找用户
+func (uc *userUsecase) findByIdentifier(ctx context.Context, identifier string) (*User, error) {
+	kind, err := uc.validator.DetectIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case IdentifierEmail:
+		return uc.repo.FindByEmail(ctx, identifier)
+	case IdentifierPhone:
+		phone, err := uc.validator.NormalizePhone(identifier, "")
+		if err != nil {
+			return nil, err
+		}
+		return uc.repo.FindByPhone(ctx, phone)
+	default:
+		return uc.repo.FindByUsername(ctx, identifier)
+	}
+}
+
 func (uc *userUsecase) GetMyProfile(ctx context.Context, userID uint) (*User, error) {
 	// 1. 获取用户信息
 	user, err := uc.repo.FindByID(ctx, userID)
@@ -114,3 +356,250 @@ func (uc *userUsecase) GetMyProfile(ctx context.Context, userID uint) (*User, er
 	// 2. 返回用户信息
 	return user, nil
 }
+
+// UpdateProfile updates the given profile fields of a user and records what changed.
+func (uc *userUsecase) UpdateProfile(ctx context.Context, userID uint, update *User, fields []string) (*User, error) {
+	// 1. 获取用户信息
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 找出真正发生变化的字段
+	var changes []*ProfileChange
+	var changed []string
+	now := time.Now()
+	for _, field := range fields {
+		var oldValue, newValue string
+		switch field {
+		case "Email":
+			oldValue, newValue = user.Email, update.Email
+			user.Email = update.Email
+		case "Phone":
+			phone, err := uc.validator.NormalizePhone(update.Phone, update.PhoneRegion)
+			if err != nil {
+				return nil, err
+			}
+			oldValue, newValue = user.Phone, phone
+			user.Phone = phone
+		case "Nickname":
+			oldValue, newValue = user.Nickname, update.Nickname
+			user.Nickname = update.Nickname
+		case "Gender":
+			if update.Gender < GenderUnspecified || update.Gender > GenderOther {
+				return nil, apperrors.ErrInvalidArgument.WithMessageKey("gender", "性别取值错误")
+			}
+			oldValue, newValue = strconv.Itoa(int(user.Gender)), strconv.Itoa(int(update.Gender))
+			user.Gender = update.Gender
+		case "Birthday":
+			if err := checkBirthday(update.Birthday, now); err != nil {
+				return nil, err
+			}
+			oldValue, newValue = formatBirthday(user.Birthday), formatBirthday(update.Birthday)
+			user.Birthday = update.Birthday
+		default:
+			return nil, apperrors.ErrInvalidArgument.WithMessageKey("unsupported_field", "不支持修改的字段: %s", field)
+		}
+		if oldValue == newValue {
+			continue
+		}
+		changed = append(changed, field)
+		changes = append(changes, &ProfileChange{
+			UserID:    userID,
+			Field:     field,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			ChangedBy: userID,
+			ChangedAt: now,
+		})
+	}
+	if len(changed) == 0 {
+		return user, nil
+	}
+
+	// 3. 只校验变化的字段
+	if err := uc.validator.ValidatePartial(user, changed...); err != nil {
+		return nil, err
+	}
+
+	// 4. 检查邮箱、手机号是否已被其他用户使用
+	for _, field := range changed {
+		if err := uc.checkUnique(ctx, user, field); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. 邮箱、手机号变化后需要重新验证
+	updates := append([]string{}, changed...)
+	for _, field := range changed {
+		switch {
+		case field == "Email" && user.EmailVerifiedAt != nil:
+			user.EmailVerifiedAt = nil
+			updates = append(updates, "EmailVerifiedAt")
+		case field == "Phone" && user.PhoneVerifiedAt != nil:
+			user.PhoneVerifiedAt = nil
+			updates = append(updates, "PhoneVerifiedAt")
+		}
+	}
+
+	// 6. 保存修改并记录变更
+	if err := uc.repo.Update(ctx, user, updates...); err != nil {
+		return nil, err
+	}
+	if err := uc.changes.Create(ctx, changes); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkBirthday 生日不能晚于今天，也不能早于 1900 年，nil 表示清除生日
+func checkBirthday(birthday *time.Time, now time.Time) error {
+	if birthday == nil {
+		return nil
+	}
+	if birthday.After(now) || birthday.Year() < 1900 {
+		return apperrors.ErrInvalidArgument.WithMessageKey("birthday_range", "生日日期错误")
+	}
+	return nil
+}
+
+func formatBirthday(birthday *time.Time) string {
+	if birthday == nil {
+		return ""
+	}
+	return birthday.Format(birthdayLayout)
+}
+
+// ParseBirthday 解析 YYYY-MM-DD 格式的生日，空字符串表示未设置
+func ParseBirthday(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(birthdayLayout, s)
+	if err != nil {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("birthday_format", "生日格式错误，应为 YYYY-MM-DD")
+	}
+	return &t, nil
+}
+
+// FormatBirthday 把生日格式化为 YYYY-MM-DD，未设置时返回空字符串
+func FormatBirthday(birthday *time.Time) string {
+	return formatBirthday(birthday)
+}
+
+func (uc *userUsecase) checkUnique(ctx context.Context, user *User, field string) error {
+	var existing *User
+	var err error
+	var errExists error
+	switch field {
+	case "Email":
+		existing, err = uc.repo.FindByEmail(ctx, user.Email)
+		errExists = apperrors.ErrEmailAlreadyExists
+	case "Phone":
+		existing, err = uc.repo.FindByPhone(ctx, user.Phone)
+		errExists = apperrors.ErrPhoneAlreadyExists
+	default:
+		return nil
+	}
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != user.ID {
+		return errExists
+	}
+	return nil
+}
+
+// ChangePassword changes the password of a user after verifying the current one.
+func (uc *userUsecase) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) (*User, error) {
+	// 1. 获取用户信息，缓存中没有密码哈希
+	user, err := uc.repo.FindByIDUncached(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 验证旧密码
+	if !uc.bcrypt.Virefy(oldPassword, user.Password) {
+		return nil, apperrors.ErrPasswordIncorrect
+	}
+
+	// 3. 设置新密码
+	if err := uc.setPassword(ctx, user, newPassword); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: user.ID, ActorID: user.ID, Event: logevent.EventUserPasswordChanged})
+	return user, nil
+}
+
+// ResetPassword sets a new password for a user whose identity has been verified elsewhere.
+func (uc *userUsecase) ResetPassword(ctx context.Context, userID uint, newPassword string) (*User, error) {
+	user, err := uc.repo.FindByIDUncached(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.setPassword(ctx, user, newPassword); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, &AuditEvent{UserID: user.ID, ActorID: user.ID, Event: logevent.EventUserPasswordReset})
+	return user, nil
+}
+
+// setPassword 校验并保存新密码，同时递增令牌版本让已签发的令牌失效
+func (uc *userUsecase) setPassword(ctx context.Context, user *User, newPassword string) error {
+	// 1. 校验新密码格式和强度
+	if err := uc.validator.ValidatePartial(&User{Password: newPassword, UserName: user.UserName, Email: user.Email}, "Password"); err != nil {
+		return err
+	}
+
+	// 2. 不能与当前密码以及最近用过的密码相同
+	recent := []string{user.Password}
+	if uc.passwordHistory > 0 {
+		hashes, err := uc.passwords.ListRecent(ctx, user.ID, uc.passwordHistory)
+		if err != nil {
+			return err
+		}
+		recent = append(recent, hashes...)
+	}
+	for _, hash := range recent {
+		if uc.bcrypt.Virefy(newPassword, hash) {
+			return apperrors.ErrPasswordReused
+		}
+	}
+
+	// 3. 保存新密码
+	hashed, err := uc.bcrypt.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	// 并发修改密码时令牌版本在数据库中递增，不能读出后加一再写回
+	version, err := uc.repo.UpdatePassword(ctx, user.ID, hashed)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.TokenVersion = version
+	return uc.passwords.Create(ctx, user.ID, hashed)
+}
+
+// GetTokenVersion 和 CheckUserStatus 用于校验令牌，不读取缓存，修改密码、禁用用户后立即生效
+func (uc *userUsecase) GetTokenVersion(ctx context.Context, userID uint) (uint, error) {
+	user, err := uc.repo.FindByIDUncached(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+func (uc *userUsecase) CheckUserStatus(ctx context.Context, userID uint) error {
+	user, err := uc.repo.FindByIDUncached(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return apperrors.ErrUserDisabled
+	}
+	return nil
+}
@@ -3,17 +3,33 @@ package biz_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
 	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
 	mock "github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
 
 	gomock "github.com/golang/mock/gomock"
 )
 
+// newTestLimiter 不限制登录失败次数
+func newTestLimiter() biz.LoginLimiter {
+	return biz.NewLoginLimiter(data.NewMemoryLoginAttemptRepo(), &conf.Auth{})
+}
+
+// newTestAudit 审计事件只保存在内存中
+func newTestAudit() biz.AuditService {
+	return biz.NewAuditUsecase(data.NewMemoryAuditRepo(), zap.NewNop())
+}
+
 // 定义自定义匹配器类型
 type userMatcher struct {
 	expectedUsername string
@@ -53,7 +69,9 @@ func TestUserUsecase_RegisterUser(t *testing.T) {
 	repo := mock.NewMockUserRepo(ctl)
 	validator := mock.NewMockUserValidator(ctl)
 	passwordHash := mock.NewMockPasswordHash(ctl)
-	uc := biz.NewUserUsecase(repo, validator, passwordHash)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validator, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
 
 	tests := []struct {
 		name      string
@@ -64,37 +82,52 @@ func TestUserUsecase_RegisterUser(t *testing.T) {
 		{
 			name: "成功注册新用户",
 			user: &biz.User{
-				ID:       0,
-				UserName: "testuser",
-				Password: "pAssword123",
-				Phone:    "15019458680",
-				Email:    "testuser@example.com",
+				ID:          0,
+				UserName:    "testuser",
+				Password:    "pAssword123",
+				Phone:       "150 1945 8680",
+				PhoneRegion: "CN",
+				Email:       "testuser@example.com",
 			},
 			setupMock: func(user *biz.User) {
-				// 验证格式
+				// 手机号转换为 E.164 格式后验证格式
+				validator.EXPECT().NormalizePhone("150 1945 8680", "CN").Return("+8615019458680", nil)
 				validator.EXPECT().Validate(gomock.Eq(user)).Return(nil)
 				// 判断是否已经注册
 				repo.EXPECT().FindByUsername(gomock.Any(), user.UserName).Return(nil, apperrors.ErrUserNotFound)
 				// 密码哈希
 				passwordHash.EXPECT().Hash(user.Password).Return("hashed_password", nil)
 				// 创建
-				repo.EXPECT().Create(gomock.Any(), NewUserMatcher(user.UserName, "hashed_password", user.Phone, user.Email)).DoAndReturn(
+				repo.EXPECT().Create(gomock.Any(), NewUserMatcher(user.UserName, "hashed_password", "+8615019458680", user.Email)).DoAndReturn(
 					func(ctx context.Context, user *biz.User) (*biz.User, error) {
 						user.ID = 1
 						return user, nil
 					})
 			},
 			wantErr: nil,
+		}, {
+			name: "手机号格式无效",
+			user: &biz.User{
+				UserName: "testuser",
+				Password: "pAssword123",
+				Phone:    "1501945868",
+				Email:    "testuser@example.com",
+			},
+			setupMock: func(user *biz.User) {
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return("", apperrors.ErrPhoneFormat)
+			},
+			wantErr: apperrors.ErrPhoneFormat,
 		}, {
 			name: "密码格式无效",
 			user: &biz.User{
 				UserName: "testuser",
 				Password: "password",
-				Phone:    "15019458680",
+				Phone:    "+8615019458680",
 				Email:    "testuser@example.com",
 			},
 			setupMock: func(user *biz.User) {
 				// 验证格式
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return(user.Phone, nil)
 				validator.EXPECT().Validate(gomock.Eq(user)).Return(apperrors.ErrPasswordFormat)
 			},
 			wantErr: apperrors.ErrPasswordFormat,
@@ -103,26 +136,45 @@ func TestUserUsecase_RegisterUser(t *testing.T) {
 			user: &biz.User{
 				UserName: "existinguser",
 				Password: "pAssword123",
-				Phone:    "15766498680",
+				Phone:    "+8615766498680",
 				Email:    "existinguser@example.com",
 			},
 			setupMock: func(user *biz.User) {
 				// 验证格式
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return(user.Phone, nil)
 				validator.EXPECT().Validate(gomock.Eq(user)).Return(nil)
 				// 判断是否已经注册
 				repo.EXPECT().FindByUsername(gomock.Any(), user.UserName).Return(&biz.User{}, nil)
 			},
-			wantErr: apperrors.ErrUserAlreadyExists,
+			wantErr: apperrors.ErrUserAlreadyExists.WithMessageKey("username", "用户名已存在"),
+		}, {
+			name: "邮箱已被其他用户使用",
+			user: &biz.User{
+				UserName: "newuser",
+				Password: "pAssword123",
+				Phone:    "+8615766498680",
+				Email:    "existinguser@example.com",
+			},
+			setupMock: func(user *biz.User) {
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return(user.Phone, nil)
+				validator.EXPECT().Validate(gomock.Eq(user)).Return(nil)
+				repo.EXPECT().FindByUsername(gomock.Any(), user.UserName).Return(nil, apperrors.ErrUserNotFound)
+				passwordHash.EXPECT().Hash(user.Password).Return("hashed_password", nil)
+				// 唯一索引冲突由 repo 转换为业务错误
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrUserAlreadyExists.WithMessageKey("email", "邮箱已被使用"))
+			},
+			wantErr: apperrors.ErrUserAlreadyExists.WithMessageKey("email", "邮箱已被使用"),
 		}, {
 			name: "密码哈希失败",
 			user: &biz.User{
 				UserName: "testuser",
 				Password: "pAssword123",
-				Phone:    "15019458680",
+				Phone:    "+8615019458680",
 				Email:    "testuser@example.com",
 			},
 			setupMock: func(user *biz.User) {
 				// 验证格式
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return(user.Phone, nil)
 				validator.EXPECT().Validate(gomock.Eq(user)).Return(nil)
 				// 判断是否已经注册
 				repo.EXPECT().FindByUsername(gomock.Any(), user.UserName).Return(nil, apperrors.ErrUserNotFound)
@@ -135,11 +187,12 @@ func TestUserUsecase_RegisterUser(t *testing.T) {
 			user: &biz.User{
 				UserName: "testuser",
 				Password: "pAssword123",
-				Phone:    "15019458680",
+				Phone:    "+8615019458680",
 				Email:    "testuser@example.com",
 			},
 			setupMock: func(user *biz.User) {
 				// 验证格式
+				validator.EXPECT().NormalizePhone(user.Phone, "").Return(user.Phone, nil)
 				validator.EXPECT().Validate(gomock.Eq(user)).Return(nil)
 				// 判断是否已经注册
 				repo.EXPECT().FindByUsername(gomock.Any(), user.UserName).Return(nil, apperrors.ErrUserNotFound)
@@ -168,7 +221,10 @@ func TestUserUsecase_Login(t *testing.T) {
 	repo := mock.NewMockUserRepo(ctl)
 	validate := mock.NewMockUserValidator(ctl)
 	passwordHash := mock.NewMockPasswordHash(ctl)
-	uc := biz.NewUserUsecase(repo, validate, passwordHash)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
 
 	tests := []struct {
 		name      string
@@ -187,6 +243,7 @@ func TestUserUsecase_Login(t *testing.T) {
 					ID: 1, UserName: username, Password: "hashed_password",
 				}, nil)
 				passwordHash.EXPECT().Virefy(password, "hashed_password").Return(true)
+				passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false)
 			},
 			wantUser: &biz.User{ID: 1, UserName: "testuser", Password: "pAssword123"},
 			wantErr:  nil,
@@ -214,6 +271,20 @@ func TestUserUsecase_Login(t *testing.T) {
 			wantUser: nil,
 			wantErr:  apperrors.ErrPasswordIncorrect,
 		},
+		{
+			name:     "账号已被禁用",
+			username: "disableduser",
+			password: "pAssword123",
+			setupMock: func(username, password string) {
+				disabledAt := time.Now()
+				repo.EXPECT().FindByUsername(gomock.Any(), username).Return(&biz.User{
+					ID: 2, UserName: username, Password: "hashed_password", DisabledAt: &disabledAt,
+				}, nil)
+				passwordHash.EXPECT().Virefy(password, "hashed_password").Return(true)
+			},
+			wantUser: nil,
+			wantErr:  apperrors.ErrUserDisabled,
+		},
 		{
 			name:     "数据库错误",
 			username: "testuser",
@@ -235,14 +306,141 @@ func TestUserUsecase_Login(t *testing.T) {
 	}
 }
 
+// 使用邮箱、手机号登录
+func TestUserUsecase_LoginByIdentifier(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
+
+	stored := func() *biz.User {
+		return &biz.User{ID: 1, UserName: "testuser", Password: "hashed_password"}
+	}
+	tests := []struct {
+		name       string
+		identifier string
+		setupMock  func(identifier string)
+		wantErr    error
+	}{
+		{
+			name:       "邮箱登录",
+			identifier: "test@example.com",
+			setupMock: func(identifier string) {
+				validate.EXPECT().DetectIdentifier(identifier).Return(biz.IdentifierEmail, nil)
+				repo.EXPECT().FindByEmail(gomock.Any(), identifier).Return(stored(), nil)
+				passwordHash.EXPECT().Virefy("pAssword123", "hashed_password").Return(true)
+				passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false)
+			},
+		},
+		{
+			name:       "手机号登录",
+			identifier: "15019458680",
+			setupMock: func(identifier string) {
+				// 本地格式的手机号转换为 E.164 格式后查找
+				validate.EXPECT().DetectIdentifier(identifier).Return(biz.IdentifierPhone, nil)
+				validate.EXPECT().NormalizePhone(identifier, "").Return("+8615019458680", nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), "+8615019458680").Return(stored(), nil)
+				passwordHash.EXPECT().Virefy("pAssword123", "hashed_password").Return(true)
+				passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false)
+			},
+		},
+		{
+			name:       "手机号不存在",
+			identifier: "+8615766498680",
+			setupMock: func(identifier string) {
+				validate.EXPECT().DetectIdentifier(identifier).Return(biz.IdentifierPhone, nil)
+				validate.EXPECT().NormalizePhone(identifier, "").Return(identifier, nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), identifier).Return(nil, apperrors.ErrUserNotFound)
+			},
+			wantErr: apperrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock(tt.identifier)
+			_, err := uc.Login(context.Background(), tt.identifier, "pAssword123")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+// 要求邮箱已验证才能登录
+func TestUserUsecase_LoginRequireVerifiedEmail(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{RequireVerifiedEmail: true})
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
+	passwordHash.EXPECT().NeedsRehash("hashed_password").Return(false).AnyTimes()
+
+	verifiedAt := time.Now()
+	tests := []struct {
+		name            string
+		emailVerifiedAt *time.Time
+		wantErr         error
+	}{
+		{name: "邮箱已验证", emailVerifiedAt: &verifiedAt},
+		{name: "邮箱未验证", wantErr: apperrors.ErrEmailNotVerified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.EXPECT().FindByUsername(gomock.Any(), "testuser").Return(&biz.User{
+				ID: 1, UserName: "testuser", Password: "hashed_password", EmailVerifiedAt: tt.emailVerifiedAt,
+			}, nil)
+			passwordHash.EXPECT().Virefy("pAssword123", "hashed_password").Return(true)
+			_, err := uc.Login(context.Background(), "testuser", "pAssword123")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
 // 获取用户信息
+// 登录成功后把旧的 bcrypt 哈希升级为 argon2id
+func TestUserUsecase_LoginRehash(t *testing.T) {
+	ctl := gomock.NewController(t)
+	repo := mock.NewMockUserRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	c := newTestPasswordConfig()
+	c.Algorithm = biz.PasswordAlgorithmBcrypt
+	oldHash, err := biz.NewPasswordHasher(c).Hash("pAssword123")
+	require.NoError(t, err)
+
+	hasher := biz.NewPasswordHasher(newTestPasswordConfig())
+	uc := biz.NewUserUsecase(repo, mock.NewMockProfileChangeRepo(ctl), mock.NewMockPasswordHistoryRepo(ctl),
+		validate, hasher, newTestLimiter(), newTestAudit(), &conf.Auth{})
+	validate.EXPECT().DetectIdentifier(gomock.Any()).Return(biz.IdentifierUsername, nil).AnyTimes()
+	repo.EXPECT().FindByUsername(gomock.Any(), "testuser").Return(&biz.User{ID: 1, UserName: "testuser", Password: oldHash}, nil)
+
+	var saved string
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), "Password").DoAndReturn(
+		func(ctx context.Context, user *biz.User, fields ...string) error {
+			saved = user.Password
+			return nil
+		})
+	_, err = uc.Login(context.Background(), "testuser", "pAssword123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(saved, "$argon2id$"))
+	assert.True(t, hasher.Virefy("pAssword123", saved))
+	assert.False(t, hasher.NeedsRehash(saved))
+}
+
 func TestUserUsecase_GetMyProfile(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
 	repo := mock.NewMockUserRepo(ctl)
 	validate := mock.NewMockUserValidator(ctl)
 	passwordHash := mock.NewMockPasswordHash(ctl)
-	uc := biz.NewUserUsecase(repo, validate, passwordHash)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
 	tests := []struct {
 		name      string
 		userID    uint
@@ -287,3 +485,234 @@ func TestUserUsecase_GetMyProfile(t *testing.T) {
 		})
 	}
 }
+
+// 修改用户资料
+func TestUserUsecase_UpdateProfile(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	repo := mock.NewMockUserRepo(ctl)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
+
+	current := func() *biz.User {
+		return &biz.User{ID: 1, UserName: "testuser", Phone: "+8615019458680", Email: "old@example.com"}
+	}
+	verifiedAt := time.Now()
+	birthday := time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC)
+	future := time.Now().AddDate(0, 0, 2)
+
+	tests := []struct {
+		name      string
+		update    *biz.User
+		fields    []string
+		setupMock func()
+		wantUser  *biz.User
+		wantErr   error
+	}{
+		{
+			name:   "成功修改邮箱",
+			update: &biz.User{Email: "new@example.com", Phone: "ignored"},
+			fields: []string{"Email"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+				validate.EXPECT().ValidatePartial(gomock.Any(), "Email").Return(nil)
+				repo.EXPECT().FindByEmail(gomock.Any(), "new@example.com").Return(nil, apperrors.ErrUserNotFound)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any(), "Email").Return(nil)
+				changes.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, changes []*biz.ProfileChange) error {
+						assert.Len(t, changes, 1)
+						assert.Equal(t, "old@example.com", changes[0].OldValue)
+						assert.Equal(t, "new@example.com", changes[0].NewValue)
+						assert.Equal(t, uint(1), changes[0].ChangedBy)
+						return nil
+					})
+			},
+			wantUser: &biz.User{ID: 1, UserName: "testuser", Phone: "+8615019458680", Email: "new@example.com"},
+		}, {
+			name:   "修改已验证的邮箱后需要重新验证",
+			update: &biz.User{Email: "new@example.com"},
+			fields: []string{"Email"},
+			setupMock: func() {
+				user := current()
+				user.EmailVerifiedAt = &verifiedAt
+				user.PhoneVerifiedAt = &verifiedAt
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+				validate.EXPECT().ValidatePartial(gomock.Any(), "Email").Return(nil)
+				repo.EXPECT().FindByEmail(gomock.Any(), "new@example.com").Return(nil, apperrors.ErrUserNotFound)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any(), "Email", "EmailVerifiedAt").Return(nil)
+				changes.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantUser: &biz.User{ID: 1, UserName: "testuser", Phone: "+8615019458680", Email: "new@example.com",
+				PhoneVerifiedAt: &verifiedAt},
+		}, {
+			name:   "未发生变化",
+			update: &biz.User{Phone: "15019458680", PhoneRegion: "CN"},
+			fields: []string{"Phone"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+				// 转换为 E.164 格式后与原来的手机号相同
+				validate.EXPECT().NormalizePhone("15019458680", "CN").Return("+8615019458680", nil)
+			},
+			wantUser: current(),
+		}, {
+			name:   "手机号格式错误",
+			update: &biz.User{Phone: "123"},
+			fields: []string{"Phone"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+				validate.EXPECT().NormalizePhone("123", "").Return("", apperrors.ErrPhoneFormat)
+			},
+			wantErr: apperrors.ErrPhoneFormat,
+		}, {
+			name:   "手机号已被使用",
+			update: &biz.User{Phone: "+8615766498680"},
+			fields: []string{"Phone"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+				validate.EXPECT().NormalizePhone("+8615766498680", "").Return("+8615766498680", nil)
+				validate.EXPECT().ValidatePartial(gomock.Any(), "Phone").Return(nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), "+8615766498680").Return(&biz.User{ID: 2}, nil)
+			},
+			wantErr: apperrors.ErrPhoneAlreadyExists,
+		}, {
+			name:   "不支持的字段",
+			update: &biz.User{UserName: "other"},
+			fields: []string{"UserName"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+			},
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("unsupported_field", "不支持修改的字段: %s", "UserName"),
+		}, {
+			name:   "成功修改昵称、性别和生日",
+			update: &biz.User{Nickname: "小明", Gender: biz.GenderMale, Birthday: &birthday},
+			fields: []string{"Nickname", "Gender", "Birthday"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+				validate.EXPECT().ValidatePartial(gomock.Any(), "Nickname", "Gender", "Birthday").Return(nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any(), "Nickname", "Gender", "Birthday").Return(nil)
+				changes.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, changes []*biz.ProfileChange) error {
+						assert.Len(t, changes, 3)
+						assert.Equal(t, "1", changes[1].NewValue)
+						assert.Equal(t, "", changes[2].OldValue)
+						assert.Equal(t, "1990-05-20", changes[2].NewValue)
+						return nil
+					})
+			},
+			wantUser: &biz.User{ID: 1, UserName: "testuser", Phone: "+8615019458680", Email: "old@example.com",
+				Nickname: "小明", Gender: biz.GenderMale, Birthday: &birthday},
+		}, {
+			name:   "生日晚于今天",
+			update: &biz.User{Birthday: &future},
+			fields: []string{"Birthday"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+			},
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("birthday_range", "生日日期错误"),
+		}, {
+			name:   "性别取值错误",
+			update: &biz.User{Gender: 9},
+			fields: []string{"Gender"},
+			setupMock: func() {
+				repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(current(), nil)
+			},
+			wantErr: apperrors.ErrInvalidArgument.WithMessageKey("gender", "性别取值错误"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			user, err := uc.UpdateProfile(context.Background(), 1, tt.update, tt.fields)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantUser, user)
+		})
+	}
+}
+
+// 修改密码
+func TestUserUsecase_ChangePassword(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	repo := mock.NewMockUserRepo(ctl)
+	changes := mock.NewMockProfileChangeRepo(ctl)
+	passwords := mock.NewMockPasswordHistoryRepo(ctl)
+	validate := mock.NewMockUserValidator(ctl)
+	passwordHash := mock.NewMockPasswordHash(ctl)
+	uc := biz.NewUserUsecase(repo, changes, passwords, validate, passwordHash, newTestLimiter(), newTestAudit(), &conf.Auth{PasswordHistory: 2})
+
+	current := func() *biz.User {
+		return &biz.User{ID: 1, UserName: "testuser", Password: "hashed_current", TokenVersion: 3}
+	}
+
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		setupMock   func()
+		wantVersion uint
+		wantErr     error
+	}{
+		{
+			name:        "成功修改密码",
+			oldPassword: "Current123",
+			newPassword: "Brandnew123",
+			setupMock: func() {
+				repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(current(), nil)
+				passwordHash.EXPECT().Virefy("Current123", "hashed_current").Return(true)
+				validate.EXPECT().ValidatePartial(&biz.User{Password: "Brandnew123", UserName: "testuser"}, "Password").Return(nil)
+				passwords.EXPECT().ListRecent(gomock.Any(), uint(1), 2).Return([]string{"hashed_old1", "hashed_old2"}, nil)
+				passwordHash.EXPECT().Virefy("Brandnew123", gomock.Any()).Return(false).Times(3)
+				passwordHash.EXPECT().Hash("Brandnew123").Return("hashed_new", nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), uint(1), "hashed_new").Return(uint(4), nil)
+				passwords.EXPECT().Create(gomock.Any(), uint(1), "hashed_new").Return(nil)
+			},
+			wantVersion: 4,
+		}, {
+			name:        "旧密码错误",
+			oldPassword: "Wrong123",
+			newPassword: "Brandnew123",
+			setupMock: func() {
+				repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(current(), nil)
+				passwordHash.EXPECT().Virefy("Wrong123", "hashed_current").Return(false)
+			},
+			wantErr: apperrors.ErrPasswordIncorrect,
+		}, {
+			name:        "新密码格式错误",
+			oldPassword: "Current123",
+			newPassword: "weak",
+			setupMock: func() {
+				repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(current(), nil)
+				passwordHash.EXPECT().Virefy("Current123", "hashed_current").Return(true)
+				validate.EXPECT().ValidatePartial(&biz.User{Password: "weak", UserName: "testuser"}, "Password").Return(apperrors.ErrPasswordFormat)
+			},
+			wantErr: apperrors.ErrPasswordFormat,
+		}, {
+			name:        "使用了最近用过的密码",
+			oldPassword: "Current123",
+			newPassword: "Previous123",
+			setupMock: func() {
+				repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(current(), nil)
+				passwordHash.EXPECT().Virefy("Current123", "hashed_current").Return(true)
+				validate.EXPECT().ValidatePartial(&biz.User{Password: "Previous123", UserName: "testuser"}, "Password").Return(nil)
+				passwords.EXPECT().ListRecent(gomock.Any(), uint(1), 2).Return([]string{"hashed_previous"}, nil)
+				passwordHash.EXPECT().Virefy("Previous123", "hashed_current").Return(false)
+				passwordHash.EXPECT().Virefy("Previous123", "hashed_previous").Return(true)
+			},
+			wantErr: apperrors.ErrPasswordReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			user, err := uc.ChangePassword(context.Background(), 1, tt.oldPassword, tt.newPassword)
+			assert.Equal(t, tt.wantErr, err)
+			if err == nil {
+				assert.Equal(t, tt.wantVersion, user.TokenVersion)
+				assert.Equal(t, "hashed_new", user.Password)
+			}
+		})
+	}
+}
@@ -34,10 +34,121 @@ type Server_MySQL struct {
 }
 
 type Auth struct {
-	JwtKey         string   `mapstructure:"jwt_key"`
-	ExpireDuration int64    `mapstructure:"expire_duration"`
-	Algorithm      string   `mapstructure:"algorithm"`
-	Whitelist      []string `mapstructure:"whitelist"`
+	JwtKey                string `mapstructure:"jwt_key"`
+	ExpireDuration        int64  `mapstructure:"expire_duration"`
+	RefreshExpireDuration int64  `mapstructure:"refresh_expire_duration"`
+	PasswordHistory       int    `mapstructure:"password_history"`
+	RequireVerifiedEmail  bool   `mapstructure:"require_verified_email"`
+	MaxLoginFailures      int    `mapstructure:"max_login_failures"`
+	MaxIPLoginFailures    int    `mapstructure:"max_ip_login_failures"`
+	LoginFailureWindow    int64  `mapstructure:"login_failure_window"`
+	LockoutDuration       int64  `mapstructure:"lockout_duration"`
+	MaxLockoutDuration    int64  `mapstructure:"max_lockout_duration"`
+	Algorithm             string `mapstructure:"algorithm"`
+	PrivateKeyFile        string `mapstructure:"private_key_file"`
+	KeyID                 string `mapstructure:"key_id"`
+	// ActiveKeyID 签发新令牌使用的密钥，为空时使用 Keys 中的第一个
+	ActiveKeyID string       `mapstructure:"active_key_id"`
+	Keys        []SigningKey `mapstructure:"keys"`
+	// Services 允许调用内部接口的服务
+	Services []ServiceCredential `mapstructure:"services"`
+}
+
+// ServiceCredential 内部服务的凭证，配置中只保存密钥的 SHA-256，调用方在 authorization 中携带密钥原文
+type ServiceCredential struct {
+	Name    string `mapstructure:"name"`
+	KeyHash string `mapstructure:"key_hash"` // 十六进制
+}
+
+// SigningKey 访问令牌的签名密钥，ID 即令牌头中的 kid。
+// 轮换后的旧密钥可以只配置 PublicKeyFile，用于验证尚未过期的令牌
+type SigningKey struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	Secret         string `mapstructure:"secret"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+type Verification struct {
+	CodeExpireDuration int64 `mapstructure:"code_expire_duration"`
+	MaxAttempts        int   `mapstructure:"max_attempts"`
+	SendInterval       int64 `mapstructure:"send_interval"`
+}
+
+type TwoFactor struct {
+	Issuer                  string `mapstructure:"issuer"`
+	ChallengeExpireDuration int64  `mapstructure:"challenge_expire_duration"`
+	MaxAttempts             int    `mapstructure:"max_attempts"`
+	RecoveryCodes           int    `mapstructure:"recovery_codes"`
+}
+
+// Password 密码哈希参数，Algorithm 为 argon2id 或 bcrypt，
+// 已保存的哈希与当前参数不一致时在用户下次登录时重新计算
+type Password struct {
+	Algorithm         string `mapstructure:"algorithm"`
+	Argon2Memory      uint32 `mapstructure:"argon2_memory"` // KiB
+	Argon2Iterations  uint32 `mapstructure:"argon2_iterations"`
+	Argon2Parallelism uint8  `mapstructure:"argon2_parallelism"`
+	Argon2SaltLength  uint32 `mapstructure:"argon2_salt_length"`
+	Argon2KeyLength   uint32 `mapstructure:"argon2_key_length"`
+	BcryptCost        int    `mapstructure:"bcrypt_cost"`
+	// MinStrength 新密码的最低强度等级，0-4，0 表示只拒绝泄露过的常用密码
+	MinStrength int `mapstructure:"min_strength"`
+}
+
+type Notifier struct {
+	Type string `mapstructure:"type"`
+	Path string `mapstructure:"path"`
+}
+
+// Storage 文件存储，目前只支持本地目录。BaseURL 为文件的访问地址前缀，
+// 以 / 开头时由 HTTP 服务直接提供 LocalDir 中的文件
+type Storage struct {
+	LocalDir string `mapstructure:"local_dir"`
+	BaseURL  string `mapstructure:"base_url"`
+}
+
+// Avatar 头像上传限制
+type Avatar struct {
+	MaxSize       int64 `mapstructure:"max_size"`       // 字节
+	ThumbnailSize int   `mapstructure:"thumbnail_size"` // 缩略图边长，像素
+}
+
+// Validation 用户资料的校验规则，没有配置的项使用 LoadConfig 中的默认值
+type Validation struct {
+	Username *UsernameRule `mapstructure:"username"`
+	Password *PasswordRule `mapstructure:"password"`
+	Phone    *Phone        `mapstructure:"phone"`
+	// NicknameMaxLength 昵称的最大字符数
+	NicknameMaxLength int `mapstructure:"nickname_max_length"`
+}
+
+// UsernameRule 用户名只能包含字母、数字和下划线，且不能是手机号
+type UsernameRule struct {
+	MinLength int `mapstructure:"min_length"`
+	MaxLength int `mapstructure:"max_length"`
+}
+
+// PasswordRule 密码的长度和必须包含的字符，密码强度由 Password.MinStrength 控制
+type PasswordRule struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+}
+
+// Phone 手机号规则，号码统一保存为 E.164 格式
+type Phone struct {
+	DefaultRegion string   `mapstructure:"default_region"` // 没有国家码的号码按该地区解析
+	Regions       []string `mapstructure:"regions"`        // 允许的国家和地区，为空时允许所有支持的地区
+}
+
+// Internal 内部服务接口的限制
+type Internal struct {
+	MaxBatchSize int `mapstructure:"max_batch_size"` // BatchGetUsers 一次最多查询的用户数
 }
 
 type Log struct {
@@ -48,6 +159,13 @@ type Log struct {
 type Data struct {
 	MySQL *Server_MySQL `mapstructure:"mysql"`
 	Redis *Server_Redis `mapstructure:"redis"`
+	Cache *Cache        `mapstructure:"cache"`
+}
+
+// Cache 按 ID 查询用户时使用 Redis 缓存，UserTTL 为 0 时不使用缓存
+type Cache struct {
+	UserTTL     int64 `mapstructure:"user_ttl"`      // 秒
+	NotFoundTTL int64 `mapstructure:"not_found_ttl"` // 用户不存在时的缓存时间，秒
 }
 
 type Server_Admin struct {
@@ -59,11 +177,22 @@ type Server struct {
 	HTTP  *Server_HTTP  `mapstructure:"http"`
 	GRPC  *Server_GRPC  `mapstructure:"grpc"`
 	Admin *Server_Admin `mapstructure:"admin"`
+	// TrustedProxies 可信代理的 IP 或 CIDR，只有来自这些地址的连接才读取 x-forwarded-for，
+	// 默认只信任本机，即同进程内的 gateway
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type Bootstrap struct {
-	Server *Server `mapstructure:"server"`
-	Data   *Data   `mapstructure:"data"`
-	Auth   *Auth   `mapstructure:"auth"`
-	Log    *Log    `mapstructure:"log"`
+	Server       *Server       `mapstructure:"server"`
+	Data         *Data         `mapstructure:"data"`
+	Auth         *Auth         `mapstructure:"auth"`
+	Verification *Verification `mapstructure:"verification"`
+	TwoFactor    *TwoFactor    `mapstructure:"two_factor"`
+	Password     *Password     `mapstructure:"password"`
+	Notifier     *Notifier     `mapstructure:"notifier"`
+	Storage      *Storage      `mapstructure:"storage"`
+	Avatar       *Avatar       `mapstructure:"avatar"`
+	Validation   *Validation   `mapstructure:"validation"`
+	Internal     *Internal     `mapstructure:"internal"`
+	Log          *Log          `mapstructure:"log"`
 }
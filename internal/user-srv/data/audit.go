@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+)
+
+// AuditEventPO 审计事件只追加，不更新也不删除，所以没有使用 gorm.Model
+type AuditEventPO struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index:idx_audit_user_created,priority:1"`
+	ActorID   uint      `gorm:"index"`
+	Event     string    `gorm:"type:varchar(64);index"`
+	TraceID   string    `gorm:"type:varchar(64)"`
+	ClientIP  string    `gorm:"type:varchar(64)"`
+	UserAgent string    `gorm:"type:varchar(255)"`
+	Detail    string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"index:idx_audit_user_created,priority:2"`
+}
+
+func (AuditEventPO) TableName() string {
+	return "user_audit_events"
+}
+
+func (po AuditEventPO) toBizAuditEvent() *biz.AuditEvent {
+	return &biz.AuditEvent{
+		ID:        po.ID,
+		UserID:    po.UserID,
+		ActorID:   po.ActorID,
+		Event:     logevent.Event(po.Event),
+		TraceID:   po.TraceID,
+		ClientIP:  po.ClientIP,
+		UserAgent: po.UserAgent,
+		Detail:    po.Detail,
+		CreatedAt: po.CreatedAt,
+	}
+}
+
+// truncate 按字符截断，避免超出列长度
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+type AuditRepo struct {
+	data *Data
+}
+
+func NewAuditRepo(data *Data) biz.AuditRepo {
+	return &AuditRepo{data: data}
+}
+
+func (r *AuditRepo) Append(ctx context.Context, event *biz.AuditEvent) error {
+	po := &AuditEventPO{
+		UserID:    event.UserID,
+		ActorID:   event.ActorID,
+		Event:     event.Event.String(),
+		TraceID:   truncate(event.TraceID, 64),
+		ClientIP:  truncate(event.ClientIP, 64),
+		UserAgent: truncate(event.UserAgent, 255),
+		Detail:    truncate(event.Detail, 255),
+		CreatedAt: event.CreatedAt,
+	}
+	if err := r.data.db.WithContext(ctx).Create(po).Error; err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+	event.ID = po.ID
+	return nil
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter *biz.AuditFilter) ([]*biz.AuditEvent, int64, error) {
+	query := r.data.db.WithContext(ctx).Model(&AuditEventPO{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event.String())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	var pos []AuditEventPO
+	if err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&pos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	events := make([]*biz.AuditEvent, 0, len(pos))
+	for _, po := range pos {
+		events = append(events, po.toBizAuditEvent())
+	}
+	return events, total, nil
+}
+
+// MemoryAuditRepo 基于内存的实现，用于测试
+type MemoryAuditRepo struct {
+	mu     sync.RWMutex
+	events []biz.AuditEvent
+}
+
+func NewMemoryAuditRepo() biz.AuditRepo {
+	return &MemoryAuditRepo{}
+}
+
+func (r *MemoryAuditRepo) Append(ctx context.Context, event *biz.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = uint(len(r.events) + 1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *MemoryAuditRepo) List(ctx context.Context, filter *biz.AuditFilter) ([]*biz.AuditEvent, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*biz.AuditEvent
+	for _, e := range slices.Backward(r.events) {
+		if (filter.UserID != 0 && e.UserID != filter.UserID) ||
+			(filter.ActorID != 0 && e.ActorID != filter.ActorID) ||
+			(filter.Event != "" && e.Event != filter.Event) {
+			continue
+		}
+		matched = append(matched, &e)
+	}
+	total := int64(len(matched))
+	start := min(filter.Offset, len(matched))
+	end := len(matched)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(matched))
+	}
+	return matched[start:end], total, nil
+}
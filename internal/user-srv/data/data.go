@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
@@ -13,7 +14,8 @@ import (
 
 // Data struct definition
 type Data struct {
-	db *gorm.DB
+	db  *gorm.DB
+	rdb *redis.Client
 }
 
 func (d *Data) WithContext(ctx context.Context) {
@@ -41,8 +43,18 @@ func NewData(s *conf.Data) (*Data, func(), error) {
 	sqlDB.SetMaxOpenConns(s.MySQL.MaxOpenConns)                                // 设置数据库的最大连接数
 	sqlDB.SetConnMaxLifetime(time.Duration(s.MySQL.MaxLifetime) * time.Second) // 设置连接的最大可复用时间
 
+	// 初始化 Redis
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", s.Redis.Host, s.Redis.Port),
+		Password: s.Redis.Password,
+		DB:       s.Redis.DB,
+	})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
 	// Return a cleanup function to close the database connection
-	sqlcleanup := func() {
+	cleanup := func() {
 		sqlDB, err := db.DB()
 		if err == nil {
 			err = sqlDB.Close()
@@ -50,9 +62,13 @@ func NewData(s *conf.Data) (*Data, func(), error) {
 				panic("关闭 MySql 错误")
 			}
 		}
+		if err := rdb.Close(); err != nil {
+			panic("关闭 Redis 错误")
+		}
 	}
 
 	return &Data{
-		db: db,
-	}, sqlcleanup, nil
+		db:  db,
+		rdb: rdb,
+	}, cleanup, nil
 }
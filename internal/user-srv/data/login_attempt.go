@@ -0,0 +1,193 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+)
+
+const (
+	loginFailuresKeyPrefix = "login:failures:"
+	loginLockKeyPrefix     = "login:lock:"
+)
+
+// NewLoginAttemptRepo 优先使用 Redis，Redis 不可用时退化为单机内存计数，保证登录不受影响
+func NewLoginAttemptRepo(data *Data, log *zap.Logger) biz.LoginAttemptRepo {
+	return &FallbackLoginAttemptRepo{
+		primary:  NewRedisLoginAttemptRepo(data),
+		fallback: NewMemoryLoginAttemptRepo(),
+		log:      log,
+	}
+}
+
+type RedisLoginAttemptRepo struct {
+	data *Data
+}
+
+func NewRedisLoginAttemptRepo(data *Data) biz.LoginAttemptRepo {
+	return &RedisLoginAttemptRepo{data: data}
+}
+
+func (r *RedisLoginAttemptRepo) IncrFailures(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := r.data.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, loginFailuresKeyPrefix+key)
+	pipe.Expire(ctx, loginFailuresKeyPrefix+key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increase login failures: %w", err)
+	}
+	return int(incr.Val()), nil
+}
+
+func (r *RedisLoginAttemptRepo) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := r.data.rdb.Set(ctx, loginLockKeyPrefix+key, 1, d).Err(); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisLoginAttemptRepo) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.data.rdb.PTTL(ctx, loginLockKeyPrefix+key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login lock: %w", err)
+	}
+	// key 不存在时返回 -2，没有过期时间时返回 -1
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *RedisLoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	if err := r.data.rdb.Del(ctx, loginFailuresKeyPrefix+key, loginLockKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+// MemoryLoginAttemptRepo 基于内存的实现，用于测试以及 Redis 不可用时的降级
+type MemoryLoginAttemptRepo struct {
+	mu       sync.Mutex
+	failures map[string]*memoryCounter
+	locks    map[string]time.Time
+	now      func() time.Time
+}
+
+type memoryCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+func NewMemoryLoginAttemptRepo() biz.LoginAttemptRepo {
+	return &MemoryLoginAttemptRepo{
+		failures: make(map[string]*memoryCounter),
+		locks:    make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (r *MemoryLoginAttemptRepo) IncrFailures(ctx context.Context, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	counter, ok := r.failures[key]
+	if !ok || !now.Before(counter.expiresAt) {
+		counter = &memoryCounter{}
+		r.failures[key] = counter
+	}
+	counter.count++
+	counter.expiresAt = now.Add(window)
+	return counter.count, nil
+}
+
+func (r *MemoryLoginAttemptRepo) Lock(ctx context.Context, key string, d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locks[key] = r.now().Add(d)
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepo) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.locks[key]
+	if !ok {
+		return 0, nil
+	}
+	d := until.Sub(r.now())
+	if d <= 0 {
+		delete(r.locks, key)
+		return 0, nil
+	}
+	return d, nil
+}
+
+func (r *MemoryLoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.failures, key)
+	delete(r.locks, key)
+	return nil
+}
+
+// FallbackLoginAttemptRepo primary 出错时改用 fallback，
+// 降级期间计数只在单个实例内生效，Redis 恢复后继续使用 Redis 中的计数
+type FallbackLoginAttemptRepo struct {
+	primary  biz.LoginAttemptRepo
+	fallback biz.LoginAttemptRepo
+	log      *zap.Logger
+}
+
+func (r *FallbackLoginAttemptRepo) warn(op string, err error) {
+	r.log.Warn("login attempt store unavailable, using in-memory fallback",
+		zap.String("op", op), zap.Error(err))
+}
+
+func (r *FallbackLoginAttemptRepo) IncrFailures(ctx context.Context, key string, window time.Duration) (int, error) {
+	n, err := r.primary.IncrFailures(ctx, key, window)
+	if err != nil {
+		r.warn("IncrFailures", err)
+		return r.fallback.IncrFailures(ctx, key, window)
+	}
+	return n, nil
+}
+
+func (r *FallbackLoginAttemptRepo) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := r.primary.Lock(ctx, key, d); err != nil {
+		r.warn("Lock", err)
+		return r.fallback.Lock(ctx, key, d)
+	}
+	return nil
+}
+
+func (r *FallbackLoginAttemptRepo) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	d, err := r.primary.LockedFor(ctx, key)
+	if err != nil {
+		r.warn("LockedFor", err)
+		return r.fallback.LockedFor(ctx, key)
+	}
+	// 降级期间产生的锁定在 Redis 恢复后仍然有效
+	local, err := r.fallback.LockedFor(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return max(d, local), nil
+}
+
+func (r *FallbackLoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	if err := r.fallback.Reset(ctx, key); err != nil {
+		return err
+	}
+	if err := r.primary.Reset(ctx, key); err != nil {
+		r.warn("Reset", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+)
+
+type PasswordHistoryPO struct {
+	UserID       uint `gorm:"index"`
+	PasswordHash string
+	gorm.Model
+}
+
+func (PasswordHistoryPO) TableName() string {
+	return "user_password_history"
+}
+
+type PasswordHistoryRepo struct {
+	data *Data
+}
+
+func NewPasswordHistoryRepo(data *Data) biz.PasswordHistoryRepo {
+	return &PasswordHistoryRepo{data: data}
+}
+
+func (r *PasswordHistoryRepo) Create(ctx context.Context, userID uint, passwordHash string) error {
+	po := &PasswordHistoryPO{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}
+	if err := r.data.db.WithContext(ctx).Create(po).Error; err != nil {
+		return fmt.Errorf("failed to create password history: %w", err)
+	}
+	return nil
+}
+
+func (r *PasswordHistoryRepo) ListRecent(ctx context.Context, userID uint, n int) ([]string, error) {
+	var hashes []string
+	err := r.data.db.WithContext(ctx).Model(&PasswordHistoryPO{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(n).
+		Pluck("password_hash", &hashes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list password history: %w", err)
+	}
+	return hashes, nil
+}
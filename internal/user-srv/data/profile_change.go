@@ -0,0 +1,56 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+)
+
+type ProfileChangePO struct {
+	UserID    uint   `gorm:"index"`
+	Field     string `gorm:"type:varchar(32)"`
+	OldValue  string
+	NewValue  string
+	ChangedBy uint
+	Reason    string `gorm:"type:varchar(255)"`
+	ChangedAt time.Time
+	gorm.Model
+}
+
+func (ProfileChangePO) TableName() string {
+	return "user_profile_changes"
+}
+
+type ProfileChangeRepo struct {
+	data *Data
+}
+
+func NewProfileChangeRepo(data *Data) biz.ProfileChangeRepo {
+	return &ProfileChangeRepo{data: data}
+}
+
+func (r *ProfileChangeRepo) Create(ctx context.Context, changes []*biz.ProfileChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	pos := make([]*ProfileChangePO, 0, len(changes))
+	for _, c := range changes {
+		pos = append(pos, &ProfileChangePO{
+			UserID:    c.UserID,
+			Field:     c.Field,
+			OldValue:  c.OldValue,
+			NewValue:  c.NewValue,
+			ChangedBy: c.ChangedBy,
+			Reason:    c.Reason,
+			ChangedAt: c.ChangedAt,
+		})
+	}
+	if err := r.data.db.WithContext(ctx).Create(&pos).Error; err != nil {
+		return fmt.Errorf("failed to record profile changes: %w", err)
+	}
+	return nil
+}
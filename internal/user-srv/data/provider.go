@@ -2,4 +2,17 @@ package data
 
 import "github.com/google/wire"
 
-var ProviderSet = wire.NewSet(NewData, NewUserRepo)
+var ProviderSet = wire.NewSet(
+	NewData,
+	NewUserRepo,
+	NewRefreshTokenRepo,
+	NewRedisRevocationList,
+	NewProfileChangeRepo,
+	NewPasswordHistoryRepo,
+	NewRedisVerificationCodeRepo,
+	NewLoginAttemptRepo,
+	NewAuditRepo,
+	NewSessionRepo,
+	NewTwoFactorRepo,
+	NewLocalBlobStore,
+)
@@ -0,0 +1,175 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type RefreshTokenPO struct {
+	UserID    uint   `gorm:"index"`
+	FamilyID  string `gorm:"type:varchar(36);index"`
+	TokenHash string `gorm:"type:char(64);uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	gorm.Model
+}
+
+func (RefreshTokenPO) TableName() string {
+	return "refresh_tokens"
+}
+
+func (po RefreshTokenPO) toBizRefreshToken() *biz.RefreshToken {
+	return &biz.RefreshToken{
+		ID:        po.ID,
+		UserID:    po.UserID,
+		FamilyID:  po.FamilyID,
+		TokenHash: po.TokenHash,
+		ExpiresAt: po.ExpiresAt,
+		UsedAt:    po.UsedAt,
+		RevokedAt: po.RevokedAt,
+	}
+}
+
+type RefreshTokenRepo struct {
+	data *Data
+}
+
+func NewRefreshTokenRepo(data *Data) biz.RefreshTokenRepo {
+	return &RefreshTokenRepo{data: data}
+}
+
+func (r *RefreshTokenRepo) Create(ctx context.Context, token *biz.RefreshToken) error {
+	po := &RefreshTokenPO{
+		UserID:    token.UserID,
+		FamilyID:  token.FamilyID,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if err := r.data.db.WithContext(ctx).Create(po).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	token.ID = po.ID
+	return nil
+}
+
+func (r *RefreshTokenRepo) FindByHash(ctx context.Context, tokenHash string) (*biz.RefreshToken, error) {
+	var po RefreshTokenPO
+	if err := r.data.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&po).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrRefreshTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	return po.toBizRefreshToken(), nil
+}
+
+func (r *RefreshTokenRepo) MarkUsed(ctx context.Context, id uint, usedAt time.Time) (bool, error) {
+	// 带条件的 UPDATE，保证并发轮换时只有一个请求能成功
+	result := r.data.db.WithContext(ctx).Model(&RefreshTokenPO{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	err := r.data.db.WithContext(ctx).Model(&RefreshTokenPO{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeByUser(ctx context.Context, userID uint, revokedAt time.Time) error {
+	err := r.data.db.WithContext(ctx).Model(&RefreshTokenPO{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of user: %w", err)
+	}
+	return nil
+}
+
+// MemoryRefreshTokenRepo 基于内存的实现，用于测试
+type MemoryRefreshTokenRepo struct {
+	mu     sync.Mutex
+	nextID uint
+	tokens map[uint]*biz.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepo() biz.RefreshTokenRepo {
+	return &MemoryRefreshTokenRepo{tokens: make(map[uint]*biz.RefreshToken)}
+}
+
+func (r *MemoryRefreshTokenRepo) Create(ctx context.Context, token *biz.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	token.ID = r.nextID
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepo) FindByHash(ctx context.Context, tokenHash string) (*biz.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, apperrors.ErrRefreshTokenInvalid
+}
+
+func (r *MemoryRefreshTokenRepo) MarkUsed(ctx context.Context, id uint, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	return true, nil
+}
+
+func (r *MemoryRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepo) RevokeByUser(ctx context.Context, userID uint, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
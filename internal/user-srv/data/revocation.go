@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/auth"
+)
+
+const revokedTokenKeyPrefix = "auth:revoked:"
+
+type RedisRevocationList struct {
+	data *Data
+}
+
+func NewRedisRevocationList(data *Data) auth.RevocationList {
+	return &RedisRevocationList{data: data}
+}
+
+func (r *RedisRevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	// 令牌已经过期了，没有必要再记录
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := r.data.rdb.Set(ctx, revokedTokenKeyPrefix+jti, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisRevocationList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.data.rdb.Exists(ctx, revokedTokenKeyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return n > 0, nil
+}
+
+// MemoryRevocationList 基于内存的实现，用于测试
+type MemoryRevocationList struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+func NewMemoryRevocationList() auth.RevocationList {
+	return &MemoryRevocationList{revoked: make(map[string]time.Time)}
+}
+
+func (r *MemoryRevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil
+	}
+	// 顺便清理已经过期的记录
+	for k, exp := range r.revoked {
+		if !exp.After(now) {
+			delete(r.revoked, k)
+		}
+	}
+	r.revoked[jti] = expiresAt
+	return nil
+}
+
+func (r *MemoryRevocationList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exp, ok := r.revoked[jti]
+	if !ok {
+		return false, nil
+	}
+	if !exp.After(time.Now()) {
+		delete(r.revoked, jti)
+		return false, nil
+	}
+	return true, nil
+}
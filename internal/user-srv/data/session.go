@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type SessionPO struct {
+	ID         string `gorm:"type:varchar(36);primaryKey"`
+	UserID     uint   `gorm:"index"`
+	DeviceName string `gorm:"type:varchar(64)"`
+	ClientIP   string `gorm:"type:varchar(64)"`
+	UserAgent  string `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+func (SessionPO) TableName() string {
+	return "user_sessions"
+}
+
+func (po SessionPO) toBizSession() *biz.Session {
+	return &biz.Session{
+		ID:         po.ID,
+		UserID:     po.UserID,
+		DeviceName: po.DeviceName,
+		ClientIP:   po.ClientIP,
+		UserAgent:  po.UserAgent,
+		CreatedAt:  po.CreatedAt,
+		LastSeenAt: po.LastSeenAt,
+		RevokedAt:  po.RevokedAt,
+	}
+}
+
+type SessionRepo struct {
+	data *Data
+}
+
+func NewSessionRepo(data *Data) biz.SessionRepo {
+	return &SessionRepo{data: data}
+}
+
+func (r *SessionRepo) Create(ctx context.Context, session *biz.Session) error {
+	po := &SessionPO{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		DeviceName: truncate(session.DeviceName, 64),
+		ClientIP:   truncate(session.ClientIP, 64),
+		UserAgent:  truncate(session.UserAgent, 255),
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+	}
+	if err := r.data.db.WithContext(ctx).Create(po).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepo) FindByID(ctx context.Context, id string) (*biz.Session, error) {
+	var po SessionPO
+	if err := r.data.db.WithContext(ctx).Where("id = ?", id).First(&po).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+	return po.toBizSession(), nil
+}
+
+func (r *SessionRepo) ListByUser(ctx context.Context, userID uint) ([]*biz.Session, error) {
+	var pos []SessionPO
+	err := r.data.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Find(&pos).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions := make([]*biz.Session, 0, len(pos))
+	for _, po := range pos {
+		sessions = append(sessions, po.toBizSession())
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepo) Touch(ctx context.Context, id string, lastSeenAt time.Time) error {
+	err := r.data.db.WithContext(ctx).Model(&SessionPO{}).
+		Where("id = ?", id).
+		Update("last_seen_at", lastSeenAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	err := r.data.db.WithContext(ctx).Model(&SessionPO{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// MemorySessionRepo 基于内存的实现，用于测试
+type MemorySessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]*biz.Session
+}
+
+func NewMemorySessionRepo() biz.SessionRepo {
+	return &MemorySessionRepo{sessions: make(map[string]*biz.Session)}
+}
+
+func (r *MemorySessionRepo) Create(ctx context.Context, session *biz.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *MemorySessionRepo) FindByID(ctx context.Context, id string) (*biz.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *MemorySessionRepo) ListByUser(ctx context.Context, userID uint) ([]*biz.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sessions []*biz.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *MemorySessionRepo) Touch(ctx context.Context, id string, lastSeenAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok {
+		session.LastSeenAt = lastSeenAt
+	}
+	return nil
+}
+
+func (r *MemorySessionRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+type TwoFactorPO struct {
+	UserID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	Secret       string `gorm:"type:varchar(64);not null"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (TwoFactorPO) TableName() string {
+	return "user_two_factors"
+}
+
+type RecoveryCodePO struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"index"`
+	CodeHash string `gorm:"type:char(64)"`
+	UsedAt   *time.Time
+}
+
+func (RecoveryCodePO) TableName() string {
+	return "user_recovery_codes"
+}
+
+type TwoFactorRepo struct {
+	data *Data
+}
+
+func NewTwoFactorRepo(data *Data) biz.TwoFactorRepo {
+	return &TwoFactorRepo{data: data}
+}
+
+func (r *TwoFactorRepo) Find(ctx context.Context, userID uint) (*biz.TwoFactor, error) {
+	var po TwoFactorPO
+	if err := r.data.db.WithContext(ctx).Where("user_id = ?", userID).First(&po).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrTwoFactorNotEnabled
+		}
+		return nil, fmt.Errorf("failed to find two factor: %w", err)
+	}
+	return &biz.TwoFactor{
+		UserID:       po.UserID,
+		Secret:       po.Secret,
+		ConfirmedAt:  po.ConfirmedAt,
+		LastUsedStep: po.LastUsedStep,
+	}, nil
+}
+
+func (r *TwoFactorRepo) Save(ctx context.Context, tf *biz.TwoFactor) error {
+	po := &TwoFactorPO{
+		UserID:       tf.UserID,
+		Secret:       tf.Secret,
+		ConfirmedAt:  tf.ConfirmedAt,
+		LastUsedStep: tf.LastUsedStep,
+	}
+	err := r.data.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(po).Error
+	if err != nil {
+		return fmt.Errorf("failed to save two factor: %w", err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepo) Confirm(ctx context.Context, userID uint, confirmedAt time.Time, recoveryCodeHashes []string) error {
+	err := r.data.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TwoFactorPO{}).Where("user_id = ?", userID).
+			Update("confirmed_at", confirmedAt).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCodePO{}).Error; err != nil {
+			return err
+		}
+		if len(recoveryCodeHashes) == 0 {
+			return nil
+		}
+		pos := make([]*RecoveryCodePO, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			pos = append(pos, &RecoveryCodePO{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&pos).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to confirm two factor: %w", err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepo) Delete(ctx context.Context, userID uint) error {
+	err := r.data.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCodePO{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&TwoFactorPO{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete two factor: %w", err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepo) UseStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	// 带条件的 UPDATE，保证同一个动态验证码在并发请求中也只能使用一次
+	result := r.data.db.WithContext(ctx).Model(&TwoFactorPO{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use totp step: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepo) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.data.db.WithContext(ctx).Model(&RecoveryCodePO{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MemoryTwoFactorRepo 基于内存的实现，用于测试
+type MemoryTwoFactorRepo struct {
+	mu            sync.Mutex
+	factors       map[uint]*biz.TwoFactor
+	recoveryCodes map[uint]map[string]bool // userID -> 哈希 -> 是否已使用
+}
+
+func NewMemoryTwoFactorRepo() biz.TwoFactorRepo {
+	return &MemoryTwoFactorRepo{
+		factors:       make(map[uint]*biz.TwoFactor),
+		recoveryCodes: make(map[uint]map[string]bool),
+	}
+}
+
+func (r *MemoryTwoFactorRepo) Find(ctx context.Context, userID uint) (*biz.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tf, ok := r.factors[userID]
+	if !ok {
+		return nil, apperrors.ErrTwoFactorNotEnabled
+	}
+	found := *tf
+	return &found, nil
+}
+
+func (r *MemoryTwoFactorRepo) Save(ctx context.Context, tf *biz.TwoFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *tf
+	r.factors[tf.UserID] = &stored
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) Confirm(ctx context.Context, userID uint, confirmedAt time.Time, recoveryCodeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tf, ok := r.factors[userID]; ok {
+		tf.ConfirmedAt = &confirmedAt
+	}
+	codes := make(map[string]bool, len(recoveryCodeHashes))
+	for _, hash := range recoveryCodeHashes {
+		codes[hash] = false
+	}
+	r.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) Delete(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.factors, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) UseStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tf, ok := r.factors[userID]
+	if !ok || tf.LastUsedStep >= step {
+		return false, nil
+	}
+	tf.LastUsedStep = step
+	return true, nil
+}
+
+func (r *MemoryTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	used, ok := r.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
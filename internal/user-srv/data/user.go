@@ -2,12 +2,18 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	gomysql "github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 
 	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/phone"
 )
 
 type UserPO struct {
@@ -16,6 +22,29 @@ type UserPO struct {
 	Password string
 	Email    string
 	Phone    string
+	// 规范化后的用户名、邮箱、手机号，由唯一索引保证不重复。
+	// 为空或用户被删除时为 NULL，不占用唯一索引，恢复用户时重新计算
+	UserNameKey *string `gorm:"type:varchar(64);uniqueIndex:uk_users_user_name_key"`
+	EmailKey    *string `gorm:"type:varchar(255);uniqueIndex:uk_users_email_key"`
+	PhoneKey    *string `gorm:"type:varchar(32);uniqueIndex:uk_users_phone_key"`
+	// 个人资料
+	Nickname string     `gorm:"type:varchar(32)"`
+	Gender   int8       `gorm:"not null;default:0"`
+	Birthday *time.Time `gorm:"type:date"`
+	// 头像在 BlobStore 中的 key 以及原图、缩略图的访问地址
+	AvatarKey          string `gorm:"type:varchar(255)"`
+	AvatarURL          string `gorm:"type:varchar(512)"`
+	AvatarThumbnailURL string `gorm:"type:varchar(512)"`
+	// 令牌版本，修改密码时递增
+	TokenVersion uint `gorm:"not null;default:0"`
+	// 邮箱、手机号的验证时间，NULL 表示未验证
+	EmailVerifiedAt *time.Time
+	PhoneVerifiedAt *time.Time
+	// 用户角色，多个角色用逗号分隔
+	Roles string `gorm:"type:varchar(255);not null;default:customer"`
+	// 被禁用的时间和原因，NULL 表示正常
+	DisabledAt     *time.Time
+	DisabledReason string `gorm:"type:varchar(255)"`
 	gorm.Model
 }
 
@@ -27,8 +56,13 @@ type UserRepo struct {
 	data *Data
 }
 
-func NewUserRepo(data *Data) biz.UserRepo {
-	return &UserRepo{data: data}
+// NewUserRepo 配置了 Cache.UserTTL 时按 ID 查询用户使用 Redis 缓存
+func NewUserRepo(data *Data, c *conf.Data) biz.UserRepo {
+	repo := &UserRepo{data: data}
+	if c.Cache == nil || c.Cache.UserTTL <= 0 {
+		return repo
+	}
+	return NewCachedUserRepo(repo, data.rdb, c.Cache)
 }
 
 func (po UserPO) toBizUser() *biz.User {
@@ -38,26 +72,255 @@ func (po UserPO) toBizUser() *biz.User {
 		Password: po.Password,
 		Phone:    po.Phone,
 		Email:    po.Email,
+
+		Nickname:           po.Nickname,
+		Gender:             biz.Gender(po.Gender),
+		Birthday:           po.Birthday,
+		AvatarKey:          po.AvatarKey,
+		AvatarURL:          po.AvatarURL,
+		AvatarThumbnailURL: po.AvatarThumbnailURL,
+
+		TokenVersion:    po.TokenVersion,
+		EmailVerifiedAt: po.EmailVerifiedAt,
+		PhoneVerifiedAt: po.PhoneVerifiedAt,
+		Roles:           splitRoles(po.Roles),
+		DisabledAt:      po.DisabledAt,
+		DisabledReason:  po.DisabledReason,
+		CreatedAt:       po.CreatedAt,
+		DeletedAt:       deletedAt(po.DeletedAt),
+	}
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
+
+func splitRoles(roles string) []string {
+	if roles == "" {
+		return nil
+	}
+	return strings.Split(roles, ",")
+}
+
+// 用户名不区分大小写，邮箱按小写比较
+func usernameKey(username string) *string {
+	return nullableKey(strings.ToLower(strings.TrimSpace(username)))
+}
+
+func emailKey(email string) *string {
+	return nullableKey(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func phoneKey(phone string) *string {
+	return nullableKey(strings.TrimSpace(phone))
+}
+
+func nullableKey(key string) *string {
+	if key == "" {
+		return nil
+	}
+	return &key
+}
+
+// userKeyExprs 在数据库中计算规范化的列，规则与 usernameKey 等函数一致
+var userKeyExprs = map[string]any{
+	"user_name_key": gorm.Expr("NULLIF(LOWER(TRIM(user_name)), '')"),
+	"email_key":     gorm.Expr("NULLIF(LOWER(TRIM(email)), '')"),
+	"phone_key":     gorm.Expr("NULLIF(TRIM(phone), '')"),
+}
+
+// MySQL 唯一索引冲突的错误码
+const mysqlDuplicateEntry = 1062
+
+// translateDuplicateError 把唯一索引冲突转换为 ErrUserAlreadyExists，并说明是哪个字段重复
+func translateDuplicateError(err error) error {
+	var mysqlErr *gomysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return nil
+	}
+	switch {
+	case strings.Contains(mysqlErr.Message, "uk_users_user_name_key"):
+		return apperrors.ErrUserAlreadyExists.WithMessageKey("username", "用户名已存在")
+	case strings.Contains(mysqlErr.Message, "uk_users_email_key"):
+		return apperrors.ErrUserAlreadyExists.WithMessageKey("email", "邮箱已被使用")
+	case strings.Contains(mysqlErr.Message, "uk_users_phone_key"):
+		return apperrors.ErrUserAlreadyExists.WithMessageKey("phone", "手机号已被使用")
+	default:
+		return apperrors.ErrUserAlreadyExists
+	}
+}
+
+// BackfillUserKeys 为添加唯一索引之前创建的用户计算规范化的列，
+// 已有数据中存在重复时返回错误，需要先人工处理重复的账号
+func BackfillUserKeys(db *gorm.DB) error {
+	err := db.Model(&UserPO{}).
+		Where("user_name_key IS NULL AND email_key IS NULL AND phone_key IS NULL").
+		Updates(userKeyExprs).Error
+	if err != nil {
+		if dup := translateDuplicateError(err); dup != nil {
+			return fmt.Errorf("failed to backfill user keys: %w: %w", dup, err)
+		}
+		return fmt.Errorf("failed to backfill user keys: %w", err)
+	}
+	return nil
+}
+
+// MigratePhonesToE164 把保存为本地格式的手机号按 defaultRegion 转换为 E.164 格式，包括已删除的用户。
+// 已删除的用户只转换 phone，phone_key 保持为 NULL，恢复时由 Restore 重新计算。
+// 无法解析的号码保持不变，用户下次修改手机号时需要重新填写
+func MigratePhonesToE164(db *gorm.DB, defaultRegion string) error {
+	parser, err := phone.NewParser(defaultRegion)
+	if err != nil {
+		return fmt.Errorf("failed to migrate phones: %w", err)
+	}
+	var users []UserPO
+	err = db.Unscoped().Select("id", "phone", "deleted_at").
+		Where("phone <> '' AND phone NOT LIKE '+%'").
+		FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+			for _, po := range users {
+				n, err := parser.Parse(po.Phone, "")
+				if err != nil {
+					continue
+				}
+				e164 := n.E164()
+				updates := map[string]any{"phone": e164}
+				if !po.DeletedAt.Valid {
+					updates["phone_key"] = phoneKey(e164)
+				}
+				err = tx.Model(&UserPO{}).Unscoped().Where("id = ?", po.ID).Updates(updates).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		if dup := translateDuplicateError(err); dup != nil {
+			return fmt.Errorf("failed to migrate phones: %w: %w", dup, err)
+		}
+		return fmt.Errorf("failed to migrate phones: %w", err)
 	}
+	return nil
 }
 
+// Create 并发注册相同的用户名、邮箱或手机号时由唯一索引保证只有一个成功
 func (r *UserRepo) Create(ctx context.Context, user *biz.User) (*biz.User, error) {
 	po := &UserPO{
-		UserName: user.UserName,
-		Password: user.Password,
-		Phone:    user.Phone,
-		Email:    user.Email,
+		UserName:    user.UserName,
+		Password:    user.Password,
+		Phone:       user.Phone,
+		Email:       user.Email,
+		UserNameKey: usernameKey(user.UserName),
+		EmailKey:    emailKey(user.Email),
+		PhoneKey:    phoneKey(user.Phone),
+		Roles:       strings.Join(user.Roles, ","),
 	}
 	if err := r.data.db.WithContext(ctx).Create(po).Error; err != nil {
+		if dup := translateDuplicateError(err); dup != nil {
+			return nil, dup
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 	user.ID = po.ID
 	return user, nil
 }
 
+// biz.User 字段名到数据库列名的映射
+var userColumns = map[string]string{
+	"UserName": "user_name",
+	"Password": "password",
+	"Email":    "email",
+	"Phone":    "phone",
+
+	"Nickname":           "nickname",
+	"Gender":             "gender",
+	"Birthday":           "birthday",
+	"AvatarKey":          "avatar_key",
+	"AvatarURL":          "avatar_url",
+	"AvatarThumbnailURL": "avatar_thumbnail_url",
+
+	"TokenVersion":    "token_version",
+	"EmailVerifiedAt": "email_verified_at",
+	"PhoneVerifiedAt": "phone_verified_at",
+	"Roles":           "roles",
+	"DisabledAt":      "disabled_at",
+	"DisabledReason":  "disabled_reason",
+}
+
+var userKeyColumns = map[string]string{
+	"user_name": "user_name_key",
+	"email":     "email_key",
+	"phone":     "phone_key",
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *biz.User, fields ...string) error {
+	po := UserPO{
+		UserName: user.UserName,
+		Password: user.Password,
+		Phone:    user.Phone,
+		Email:    user.Email,
+
+		UserNameKey: usernameKey(user.UserName),
+		EmailKey:    emailKey(user.Email),
+		PhoneKey:    phoneKey(user.Phone),
+
+		Nickname:           user.Nickname,
+		Gender:             int8(user.Gender),
+		Birthday:           user.Birthday,
+		AvatarKey:          user.AvatarKey,
+		AvatarURL:          user.AvatarURL,
+		AvatarThumbnailURL: user.AvatarThumbnailURL,
+
+		TokenVersion:    user.TokenVersion,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		Roles:           strings.Join(user.Roles, ","),
+		DisabledAt:      user.DisabledAt,
+		DisabledReason:  user.DisabledReason,
+	}
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := userColumns[field]
+		if !ok {
+			return fmt.Errorf("failed to update user: unknown field %s", field)
+		}
+		columns = append(columns, column)
+		// 修改用户名、邮箱、手机号时同时更新规范化的列
+		if key, ok := userKeyColumns[column]; ok {
+			columns = append(columns, key)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	result := r.data.db.WithContext(ctx).Model(&UserPO{}).Where("id = ?", user.ID).Select(columns).Updates(&po)
+	if result.Error != nil {
+		if dup := translateDuplicateError(result.Error); dup != nil {
+			return dup
+		}
+		return fmt.Errorf("failed to update user: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	// MySQL 返回的是实际变化的行数，值没有变化时同样为 0，需要再确认用户是否存在
+	var count int64
+	if err := r.data.db.WithContext(ctx).Model(&UserPO{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if count == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
 func (r *UserRepo) FindByUsername(ctx context.Context, username string) (*biz.User, error) {
 	var po UserPO
-	if err := r.data.db.WithContext(ctx).Where("user_name = ?", username).First(&po).Error; err != nil {
+	if err := r.data.db.WithContext(ctx).Where("user_name_key = ?", usernameKey(username)).First(&po).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, apperrors.ErrUserNotFound
 		}
@@ -66,6 +329,28 @@ func (r *UserRepo) FindByUsername(ctx context.Context, username string) (*biz.Us
 	return po.toBizUser(), nil
 }
 
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*biz.User, error) {
+	var po UserPO
+	if err := r.data.db.WithContext(ctx).Where("email_key = ?", emailKey(email)).First(&po).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	}
+	return po.toBizUser(), nil
+}
+
+func (r *UserRepo) FindByPhone(ctx context.Context, phone string) (*biz.User, error) {
+	var po UserPO
+	if err := r.data.db.WithContext(ctx).Where("phone_key = ?", phoneKey(phone)).First(&po).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by phone: %w", err)
+	}
+	return po.toBizUser(), nil
+}
+
 func (r *UserRepo) FindByID(ctx context.Context, id uint) (*biz.User, error) {
 	var po UserPO
 	if err := r.data.db.WithContext(ctx).First(&po, id).Error; err != nil {
@@ -76,3 +361,139 @@ func (r *UserRepo) FindByID(ctx context.Context, id uint) (*biz.User, error) {
 	}
 	return po.toBizUser(), nil
 }
+
+// FindByIDUncached UserRepo 本身不使用缓存，与 FindByID 相同
+func (r *UserRepo) FindByIDUncached(ctx context.Context, id uint) (*biz.User, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *UserRepo) FindByIDs(ctx context.Context, ids []uint) ([]*biz.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var pos []UserPO
+	if err := r.data.db.WithContext(ctx).Where("id IN ?", ids).Find(&pos).Error; err != nil {
+		return nil, fmt.Errorf("failed to find users by ids: %w", err)
+	}
+	users := make([]*biz.User, 0, len(pos))
+	for _, po := range pos {
+		users = append(users, po.toBizUser())
+	}
+	return users, nil
+}
+
+func (r *UserRepo) FindByIDWithDeleted(ctx context.Context, id uint) (*biz.User, error) {
+	var po UserPO
+	if err := r.data.db.WithContext(ctx).Unscoped().First(&po, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by id: %w", err)
+	}
+	return po.toBizUser(), nil
+}
+
+// LIKE 查询时转义用户输入中的通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *UserRepo) List(ctx context.Context, filter *biz.UserFilter) ([]*biz.User, int64, error) {
+	query := r.data.db.WithContext(ctx).Model(&UserPO{})
+	switch filter.Status {
+	case biz.UserStatusActive:
+		query = query.Where("disabled_at IS NULL")
+	case biz.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case biz.UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.Keyword != "" {
+		prefix := likeEscaper.Replace(filter.Keyword) + "%"
+		query = query.Where("user_name LIKE ? OR email LIKE ? OR phone LIKE ?", prefix, prefix, prefix)
+	}
+	if filter.Role != "" {
+		query = query.Where("FIND_IN_SET(?, roles) > 0", filter.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	var pos []UserPO
+	if err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&pos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	users := make([]*biz.User, 0, len(pos))
+	for _, po := range pos {
+		users = append(users, po.toBizUser())
+	}
+	return users, total, nil
+}
+
+// UpdatePassword 在同一个事务中更新密码、递增令牌版本并读取新的令牌版本
+func (r *UserRepo) UpdatePassword(ctx context.Context, id uint, password string) (uint, error) {
+	var version uint
+	err := r.data.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&UserPO{}).Where("id = ?", id).Updates(map[string]any{
+			"password":      password,
+			"token_version": gorm.Expr("token_version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 令牌版本每次都会变化，没有更新到说明用户不存在
+		if result.RowsAffected == 0 {
+			return apperrors.ErrUserNotFound
+		}
+		var po UserPO
+		if err := tx.Select("token_version").Where("id = ?", id).Take(&po).Error; err != nil {
+			return err
+		}
+		version = po.TokenVersion
+		return nil
+	})
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+	return version, nil
+}
+
+// Delete 软删除用户，同时释放用户名、邮箱、手机号的唯一索引
+func (r *UserRepo) Delete(ctx context.Context, id uint) error {
+	result := r.data.db.WithContext(ctx).Model(&UserPO{}).Where("id = ?", id).Updates(map[string]any{
+		"deleted_at":    time.Now(),
+		"user_name_key": nil,
+		"email_key":     nil,
+		"phone_key":     nil,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepo) Restore(ctx context.Context, id uint) error {
+	updates := map[string]any{"deleted_at": nil}
+	for column, expr := range userKeyExprs {
+		updates[column] = expr
+	}
+	result := r.data.db.WithContext(ctx).Unscoped().Model(&UserPO{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(updates)
+	if result.Error != nil {
+		// 删除期间用户名、邮箱、手机号被其他用户使用
+		if dup := translateDuplicateError(result.Error); dup != nil {
+			return dup
+		}
+		return fmt.Errorf("failed to restore user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+const (
+	userCacheKeyPrefix = "user:id:"
+	// userCacheNotFound 用户不存在时缓存的值
+	userCacheNotFound = "-"
+	// userCacheRedeleteDelay 写入后第二次删除缓存的延迟，清除写入期间并发查询回填的旧数据
+	userCacheRedeleteDelay = 500 * time.Millisecond
+)
+
+var UserCacheRequestTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "user_cache_request_total",
+		Help: "Total number of user cache lookups, result is hit, miss or error",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(UserCacheRequestTotal)
+}
+
+// cachedUser 缓存中保存的用户，不包括密码哈希，需要密码的场景使用 FindByIDUncached 从数据库读取
+type cachedUser struct {
+	ID                 uint       `json:"id"`
+	UserName           string     `json:"user_name"`
+	Phone              string     `json:"phone"`
+	Email              string     `json:"email"`
+	Nickname           string     `json:"nickname"`
+	Gender             biz.Gender `json:"gender"`
+	Birthday           *time.Time `json:"birthday"`
+	AvatarKey          string     `json:"avatar_key"`
+	AvatarURL          string     `json:"avatar_url"`
+	AvatarThumbnailURL string     `json:"avatar_thumbnail_url"`
+	TokenVersion       uint       `json:"token_version"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	PhoneVerifiedAt    *time.Time `json:"phone_verified_at"`
+	Roles              []string   `json:"roles"`
+	DisabledAt         *time.Time `json:"disabled_at"`
+	DisabledReason     string     `json:"disabled_reason"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+func toCachedUser(user *biz.User) *cachedUser {
+	return &cachedUser{
+		ID:                 user.ID,
+		UserName:           user.UserName,
+		Phone:              user.Phone,
+		Email:              user.Email,
+		Nickname:           user.Nickname,
+		Gender:             user.Gender,
+		Birthday:           user.Birthday,
+		AvatarKey:          user.AvatarKey,
+		AvatarURL:          user.AvatarURL,
+		AvatarThumbnailURL: user.AvatarThumbnailURL,
+		TokenVersion:       user.TokenVersion,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		PhoneVerifiedAt:    user.PhoneVerifiedAt,
+		Roles:              user.Roles,
+		DisabledAt:         user.DisabledAt,
+		DisabledReason:     user.DisabledReason,
+		CreatedAt:          user.CreatedAt,
+	}
+}
+
+func (u *cachedUser) toBizUser() *biz.User {
+	return &biz.User{
+		ID:                 u.ID,
+		UserName:           u.UserName,
+		Phone:              u.Phone,
+		Email:              u.Email,
+		Nickname:           u.Nickname,
+		Gender:             u.Gender,
+		Birthday:           u.Birthday,
+		AvatarKey:          u.AvatarKey,
+		AvatarURL:          u.AvatarURL,
+		AvatarThumbnailURL: u.AvatarThumbnailURL,
+		TokenVersion:       u.TokenVersion,
+		EmailVerifiedAt:    u.EmailVerifiedAt,
+		PhoneVerifiedAt:    u.PhoneVerifiedAt,
+		Roles:              u.Roles,
+		DisabledAt:         u.DisabledAt,
+		DisabledReason:     u.DisabledReason,
+		CreatedAt:          u.CreatedAt,
+	}
+}
+
+func userCacheKey(id uint) string {
+	return userCacheKeyPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+// CachedUserRepo 按 ID 查询用户时先读 Redis，未命中时查询 repo 并写入缓存，用户不存在时同样缓存。
+// 缓存中不保存密码哈希，FindByID 返回的用户 Password 为空。
+// 同一个用户的并发查询只有一个会访问数据库。创建、修改、删除、恢复用户以及修改密码的写入提交后删除对应的缓存，
+// 并在 userCacheRedeleteDelay 后再删除一次，避免写入前开始的查询把旧数据写回缓存。
+// 删除失败时缓存最多在 TTL 内与数据库不一致，禁用状态、令牌版本等安全相关的检查应使用 FindByIDUncached
+type CachedUserRepo struct {
+	biz.UserRepo
+	rdb         *redis.Client
+	ttl         time.Duration
+	notFoundTTL time.Duration
+	group       singleflight.Group
+}
+
+func NewCachedUserRepo(repo biz.UserRepo, rdb *redis.Client, c *conf.Cache) biz.UserRepo {
+	return &CachedUserRepo{
+		UserRepo:    repo,
+		rdb:         rdb,
+		ttl:         time.Duration(c.UserTTL) * time.Second,
+		notFoundTTL: time.Duration(c.NotFoundTTL) * time.Second,
+	}
+}
+
+func (r *CachedUserRepo) FindByID(ctx context.Context, id uint) (*biz.User, error) {
+	key := userCacheKey(id)
+	cached, err := r.rdb.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		UserCacheRequestTotal.WithLabelValues("hit").Inc()
+		return decodeCachedUser(cached)
+	case !errors.Is(err, redis.Nil):
+		// Redis 不可用时直接查询数据库
+		UserCacheRequestTotal.WithLabelValues("error").Inc()
+		return r.UserRepo.FindByID(ctx, id)
+	}
+
+	UserCacheRequestTotal.WithLabelValues("miss").Inc()
+	// 共享查询结果的调用方可能来自不同的请求，不能因为第一个请求取消而全部失败
+	loaded, err, _ := r.group.Do(key, func() (any, error) {
+		return r.load(context.WithoutCancel(ctx), key, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 每个调用方各自解码，避免共享同一个 *biz.User
+	return decodeCachedUser(loaded.([]byte))
+}
+
+// load 从 repo 查询用户并写入缓存，写缓存失败不影响查询结果
+func (r *CachedUserRepo) load(ctx context.Context, key string, id uint) ([]byte, error) {
+	user, err := r.UserRepo.FindByID(ctx, id)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		if r.notFoundTTL > 0 {
+			r.rdb.Set(ctx, key, userCacheNotFound, r.notFoundTTL)
+		}
+		return []byte(userCacheNotFound), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(toCachedUser(user))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cached user: %w", err)
+	}
+	r.rdb.Set(ctx, key, encoded, r.ttl)
+	return encoded, nil
+}
+
+func decodeCachedUser(cached []byte) (*biz.User, error) {
+	if string(cached) == userCacheNotFound {
+		return nil, apperrors.ErrUserNotFound
+	}
+	var user cachedUser
+	if err := json.Unmarshal(cached, &user); err != nil {
+		return nil, fmt.Errorf("failed to decode cached user: %w", err)
+	}
+	return user.toBizUser(), nil
+}
+
+// invalidate 删除缓存，并让之后的查询不再等待进行中的、可能读到旧数据的查询。
+// 写入前已经从数据库读到旧数据的查询可能在第一次删除之后才写回缓存，所以延迟后再删除一次
+func (r *CachedUserRepo) invalidate(ctx context.Context, id uint) {
+	key := userCacheKey(id)
+	ctx = context.WithoutCancel(ctx)
+	r.group.Forget(key)
+	r.rdb.Del(ctx, key)
+	time.AfterFunc(userCacheRedeleteDelay, func() {
+		r.group.Forget(key)
+		r.rdb.Del(ctx, key)
+	})
+}
+
+func (r *CachedUserRepo) Create(ctx context.Context, user *biz.User) (*biz.User, error) {
+	created, err := r.UserRepo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	// 清除之前查询该 ID 时缓存的不存在
+	r.invalidate(ctx, created.ID)
+	return created, nil
+}
+
+func (r *CachedUserRepo) Update(ctx context.Context, user *biz.User, fields ...string) error {
+	err := r.UserRepo.Update(ctx, user, fields...)
+	// 更新失败时也可能已经部分写入，同样删除缓存
+	r.invalidate(ctx, user.ID)
+	return err
+}
+
+func (r *CachedUserRepo) UpdatePassword(ctx context.Context, id uint, password string) (uint, error) {
+	version, err := r.UserRepo.UpdatePassword(ctx, id, password)
+	r.invalidate(ctx, id)
+	return version, err
+}
+
+func (r *CachedUserRepo) Delete(ctx context.Context, id uint) error {
+	err := r.UserRepo.Delete(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+func (r *CachedUserRepo) Restore(ctx context.Context, id uint) error {
+	err := r.UserRepo.Restore(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
@@ -0,0 +1,215 @@
+package data_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz/mock"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+func newTestCachedUserRepo(t *testing.T) (biz.UserRepo, *mock.MockUserRepo, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	repo := mock.NewMockUserRepo(gomock.NewController(t))
+	return data.NewCachedUserRepo(repo, rdb, &conf.Cache{UserTTL: 60, NotFoundTTL: 10}), repo, mr
+}
+
+func cacheRequests(result string) float64 {
+	return testutil.ToFloat64(data.UserCacheRequestTotal.WithLabelValues(result))
+}
+
+func TestCachedUserRepo_FindByID(t *testing.T) {
+	cached, repo, mr := newTestCachedUserRepo(t)
+	ctx := context.Background()
+	hits, misses := cacheRequests("hit"), cacheRequests("miss")
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, UserName: "kyson"}, nil).Times(1)
+
+	for i := 0; i < 3; i++ {
+		user, err := cached.FindByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "kyson", user.UserName)
+	}
+	assert.Equal(t, misses+1, cacheRequests("miss"))
+	assert.Equal(t, hits+2, cacheRequests("hit"))
+	assert.Equal(t, 60*time.Second, mr.TTL("user:id:1"))
+
+	// 调用方修改返回的用户不影响缓存
+	user, _ := cached.FindByID(ctx, 1)
+	user.UserName = "changed"
+	user, _ = cached.FindByID(ctx, 1)
+	assert.Equal(t, "kyson", user.UserName)
+
+	// 过期后重新查询
+	mr.FastForward(time.Minute)
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, UserName: "kyson"}, nil).Times(1)
+	_, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+}
+
+func TestCachedUserRepo_NoPassword(t *testing.T) {
+	cached, repo, mr := newTestCachedUserRepo(t)
+	ctx := context.Background()
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, UserName: "kyson", Password: "hashed"}, nil)
+	user, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, user.Password)
+	raw, err := mr.Get("user:id:1")
+	require.NoError(t, err)
+	assert.NotContains(t, raw, "hashed")
+
+	// 需要密码时绕过缓存
+	repo.EXPECT().FindByIDUncached(gomock.Any(), uint(1)).Return(&biz.User{ID: 1, Password: "hashed"}, nil)
+	user, err = cached.FindByIDUncached(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "hashed", user.Password)
+}
+
+func TestCachedUserRepo_NotFound(t *testing.T) {
+	cached, repo, mr := newTestCachedUserRepo(t)
+	ctx := context.Background()
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(2)).Return(nil, apperrors.ErrUserNotFound).Times(1)
+	for i := 0; i < 2; i++ {
+		_, err := cached.FindByID(ctx, 2)
+		assert.Equal(t, apperrors.ErrUserNotFound, err)
+	}
+	assert.Equal(t, 10*time.Second, mr.TTL("user:id:2"))
+
+	// 注册后立即可以查到
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&biz.User{ID: 2}, nil)
+	repo.EXPECT().FindByID(gomock.Any(), uint(2)).Return(&biz.User{ID: 2}, nil)
+	_, err := cached.Create(ctx, &biz.User{})
+	require.NoError(t, err)
+	user, err := cached.FindByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), user.ID)
+}
+
+func TestCachedUserRepo_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		write func(r biz.UserRepo, m *mock.MockUserRepo) error
+	}{
+		{"修改", func(r biz.UserRepo, m *mock.MockUserRepo) error {
+			m.EXPECT().Update(gomock.Any(), gomock.Any(), "Nickname").Return(nil)
+			return r.Update(ctx, &biz.User{ID: 1}, "Nickname")
+		}},
+		{"修改密码", func(r biz.UserRepo, m *mock.MockUserRepo) error {
+			m.EXPECT().UpdatePassword(gomock.Any(), uint(1), "hashed").Return(uint(2), nil)
+			_, err := r.UpdatePassword(ctx, 1, "hashed")
+			return err
+		}},
+		{"删除", func(r biz.UserRepo, m *mock.MockUserRepo) error {
+			m.EXPECT().Delete(gomock.Any(), uint(1)).Return(nil)
+			return r.Delete(ctx, 1)
+		}},
+		{"恢复", func(r biz.UserRepo, m *mock.MockUserRepo) error {
+			m.EXPECT().Restore(gomock.Any(), uint(1)).Return(nil)
+			return r.Restore(ctx, 1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached, repo, mr := newTestCachedUserRepo(t)
+			repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1}, nil).Times(2)
+
+			_, err := cached.FindByID(ctx, 1)
+			require.NoError(t, err)
+			require.True(t, mr.Exists("user:id:1"))
+
+			require.NoError(t, tt.write(cached, repo))
+			assert.False(t, mr.Exists("user:id:1"))
+
+			_, err = cached.FindByID(ctx, 1)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCachedUserRepo_StaleRefill(t *testing.T) {
+	cached, repo, mr := newTestCachedUserRepo(t)
+	ctx := context.Background()
+
+	// 查询在修改之前读到旧数据，在修改提交、删除缓存之后才写回缓存
+	loaded, release := make(chan struct{}), make(chan struct{})
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).DoAndReturn(func(ctx context.Context, id uint) (*biz.User, error) {
+		close(loaded)
+		<-release
+		return &biz.User{ID: 1}, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cached.FindByID(ctx, 1)
+	}()
+	<-loaded
+
+	repo.EXPECT().Update(gomock.Any(), gomock.Any(), "DisabledAt").Return(nil)
+	require.NoError(t, cached.Update(ctx, &biz.User{ID: 1}, "DisabledAt"))
+	close(release)
+	<-done
+	require.True(t, mr.Exists("user:id:1"))
+
+	// 延迟后再次删除旧数据
+	assert.Eventually(t, func() bool { return !mr.Exists("user:id:1") }, 2*time.Second, 50*time.Millisecond)
+}
+
+func TestCachedUserRepo_Singleflight(t *testing.T) {
+	cached, repo, _ := newTestCachedUserRepo(t)
+	release := make(chan struct{})
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).DoAndReturn(func(ctx context.Context, id uint) (*biz.User, error) {
+		<-release
+		return &biz.User{ID: 1}, nil
+	}).Times(1)
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cached.FindByID(context.Background(), 1)
+			errs <- err
+		}()
+	}
+	// 等待所有请求都在等同一个查询
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestCachedUserRepo_RedisDown(t *testing.T) {
+	cached, repo, mr := newTestCachedUserRepo(t)
+	mr.Close()
+	failures := cacheRequests("error")
+
+	repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&biz.User{ID: 1}, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		user, err := cached.FindByID(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), user.ID)
+	}
+	assert.Equal(t, failures+2, cacheRequests("error"))
+}
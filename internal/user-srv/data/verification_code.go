@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+const (
+	verificationCodeKeyPrefix = "verify:"
+	cooldownKeyPrefix         = "verify:cooldown:"
+)
+
+// 只在验证码仍然存在时累加错误次数，避免给已过期的 key 重新创建一个没有 TTL 的 hash
+var incrAttemptsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+end
+return 0
+`)
+
+func verificationCodeKey(purpose, target string) string {
+	return verificationCodeKeyPrefix + purpose + ":" + target
+}
+
+type RedisVerificationCodeRepo struct {
+	data *Data
+}
+
+func NewRedisVerificationCodeRepo(data *Data) biz.VerificationCodeRepo {
+	return &RedisVerificationCodeRepo{data: data}
+}
+
+func (r *RedisVerificationCodeRepo) Save(ctx context.Context, code *biz.VerificationCode) error {
+	key := verificationCodeKey(code.Purpose, code.Target)
+	pipe := r.data.rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key,
+		"user_id", code.UserID,
+		"code_hash", code.CodeHash,
+		"expires_at", code.ExpiresAt.Unix(),
+		"attempts", 0,
+	)
+	pipe.ExpireAt(ctx, key, code.ExpiresAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save verification code: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisVerificationCodeRepo) Get(ctx context.Context, purpose, target string) (*biz.VerificationCode, error) {
+	values, err := r.data.rdb.HGetAll(ctx, verificationCodeKey(purpose, target)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification code: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+	userID, err := strconv.ParseUint(values["user_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse verification code: %w", err)
+	}
+	expiresAt, err := strconv.ParseInt(values["expires_at"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse verification code: %w", err)
+	}
+	return &biz.VerificationCode{
+		Purpose:   purpose,
+		Target:    target,
+		UserID:    uint(userID),
+		CodeHash:  values["code_hash"],
+		ExpiresAt: time.Unix(expiresAt, 0),
+	}, nil
+}
+
+func (r *RedisVerificationCodeRepo) IncrAttempts(ctx context.Context, purpose, target string) (int, error) {
+	n, err := incrAttemptsScript.Run(ctx, r.data.rdb, []string{verificationCodeKey(purpose, target)}).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increase verification attempts: %w", err)
+	}
+	return n, nil
+}
+
+func (r *RedisVerificationCodeRepo) Delete(ctx context.Context, purpose, target string) (bool, error) {
+	n, err := r.data.rdb.Del(ctx, verificationCodeKey(purpose, target)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete verification code: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *RedisVerificationCodeRepo) SetCooldown(ctx context.Context, purpose, target string, d time.Duration) (bool, error) {
+	ok, err := r.data.rdb.SetNX(ctx, cooldownKeyPrefix+purpose+":"+target, 1, d).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set verification cooldown: %w", err)
+	}
+	return ok, nil
+}
+
+// MemoryVerificationCodeRepo 基于内存的实现，用于测试
+type MemoryVerificationCodeRepo struct {
+	mu        sync.Mutex
+	codes     map[string]*biz.VerificationCode
+	attempts  map[string]int
+	cooldowns map[string]time.Time
+}
+
+func NewMemoryVerificationCodeRepo() biz.VerificationCodeRepo {
+	return &MemoryVerificationCodeRepo{
+		codes:     make(map[string]*biz.VerificationCode),
+		attempts:  make(map[string]int),
+		cooldowns: make(map[string]time.Time),
+	}
+}
+
+func (r *MemoryVerificationCodeRepo) Save(ctx context.Context, code *biz.VerificationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationCodeKey(code.Purpose, code.Target)
+	stored := *code
+	r.codes[key] = &stored
+	r.attempts[key] = 0
+	return nil
+}
+
+func (r *MemoryVerificationCodeRepo) Get(ctx context.Context, purpose, target string) (*biz.VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[verificationCodeKey(purpose, target)]
+	if !ok {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+	found := *code
+	return &found, nil
+}
+
+func (r *MemoryVerificationCodeRepo) IncrAttempts(ctx context.Context, purpose, target string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationCodeKey(purpose, target)
+	if _, ok := r.codes[key]; !ok {
+		return 0, nil
+	}
+	r.attempts[key]++
+	return r.attempts[key], nil
+}
+
+func (r *MemoryVerificationCodeRepo) Delete(ctx context.Context, purpose, target string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationCodeKey(purpose, target)
+	if _, ok := r.codes[key]; !ok {
+		return false, nil
+	}
+	delete(r.codes, key)
+	delete(r.attempts, key)
+	return true, nil
+}
+
+func (r *MemoryVerificationCodeRepo) SetCooldown(ctx context.Context, purpose, target string, d time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationCodeKey(purpose, target)
+	now := time.Now()
+	if until, ok := r.cooldowns[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	r.cooldowns[key] = now.Add(d)
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	"github.com/kyson/e-shop-native/pkg/code"
+)
+
+// 英文消息目录，key 为错误码，或者 "错误码.key" 对应 WithMessageKey 中的 key
+func init() {
+	code.RegisterCatalog("en", code.Catalog{
+		// 通用错误
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".required":                "%s is required",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".unsupported_field":       "Field cannot be updated: %s",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".gender":                  "Invalid gender",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".birthday_range":          "Invalid birthday",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".birthday_format":         "Invalid birthday, expected YYYY-MM-DD",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".nickname_too_long":       "Nickname cannot be longer than %s characters",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".disable_reason_required": "A reason is required to disable an account",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".disable_self":            "You cannot disable your own account",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".delete_self":             "You cannot delete your own account",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_required":         "Please choose an avatar image",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_request":          "The avatar image is too large or the request is malformed",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_read":             "Failed to read the avatar image",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_too_large":        "The avatar image cannot be larger than %dKB",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_format":           "Only JPEG, PNG and GIF avatars are supported",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_corrupted":        "The avatar image is corrupted",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".avatar_dimension":        "The avatar image cannot be wider or taller than %d pixels",
+		v1.ErrorCode_INVALID_ARGUMENT.String() + ".batch_too_large":         "Cannot query more than %d users at once",
+
+		// 用户相关的错误
+		v1.ErrorCode_USER_ALREADY_EXISTS.String():               "User already exists",
+		v1.ErrorCode_USER_ALREADY_EXISTS.String() + ".username": "Username is already taken",
+		v1.ErrorCode_USER_ALREADY_EXISTS.String() + ".email":    "Email is already in use",
+		v1.ErrorCode_USER_ALREADY_EXISTS.String() + ".phone":    "Phone number is already in use",
+		v1.ErrorCode_USER_NOT_FOUND.String():                    "User not found",
+		v1.ErrorCode_USER_DISABLED.String():                     "This account has been disabled",
+		v1.ErrorCode_EMAIL_ALREADY_EXISTS.String():              "Email is already in use",
+		v1.ErrorCode_PHONE_ALREADY_EXISTS.String():              "Phone number is already in use",
+
+		v1.ErrorCode_PASSWORD_INCORRECT.String(): "Incorrect password",
+		v1.ErrorCode_PASSWORD_REUSED.String():    "You cannot reuse a recently used password",
+
+		v1.ErrorCode_EMAIL_NOT_VERIFIED.String():              "Email is not verified",
+		v1.ErrorCode_ACCOUNT_LOCKED.String():                  "Too many failed login attempts, please try again later",
+		v1.ErrorCode_ACCOUNT_LOCKED.String() + ".retry_after": "Too many failed login attempts, please try again in %d seconds",
+
+		v1.ErrorCode_VERIFICATION_CODE_INVALID.String():      "The verification code is invalid or has expired",
+		v1.ErrorCode_VERIFICATION_CODE_TOO_FREQUENT.String(): "Verification codes are being requested too often, please try again later",
+
+		// 认证相关的错误
+		v1.ErrorCode_TOKEN_INVALID.String():              "Invalid token",
+		v1.ErrorCode_TOKEN_INVALID.String() + ".missing": "Authentication required",
+		v1.ErrorCode_TOKEN_INVALID.String() + ".format":  "Invalid token format",
+		v1.ErrorCode_TOKEN_INVALID.String() + ".empty":   "Token is empty",
+		v1.ErrorCode_TOKEN_INVALID.String() + ".invalid": "Invalid or expired token",
+		v1.ErrorCode_TOKEN_EXPIRED.String():              "Token has expired",
+		v1.ErrorCode_TOKEN_REVOKED.String():              "Token is no longer valid, please log in again",
+
+		v1.ErrorCode_PERMISSION_DENIED.String():                  "Permission denied",
+		v1.ErrorCode_PERMISSION_DENIED.String() + ".service":     "Only internal services can call this method",
+		v1.ErrorCode_PERMISSION_DENIED.String() + ".target_role": "Cannot manage an account whose role is at or above your own",
+		v1.ErrorCode_SESSION_NOT_FOUND.String():                  "Session not found or has expired",
+
+		v1.ErrorCode_TWO_FACTOR_CODE_INVALID.String():    "Incorrect authentication code",
+		v1.ErrorCode_TWO_FACTOR_ALREADY_ENABLED.String(): "Two-factor authentication is already enabled",
+		v1.ErrorCode_TWO_FACTOR_NOT_ENABLED.String():     "Two-factor authentication is not enabled",
+		v1.ErrorCode_LOGIN_CHALLENGE_INVALID.String():    "Login verification has expired, please log in again",
+
+		v1.ErrorCode_REFRESH_TOKEN_INVALID.String(): "Invalid refresh token",
+		v1.ErrorCode_REFRESH_TOKEN_REUSED.String():  "Refresh token has already been used, please log in again",
+
+		// 验证相关的错误
+		v1.ErrorCode_USERNAME_FORMAT_ERROR.String():               "Invalid username",
+		v1.ErrorCode_USERNAME_FORMAT_ERROR.String() + ".required": "Username is required",
+		v1.ErrorCode_USERNAME_FORMAT_ERROR.String() + ".min":      "Username must be at least %s characters",
+		v1.ErrorCode_USERNAME_FORMAT_ERROR.String() + ".max":      "Username cannot be longer than %s characters",
+		v1.ErrorCode_USERNAME_FORMAT_ERROR.String() + ".charset":  "Username can only contain letters, digits and underscores",
+
+		v1.ErrorCode_EMAIL_FORMAT_ERROR.String():               "Invalid email address",
+		v1.ErrorCode_EMAIL_FORMAT_ERROR.String() + ".required": "Email is required",
+
+		v1.ErrorCode_PHONE_FORMAT_ERROR.String():               "Invalid phone number",
+		v1.ErrorCode_PHONE_FORMAT_ERROR.String() + ".required": "Phone number is required",
+		v1.ErrorCode_PHONE_FORMAT_ERROR.String() + ".region":   "Phone numbers from this country or region are not supported yet",
+
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String():                 "Invalid password",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".required":   "Password is required",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".min":        "Password must be at least %s characters",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".max":        "Password cannot be longer than %s characters",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".charset":    "Password cannot contain whitespace",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".upper":      "Password must contain an upper case letter",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".lower":      "Password must contain a lower case letter",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".digit":      "Password must contain a digit",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".symbol":     "Password must contain a special character",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".breached":   "This password has appeared in a data breach, please choose another one",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".weak":       "Password is too weak, please use a longer and less predictable password",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".user_input": "Password cannot contain your username or email",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".dictionary": "Password contains the common word %s and is easy to guess",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".sequence":   "Password contains a sequence of letters or digits (such as abc or 123) and is easy to guess",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".keyboard":   "Password contains adjacent keyboard keys (such as qwerty or 1qaz) and is easy to guess",
+		v1.ErrorCode_PASSWORD_FORMAT_ERROR.String() + ".repeat":     "Password contains repeated characters (such as aaa) and is easy to guess",
+	})
+}
@@ -9,21 +9,44 @@ import (
 
 // 通用错误
 var (
-	ErrInternal = code.ErrInternal
+	ErrInternal        = code.ErrInternal
+	ErrInvalidArgument = code.ErrInvalidArgument
 )
 
 // 定义用户相关的错误
 var (
-	ErrUserAlreadyExists = code.New(v1.ErrorCode_USER_ALREADY_EXISTS.String(), "用户已存在", codes.AlreadyExists)
-	ErrUserNotFound      = code.New(v1.ErrorCode_USER_NOT_FOUND.String(), "用户不存在", codes.NotFound)
+	ErrUserAlreadyExists  = code.New(v1.ErrorCode_USER_ALREADY_EXISTS.String(), "用户已存在", codes.AlreadyExists)
+	ErrUserNotFound       = code.New(v1.ErrorCode_USER_NOT_FOUND.String(), "用户不存在", codes.NotFound)
+	ErrUserDisabled       = code.New(v1.ErrorCode_USER_DISABLED.String(), "账号已被禁用", codes.PermissionDenied)
+	ErrEmailAlreadyExists = code.New(v1.ErrorCode_EMAIL_ALREADY_EXISTS.String(), "邮箱已被使用", codes.AlreadyExists)
+	ErrPhoneAlreadyExists = code.New(v1.ErrorCode_PHONE_ALREADY_EXISTS.String(), "手机号已被使用", codes.AlreadyExists)
 
 	ErrPasswordIncorrect = code.New(v1.ErrorCode_PASSWORD_INCORRECT.String(), "密码错误", codes.Unauthenticated)
+	ErrPasswordReused    = code.New(v1.ErrorCode_PASSWORD_REUSED.String(), "不能使用最近用过的密码", codes.InvalidArgument)
+
+	ErrEmailNotVerified = code.New(v1.ErrorCode_EMAIL_NOT_VERIFIED.String(), "邮箱未验证", codes.FailedPrecondition)
+	ErrAccountLocked    = code.New(v1.ErrorCode_ACCOUNT_LOCKED.String(), "登录失败次数过多，请稍后重试", codes.ResourceExhausted)
+
+	ErrVerificationCodeInvalid     = code.New(v1.ErrorCode_VERIFICATION_CODE_INVALID.String(), "验证码无效或已过期", codes.InvalidArgument)
+	ErrVerificationCodeTooFrequent = code.New(v1.ErrorCode_VERIFICATION_CODE_TOO_FREQUENT.String(), "验证码发送过于频繁，请稍后再试", codes.ResourceExhausted)
 )
 
-// 定义认证相关的错误
+// 定义认证相关的错误，令牌缺失、无效、过期都是未认证，与 gRPC 的 Unauthenticated 对应
 var (
-	ErrTokenInvalid = code.New(v1.ErrorCode_TOKEN_INVALID.String(), "Token 无效", codes.InvalidArgument)
+	ErrTokenInvalid = code.New(v1.ErrorCode_TOKEN_INVALID.String(), "Token 无效", codes.Unauthenticated)
 	ErrTokenExpired = code.New(v1.ErrorCode_TOKEN_EXPIRED.String(), "Token 已过期", codes.Unauthenticated)
+	ErrTokenRevoked = code.New(v1.ErrorCode_TOKEN_REVOKED.String(), "Token 已失效，请重新登录", codes.Unauthenticated)
+
+	ErrPermissionDenied = code.New(v1.ErrorCode_PERMISSION_DENIED.String(), "没有访问权限", codes.PermissionDenied)
+	ErrSessionNotFound  = code.New(v1.ErrorCode_SESSION_NOT_FOUND.String(), "会话不存在或已失效", codes.NotFound)
+
+	ErrTwoFactorCodeInvalid    = code.New(v1.ErrorCode_TWO_FACTOR_CODE_INVALID.String(), "动态验证码错误", codes.Unauthenticated)
+	ErrTwoFactorAlreadyEnabled = code.New(v1.ErrorCode_TWO_FACTOR_ALREADY_ENABLED.String(), "已开启两步验证", codes.FailedPrecondition)
+	ErrTwoFactorNotEnabled     = code.New(v1.ErrorCode_TWO_FACTOR_NOT_ENABLED.String(), "未开启两步验证", codes.FailedPrecondition)
+	ErrLoginChallengeInvalid   = code.New(v1.ErrorCode_LOGIN_CHALLENGE_INVALID.String(), "登录验证已过期，请重新登录", codes.Unauthenticated)
+
+	ErrRefreshTokenInvalid = code.New(v1.ErrorCode_REFRESH_TOKEN_INVALID.String(), "刷新令牌无效", codes.Unauthenticated)
+	ErrRefreshTokenReused  = code.New(v1.ErrorCode_REFRESH_TOKEN_REUSED.String(), "刷新令牌已被使用，请重新登录", codes.Unauthenticated)
 )
 
 // 定义验证相关的错误
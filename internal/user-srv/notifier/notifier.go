@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+)
+
+// NewNotifier 根据配置创建通知发送器，目前还没有接入真实的邮件、短信服务
+func NewNotifier(c *conf.Notifier) (biz.Notifier, func(), error) {
+	switch c.Type {
+	case "", "console":
+		return NewWriterNotifier(os.Stdout), func() {}, nil
+	case "file":
+		return NewFileNotifier(c.Path)
+	default:
+		return nil, nil, fmt.Errorf("unsupported notifier type: %s", c.Type)
+	}
+}
+
+// record 写出的一行通知记录
+type record struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+// WriterNotifier 把通知按 JSON Lines 格式写入 io.Writer，便于离线调试和测试
+type WriterNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterNotifier(w io.Writer) biz.Notifier {
+	return &WriterNotifier{w: w}
+}
+
+// NewFileNotifier 把通知追加写入文件
+func NewFileNotifier(path string) (biz.Notifier, func(), error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("notifier path is required for file notifier")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open notifier file: %w", err)
+	}
+	cleanup := func() {
+		if err := f.Close(); err != nil {
+			panic("关闭通知文件错误")
+		}
+	}
+	return NewWriterNotifier(f), cleanup, nil
+}
+
+func (n *WriterNotifier) Notify(ctx context.Context, msg *biz.Notification) error {
+	line, err := json.Marshal(&record{
+		Time:    time.Now(),
+		Channel: msg.Channel,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, err := n.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package notifier
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewNotifier)
@@ -1,17 +1,26 @@
 package server
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 
+	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 )
 
-func NewAdminServer(c *conf.Server) *AdminHTTPServer {
+// AuthConfigLoader 重新读取配置文件中的 auth 配置
+type AuthConfigLoader func() (*conf.Auth, error)
+
+// NewAdminServer 运维接口，只在内网端口上提供，修改服务状态的接口还需要内部服务的凭证
+func NewAdminServer(c *conf.Server, a auth.Auth, load AuthConfigLoader, services *auth.ServiceCredentials, logger *zap.Logger) *AdminHTTPServer {
 	mux := http.NewServeMux()
 
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("POST /auth/keys/reload", requireService(services, logger, reloadKeysHandler(a, load, logger)))
 
 	http_server := &http.Server{
 		Addr:    c.Admin.Addr,
@@ -19,3 +28,45 @@ func NewAdminServer(c *conf.Server) *AdminHTTPServer {
 	}
 	return &AdminHTTPServer{Server: http_server}
 }
+
+type reloadKeysReply struct {
+	ActiveKeyID string   `json:"active_key_id"`
+	KeyIDs      []string `json:"key_ids"`
+}
+
+// requireService 要求请求头 Authorization: Bearer <服务密钥>，密钥与 gRPC 内部接口使用的 auth.services 相同
+func requireService(services *auth.ServiceCredentials, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		name, ok := services.Authenticate(key)
+		if !found || !ok {
+			logger.Warn("unauthenticated admin request", zap.String("path", r.URL.Path), zap.String("remote_addr", r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.ServiceToContext(r.Context(), name)))
+	})
+}
+
+// reloadKeysHandler 轮换签名密钥时先把新密钥加入 keys 并重新加载，
+// 等所有验签方都获取到新的公钥后再修改 active_key_id 并重新加载，不需要重启进程。
+// 调用方需要携带 auth.services 中配置的服务密钥，见 requireService
+func reloadKeysHandler(a auth.Auth, load AuthConfigLoader, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, err := load()
+		if err == nil {
+			err = a.ReloadKeys(c)
+		}
+		if err != nil {
+			// 加载失败时继续使用原来的密钥
+			logger.Error("failed to reload signing keys", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		active, kids := a.KeyIDs()
+		logger.Info("signing keys reloaded", zap.String("active_key_id", active), zap.Strings("key_ids", kids))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(reloadKeysReply{ActiveKeyID: active, KeyIDs: kids})
+	}
+}
@@ -7,18 +7,39 @@ import (
 
 	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1" // Update to the correct import path for your generated gRPC code
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
 )
 
-func NewGRPCServer(c *conf.Server, src v1.UserServiceServer, auth auth.Auth, log *zap.Logger) *BusinessGRPCServer {
+// NewClaimsCheckers 令牌签名通过后的服务端状态检查，鉴权拦截器和令牌内省共用
+func NewClaimsCheckers(revocations auth.RevocationList, uc biz.UserService, sessions biz.SessionService) auth.ClaimsCheckers {
+	return auth.ClaimsCheckers{
+		auth.NewRevocationChecker(revocations),
+		auth.NewUserStatusChecker(uc),
+		auth.NewTokenVersionChecker(uc),
+		auth.NewSessionChecker(sessions),
+	}
+}
+
+func NewGRPCServer(c *conf.Server, src v1.UserServiceServer, admin v1.AdminUserServiceServer,
+	internal v1.InternalUserServiceServer, authSrv v1.AuthServiceServer, a auth.Auth, policies auth.Policies,
+	services *auth.ServiceCredentials, checkers auth.ClaimsCheckers, log *zap.Logger) (*BusinessGRPCServer, error) {
+	trustedProxies, err := intercepter.ParseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	// options
 	opts := grpc.ChainUnaryInterceptor(
 		intercepter.TraceServerInterceptor,
+		intercepter.ClientIPInterceptor(trustedProxies),
+		intercepter.UserAgentInterceptor,
+		intercepter.LocaleInterceptor,
 		intercepter.LoggingInterceptor,
 		intercepter.RecoverInterceptor(log),
 		intercepter.MetricsInterceptor,
-		intercepter.AuthInterceptor(auth),
+		intercepter.AuthInterceptor(a, policies, services, checkers...),
 		intercepter.ErrorInterceptor,
 	)
 
@@ -27,10 +48,14 @@ func NewGRPCServer(c *conf.Server, src v1.UserServiceServer, auth auth.Auth, log
 
 	// Register your gRPC services here
 	v1.RegisterUserServiceServer(server, src)
+	v1.RegisterAdminUserServiceServer(server, admin)
+	// 内部接口不注册到 HTTP 网关
+	v1.RegisterInternalUserServiceServer(server, internal)
+	v1.RegisterAuthServiceServer(server, authSrv)
 
 	// Enable reflection for debugging (optional)
 	reflection.Register(server)
 
 	// Return the gRPC server instance
-	return &BusinessGRPCServer{Server: server}
+	return &BusinessGRPCServer{Server: server}, nil
 }
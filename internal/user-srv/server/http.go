@@ -2,7 +2,11 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -12,12 +16,17 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
 	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
 	middleware "github.com/kyson/e-shop-native/internal/user-srv/server/middleware"
 )
 
-func NewHTTPServer(c *conf.Server, logger *zap.Logger) (*BusinessHTTPServer, error) {
+// maxAvatarRequestSize 头像上传请求体的上限，与 gRPC 默认的最大消息一致，图片大小由 biz 按配置校验
+const maxAvatarRequestSize = 4 << 20
+
+func NewHTTPServer(c *conf.Server, storage *conf.Storage, a auth.Auth, logger *zap.Logger) (*BusinessHTTPServer, error) {
 	// 初始化gateway
 	mux := runtime.NewServeMux(runtime.WithErrorHandler(middleware.CustomErrorHandle(logger)))
 
@@ -31,6 +40,15 @@ func NewHTTPServer(c *conf.Server, logger *zap.Logger) (*BusinessHTTPServer, err
 	if err != nil {
 		return nil, err
 	}
+	err = v1.RegisterAdminUserServiceHandlerFromEndpoint(context.Background(), mux, c.GRPC.Addr, opts)
+	if err != nil {
+		return nil, err
+	}
+	// 头像上传使用 multipart 表单，gateway 不支持，由 chi 直接处理后调用 gRPC
+	conn, err := grpc.NewClient(c.GRPC.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	// 这里可以直接把mux挂载到http.Server上，但是这样的话就不能实现中间件了，所以引入一个轻量http库
 	chi := chi.NewRouter()
@@ -40,6 +58,14 @@ func NewHTTPServer(c *conf.Server, logger *zap.Logger) (*BusinessHTTPServer, err
 	chi.Use(chiMiddleware.Recoverer)      // 终极保护，必须在最外层之一，捕获一切panic
 	chi.Use(middleware.MetricsMiddleware) // 指标
 
+	chi.Get("/.well-known/jwks.json", jwksHandler(a)) // 其他服务从这里获取验签公钥
+	chi.Post("/v1/user/avatar", avatarUploadHandler(mux, v1.NewUserServiceClient(conn), middleware.CustomErrorHandle(logger)))
+	// 使用本地存储时由这里提供上传的文件，使用 CDN 时 base_url 配置为完整地址
+	if strings.HasPrefix(storage.BaseURL, "/") {
+		prefix := strings.TrimSuffix(storage.BaseURL, "/")
+		chi.Handle(prefix+"/*", http.StripPrefix(prefix, http.FileServer(noDirFS{http.Dir(storage.LocalDir)})))
+	}
+
 	chi.Mount("/", mux) //把gateway挂载到chi上，也就是请求先到chi，然后chi再根据这里的挂载规则转发到gateway
 
 	http_server := &http.Server{
@@ -48,3 +74,79 @@ func NewHTTPServer(c *conf.Server, logger *zap.Logger) (*BusinessHTTPServer, err
 	}
 	return &BusinessHTTPServer{Server: http_server}, nil
 }
+
+// noDirFS 访问目录时返回不存在，http.FileServer 不再列出目录内容，也不会返回目录下的 index.html
+type noDirFS struct {
+	fs http.FileSystem
+}
+
+func (n noDirFS) Open(name string) (http.File, error) {
+	f, err := n.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, fs.ErrNotExist
+	}
+	return f, nil
+}
+
+// jwksHandler 发布访问令牌的验签公钥，允许验签方缓存一段时间
+func jwksHandler(a auth.Auth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(a.JWKS())
+	}
+}
+
+// avatarUploadHandler 读取 multipart 表单中的 avatar 文件，转发到 UserService.UploadAvatar，
+// 请求头按 gateway 的规则转换为 gRPC metadata，响应格式与 gateway 一致
+func avatarUploadHandler(mux *runtime.ServeMux, client v1.UserServiceClient, errorHandler runtime.ErrorHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		fail := func(err error) {
+			errorHandler(r.Context(), mux, outbound, w, r, err)
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxAvatarRequestSize)
+		if err := r.ParseMultipartForm(maxAvatarRequestSize); err != nil {
+			fail(apperrors.ErrInvalidArgument.WithMessageKey("avatar_request", "头像图片过大或请求格式错误"))
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+		file, _, err := r.FormFile("avatar")
+		if err != nil {
+			fail(apperrors.ErrInvalidArgument.WithMessageKey("avatar_required", "请选择头像图片"))
+			return
+		}
+		defer file.Close()
+		image, err := io.ReadAll(file)
+		if err != nil {
+			fail(apperrors.ErrInvalidArgument.WithMessageKey("avatar_read", "读取头像图片失败"))
+			return
+		}
+
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, v1.UserService_UploadAvatar_FullMethodName,
+			runtime.WithHTTPPathPattern("/v1/user/avatar"))
+		if err != nil {
+			fail(err)
+			return
+		}
+		var md runtime.ServerMetadata
+		reply, err := client.UploadAvatar(ctx, &v1.UploadAvatarRequest{Image: image},
+			grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+		ctx = runtime.NewServerMetadataContext(ctx, md)
+		if err != nil {
+			fail(err)
+			return
+		}
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, reply)
+	}
+}
@@ -2,11 +2,12 @@ package intercepter
 
 import (
 	"context"
-	"slices"
+	"errors"
 	"strings"
 
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/code"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -19,42 +20,76 @@ const (
 	BearerScheme = "Bearer"
 )
 
-func AuthInterceptor(a auth.Auth) grpc.UnaryServerInterceptor {
+// AuthInterceptor 校验访问令牌，签名通过后再依次执行 checkers 做服务端状态检查，
+// 最后按 policies 中声明的角色检查访问权限。内部方法只接受 services 中的服务凭证，不接受用户的访问令牌
+func AuthInterceptor(a auth.Auth, policies auth.Policies, services *auth.ServiceCredentials,
+	checkers ...auth.ClaimsChecker) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		// 白名单
-		whiteList := a.GetWhiteList()
-
-		if slices.Contains(whiteList, info.FullMethod) {
+		// 公开方法
+		if policies.IsPublic(info.FullMethod) {
 			return handler(ctx, req)
 		}
 
 		// 解析token
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, apperrors.ErrTokenInvalid.WithMessage("authentication required").GrpcError()
+		tokenString, err := bearerToken(ctx)
+		if err != nil {
+			return nil, localize(ctx, code.FromError(err)).GrpcError()
 		}
 
-		authHeaders := md.Get(AuthorizationHeader)
-		if len(authHeaders) == 0 {
-			return nil, apperrors.ErrTokenInvalid.WithMessage("authentication required").GrpcError()
+		// 内部方法
+		if policies.IsInternal(info.FullMethod) {
+			name, ok := services.Authenticate(tokenString)
+			if !ok {
+				return nil, localize(ctx, apperrors.ErrPermissionDenied.WithMessageKey("service", "只允许内部服务调用")).GrpcError()
+			}
+			return handler(auth.ServiceToContext(ctx, name), req)
 		}
 
-		parts := strings.Split(authHeaders[0], " ")
-		if len(parts) != 2 || parts[0] != BearerScheme {
-			return nil, apperrors.ErrTokenInvalid.WithMessage("invalid token format").GrpcError()
+		// 判断token的
+		ctx, err = a.ParseAndSaveToken(ctx, tokenString)
+		if errors.Is(err, apperrors.ErrTokenExpired) {
+			return nil, localize(ctx, apperrors.ErrTokenExpired).GrpcError()
+		}
+		if err != nil {
+			return nil, localize(ctx, apperrors.ErrTokenInvalid.WithMessageKey("invalid", "Token 无效或已过期")).GrpcError()
 		}
 
-		tokenString := parts[1]
-		if tokenString == "" {
-			return nil, apperrors.ErrTokenInvalid.WithMessage("token is empty").GrpcError()
+		// 吊销等服务端状态检查
+		claims, _ := auth.FromContext(ctx)
+		for _, checker := range checkers {
+			if err := checker.Check(ctx, claims); err != nil {
+				return nil, localize(ctx, code.FromError(err)).GrpcError()
+			}
 		}
 
-		// 判断token的
-		ctx, err = a.ParseAndSaveToken(ctx, tokenString)
-		if err != nil {
-			return nil, apperrors.ErrTokenInvalid.WithMessage("invalid or expired token")
+		// 角色权限
+		if err := policies.Authorize(info.FullMethod, claims); err != nil {
+			return nil, localize(ctx, code.FromError(err)).GrpcError()
 		}
 
 		return handler(ctx, req)
 	}
 }
+
+// bearerToken 从 authorization 中读取 Bearer 之后的令牌
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", apperrors.ErrTokenInvalid.WithMessageKey("missing", "请先登录")
+	}
+
+	authHeaders := md.Get(AuthorizationHeader)
+	if len(authHeaders) == 0 {
+		return "", apperrors.ErrTokenInvalid.WithMessageKey("missing", "请先登录")
+	}
+
+	parts := strings.Split(authHeaders[0], " ")
+	if len(parts) != 2 || parts[0] != BearerScheme {
+		return "", apperrors.ErrTokenInvalid.WithMessageKey("format", "Token 格式错误")
+	}
+
+	if parts[1] == "" {
+		return "", apperrors.ErrTokenInvalid.WithMessageKey("empty", "Token 不能为空")
+	}
+	return parts[1], nil
+}
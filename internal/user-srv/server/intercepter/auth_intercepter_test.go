@@ -2,6 +2,8 @@ package intercepter_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,11 +13,31 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	"github.com/kyson/e-shop-native/internal/user-srv/data"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
 	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
 )
 
+// fakeVersionSource 返回固定的用户令牌版本
+type fakeVersionSource map[uint]uint
+
+func (f fakeVersionSource) GetTokenVersion(ctx context.Context, userID uint) (uint, error) {
+	return f[userID], nil
+}
+
+// fakeSessionSource 只有列出的会话有效
+type fakeSessionSource map[string]bool
+
+func (f fakeSessionSource) CheckSession(ctx context.Context, userID uint, sessionID string) error {
+	if !f[sessionID] {
+		return apperrors.ErrSessionNotFound
+	}
+	return nil
+}
+
 // TestAuthInterceptor_Unit a pure unit test for the interceptor logic.
 func TestAuthInterceptor_Unit(t *testing.T) {
 	// 1. Arrange (准备)
@@ -23,12 +45,28 @@ func TestAuthInterceptor_Unit(t *testing.T) {
 		Algorithm:      "HS256",
 		JwtKey:         "ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs=",
 		ExpireDuration: 3600,
-		Whitelist:      []string{"/test.Service/PublicMethod"},
 	}
-	authInstance := auth.NewAuth(mockConfig)
+	authInstance, err := auth.NewAuth(mockConfig)
+	require.NoError(t, err)
+	policies := auth.Policies{
+		"/test.Service/PublicMethod":   {Public: true},
+		"/test.Service/AdminMethod":    {Roles: []string{"admin", "support"}},
+		"/test.Service/InternalMethod": {Internal: true},
+	}
+	serviceKeyHash := sha256.Sum256([]byte("order-service-key"))
+	services, err := auth.NewServiceCredentials(&conf.Auth{Services: []conf.ServiceCredential{
+		{Name: "order-srv", KeyHash: hex.EncodeToString(serviceKeyHash[:])},
+	}})
+	require.NoError(t, err)
+	revocations := data.NewMemoryRevocationList()
 
 	// 获取拦截器函数
-	interceptor := intercepter.AuthInterceptor(authInstance)
+	versions := fakeVersionSource{42: 0, 7: 2}
+	interceptor := intercepter.AuthInterceptor(authInstance, policies, services,
+		auth.NewRevocationChecker(revocations),
+		auth.NewTokenVersionChecker(versions),
+		auth.NewSessionChecker(fakeSessionSource{"active-session": true}),
+	)
 
 	// --- 定义我们的测试用例 ---
 
@@ -39,9 +77,10 @@ func TestAuthInterceptor_Unit(t *testing.T) {
 		handlerShouldBeCalled bool            // 预期 handler 是否会被调用
 		expectedErrCode       codes.Code      // 预期返回的 gRPC 错误码
 		checkClaimsInCtx      bool            // 是否需要在 handler 中检查 claims
+		wantService           string          // handler 中预期的调用方服务
 	}{
 		{
-			name:                  "Whitelisted method should pass through",
+			name:                  "Public method should pass through",
 			fullMethod:            "/test.Service/PublicMethod",
 			ctx:                   context.Background(),
 			handlerShouldBeCalled: true,
@@ -87,6 +126,111 @@ func TestAuthInterceptor_Unit(t *testing.T) {
 			expectedErrCode:       codes.OK,
 			checkClaimsInCtx:      true,
 		},
+		{
+			name:       "Protected method with revoked token should fail",
+			fullMethod: "/test.Service/ProtectedMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser")
+				require.NoError(t, err)
+				ctx, err := authInstance.ParseAndSaveToken(context.Background(), token)
+				require.NoError(t, err)
+				claims, _ := auth.FromContext(ctx)
+				require.NoError(t, revocations.Revoke(context.Background(), claims.ID, claims.ExpiresAt.Time))
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.Unauthenticated,
+		},
+		{
+			name:       "Protected method with outdated token version should fail",
+			fullMethod: "/test.Service/ProtectedMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 7, "testuser", auth.WithTokenVersion(1))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.Unauthenticated,
+		},
+		{
+			name:       "Protected method with active session should pass",
+			fullMethod: "/test.Service/ProtectedMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser", auth.WithSessionID("active-session"))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: true,
+			expectedErrCode:       codes.OK,
+			checkClaimsInCtx:      true,
+		},
+		{
+			name:       "Protected method with revoked session should fail",
+			fullMethod: "/test.Service/ProtectedMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser", auth.WithSessionID("revoked-session"))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.Unauthenticated,
+		},
+		{
+			name:       "Admin method without required role should fail",
+			fullMethod: "/test.Service/AdminMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser", auth.WithRoles("customer"))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.PermissionDenied,
+		},
+		{
+			name:       "Admin method with required role should pass",
+			fullMethod: "/test.Service/AdminMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser", auth.WithRoles("customer", "support"))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: true,
+			expectedErrCode:       codes.OK,
+			checkClaimsInCtx:      true,
+		},
+		{
+			name:                  "Internal method with service key should pass",
+			fullMethod:            "/test.Service/InternalMethod",
+			ctx:                   metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer order-service-key")),
+			handlerShouldBeCalled: true,
+			expectedErrCode:       codes.OK,
+			wantService:           "order-srv",
+		},
+		{
+			name:       "Internal method with user token should fail",
+			fullMethod: "/test.Service/InternalMethod",
+			ctx: func() context.Context {
+				token, err := authInstance.GenerateToken(context.Background(), 42, "testuser", auth.WithRoles("admin"))
+				require.NoError(t, err)
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			}(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.PermissionDenied,
+		},
+		{
+			name:                  "Internal method without credentials should fail",
+			fullMethod:            "/test.Service/InternalMethod",
+			ctx:                   context.Background(),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.Unauthenticated,
+		},
+		{
+			name:                  "Protected method with service key should fail",
+			fullMethod:            "/test.Service/ProtectedMethod",
+			ctx:                   metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer order-service-key")),
+			handlerShouldBeCalled: false,
+			expectedErrCode:       codes.Unauthenticated,
+		},
 	}
 
 	for _, tt := range tests {
@@ -107,6 +251,12 @@ func TestAuthInterceptor_Unit(t *testing.T) {
 					assert.Equal(t, uint(42), claims.Id, "Claims should have correct user ID")
 				}
 
+				if tt.wantService != "" {
+					name, ok := auth.ServiceFromContext(ctx)
+					assert.True(t, ok, "Handler context should contain the calling service")
+					assert.Equal(t, tt.wantService, name)
+				}
+
 				return "handler response", nil
 			}
 
@@ -134,3 +284,34 @@ func TestAuthInterceptor_Unit(t *testing.T) {
 		})
 	}
 }
+
+// 过期的令牌返回 TOKEN_EXPIRED，客户端据此使用刷新令牌，签名错误的令牌返回 TOKEN_INVALID
+func TestAuthInterceptor_ExpiredToken(t *testing.T) {
+	a, err := auth.NewAuth(&conf.Auth{Algorithm: "HS256", JwtKey: "ahkPzSJ6auFD2WZHt5NFfixFSI3JmXm4isbTs8y29Zs=", ExpireDuration: -60})
+	require.NoError(t, err)
+	interceptor := intercepter.AuthInterceptor(a, auth.Policies{}, nil)
+	expired, err := a.GenerateToken(context.Background(), 42, "testuser")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{"过期的令牌", expired, v1.ErrorCode_TOKEN_EXPIRED.String()},
+		{"签名错误的过期令牌", expired + "x", v1.ErrorCode_TOKEN_INVALID.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tt.token))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/ProtectedMethod"},
+				func(ctx context.Context, req any) (any, error) { return nil, nil })
+			st, _ := status.FromError(err)
+			assert.Equal(t, codes.Unauthenticated, st.Code())
+			require.NotEmpty(t, st.Details())
+			detail, ok := st.Details()[0].(*v1.UserErr)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, detail.Code)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package intercepter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/kyson/e-shop-native/pkg/clientip"
+)
+
+// grpc-gateway 会把 HTTP 请求的来源地址追加到 x-forwarded-for 的末尾，前面的地址由客户端提供，可以伪造
+const ForwardedForKey = "x-forwarded-for"
+
+// ParseTrustedProxies 解析可信代理的 CIDR，单个 IP 视为 /32 或 /128
+func ParseTrustedProxies(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIPInterceptor 解析客户端 IP 并注入到 context 中，用于登录限流、审计日志等场景。
+// 只有连接的对端是可信代理（如同进程内的 gateway）时才读取 x-forwarded-for，
+// 并从右向左跳过可信代理，取第一个不可信的地址
+func ClientIPInterceptor(trustedProxies []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if ip := resolveClientIP(ctx, trustedProxies); ip != "" {
+			ctx = clientip.ToContext(ctx, ip)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func resolveClientIP(ctx context.Context, trustedProxies []netip.Prefix) string {
+	remote := peerIP(ctx)
+	if remote == "" || !isTrusted(remote, trustedProxies) {
+		return remote
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	hops := strings.Split(strings.Join(md.Get(ForwardedForKey), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrusted(hop, trustedProxies) {
+			return hop
+		}
+		remote = hop
+	}
+	// 所有地址都是可信代理时使用最左边的代理地址
+	return remote
+}
+
+// peerIP 连接的对端地址
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func isTrusted(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
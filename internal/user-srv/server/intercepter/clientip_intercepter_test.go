@@ -0,0 +1,65 @@
+package intercepter_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
+	"github.com/kyson/e-shop-native/pkg/clientip"
+)
+
+func TestClientIPInterceptor(t *testing.T) {
+	trusted, err := intercepter.ParseTrustedProxies([]string{"127.0.0.1", "::1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	interceptor := intercepter.ClientIPInterceptor(trusted)
+
+	tests := []struct {
+		name string
+		peer string
+		xff  []string
+		want string
+	}{
+		{"直接调用使用对端地址", "203.0.113.5:5000", nil, "203.0.113.5"},
+		{"不可信的对端忽略 x-forwarded-for", "203.0.113.5:5000", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"gateway 追加的地址", "127.0.0.1:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"客户端伪造的地址被忽略", "127.0.0.1:5000", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"跳过可信的负载均衡", "127.0.0.1:5000", []string{"1.2.3.4, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"多个请求头", "[::1]:5000", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"全部是可信代理", "127.0.0.1:5000", []string{"10.0.0.2, 10.0.0.3"}, "10.0.0.2"},
+		{"没有 x-forwarded-for", "127.0.0.1:5000", nil, "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+			require.NoError(t, err)
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			if tt.xff != nil {
+				md := metadata.MD{}
+				md.Append(intercepter.ForwardedForKey, tt.xff...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			var got string
+			_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				got, _ = clientip.FromContext(ctx)
+				return nil, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := intercepter.ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+	_, err = intercepter.ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
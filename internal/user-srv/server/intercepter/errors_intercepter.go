@@ -12,7 +12,7 @@ func ErrorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo,
 	resp, err = handler(ctx, req)
 	// 这里必须把所有的错误全部转换为grpc的标准错误
 	if err != nil {
-		return resp, code.FromError(err).GrpcError()
+		return resp, localize(ctx, code.FromError(err)).GrpcError()
 	}
 	return resp, err
 }
@@ -0,0 +1,36 @@
+package intercepter
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kyson/e-shop-native/pkg/code"
+	"github.com/kyson/e-shop-native/pkg/locale"
+)
+
+const (
+	// grpc-gateway 把 HTTP 请求的 Accept-Language 转发为 grpcgateway-accept-language
+	GatewayAcceptLanguageKey = "grpcgateway-accept-language"
+	AcceptLanguageKey        = "accept-language"
+)
+
+// LocaleInterceptor 按 Accept-Language 从已注册的消息目录中选择语言并注入到 context 中，
+// 返回的错误信息使用该语言
+func LocaleInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{GatewayAcceptLanguageKey, AcceptLanguageKey} {
+			if vals := md.Get(key); len(vals) > 0 && vals[0] != "" {
+				ctx = locale.ToContext(ctx, locale.Match(vals[0], code.Locales()...))
+				break
+			}
+		}
+	}
+	return handler(ctx, req)
+}
+
+// localize 按请求的语言翻译错误信息
+func localize(ctx context.Context, err code.Code) code.Code {
+	return err.Localize(locale.FromContext(ctx))
+}
@@ -0,0 +1,106 @@
+package intercepter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
+	"github.com/kyson/e-shop-native/pkg/code"
+)
+
+// 错误信息按 Accept-Language 翻译，没有匹配的语言时使用默认语言
+func TestLocaleInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	chain := func(err error) grpc.UnaryHandler {
+		return func(ctx context.Context, req any) (any, error) {
+			return intercepter.ErrorInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return nil, err
+			})
+		}
+	}
+
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		err     error
+		wantMsg string
+	}{
+		{
+			name:    "通过网关访问",
+			md:      metadata.Pairs(intercepter.GatewayAcceptLanguageKey, "en-US,en;q=0.9"),
+			err:     apperrors.ErrUserNotFound,
+			wantMsg: "User not found",
+		}, {
+			name:    "带参数的信息",
+			md:      metadata.Pairs(intercepter.AcceptLanguageKey, "en"),
+			err:     apperrors.ErrAccountLocked.WithMessageKey("retry_after", "登录失败次数过多，请 %d 秒后重试", 30),
+			wantMsg: "Too many failed login attempts, please try again in 30 seconds",
+		}, {
+			name:    "默认语言",
+			md:      metadata.Pairs(intercepter.AcceptLanguageKey, "zh-CN"),
+			err:     apperrors.ErrUserNotFound,
+			wantMsg: "用户不存在",
+		}, {
+			name:    "不支持的语言",
+			md:      metadata.Pairs(intercepter.AcceptLanguageKey, "fr"),
+			err:     apperrors.ErrUserNotFound,
+			wantMsg: "用户不存在",
+		}, {
+			name:    "固定的信息不翻译",
+			md:      metadata.Pairs(intercepter.AcceptLanguageKey, "en"),
+			err:     apperrors.ErrUserNotFound.WithMessage("自定义信息"),
+			wantMsg: "自定义信息",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := intercepter.LocaleInterceptor(ctx, nil, info, chain(tt.err))
+			s, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantMsg, s.Message())
+		})
+	}
+}
+
+// 参数校验失败的所有字段作为 google.rpc.BadRequest 返回，并按请求的语言翻译
+func TestErrorInterceptorFieldViolations(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	usernameErr := apperrors.ErrUsernameFormat.WithMessageKey("min", "用户名长度不能小于%s个字符", "3")
+	violationErr := usernameErr.WithViolations(
+		code.FieldViolation{Field: "username", Err: usernameErr},
+		code.FieldViolation{Field: "email", Err: apperrors.ErrEmailFormat},
+	)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(intercepter.AcceptLanguageKey, "en"))
+	_, err := intercepter.LocaleInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return intercepter.ErrorInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return nil, violationErr
+		})
+	})
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, "Username must be at least 3 characters", s.Message())
+
+	var badRequest *errdetails.BadRequest
+	for _, detail := range s.Details() {
+		if d, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = d
+		}
+	}
+	require.NotNil(t, badRequest)
+	require.Len(t, badRequest.FieldViolations, 2)
+	assert.Equal(t, "username", badRequest.FieldViolations[0].Field)
+	assert.Equal(t, "USERNAME_FORMAT_ERROR", badRequest.FieldViolations[0].Reason)
+	assert.Equal(t, "Username must be at least 3 characters", badRequest.FieldViolations[0].Description)
+	assert.Equal(t, "email", badRequest.FieldViolations[1].Field)
+	assert.Equal(t, "Invalid email address", badRequest.FieldViolations[1].Description)
+}
@@ -21,7 +21,7 @@ func RecoverInterceptor(log *zap.Logger) grpc.UnaryServerInterceptor {
 					zap.String("stacktrace", string(debug.Stack())),
 				)
 				// 返回一个grpc标准错误（这里使用的是命名返回值的形式）, 等同于 return nil, err
-				err = apperrors.ErrInternal.GrpcError()
+				err = localize(ctx, apperrors.ErrInternal).GrpcError()
 			}
 		}()
 		// 正常调用下一个handler
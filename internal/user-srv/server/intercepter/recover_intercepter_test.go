@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
 	intercepter "github.com/kyson/e-shop-native/internal/user-srv/server/intercepter"
 
 	"go.uber.org/zap"
@@ -100,8 +101,8 @@ func TestRecoverInterceptor_Unit(t *testing.T) {
 					t.Errorf("expected Internal error code, got: %v", s.Code())
 				}
 				// 验证错误消息
-				if s.Message() != "internal server error" {
-					t.Errorf("expected error message 'internal server error', got: %v", s.Message())
+				if s.Message() != apperrors.ErrInternal.Message() {
+					t.Errorf("expected error message '%s', got: %v", apperrors.ErrInternal.Message(), s.Message())
 				}
 			}
 
@@ -0,0 +1,30 @@
+package intercepter
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kyson/e-shop-native/pkg/useragent"
+)
+
+const (
+	// grpc-gateway 把 HTTP 请求的 User-Agent 转发为 grpcgateway-user-agent
+	GatewayUserAgentKey = "grpcgateway-user-agent"
+	UserAgentKey        = "user-agent"
+)
+
+// UserAgentInterceptor 解析客户端的 User-Agent 并注入到 context 中，用于审计日志
+func UserAgentInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// 通过网关访问时 user-agent 是网关自身的 gRPC 客户端，优先使用浏览器的 User-Agent
+		for _, key := range []string{GatewayUserAgentKey, UserAgentKey} {
+			if vals := md.Get(key); len(vals) > 0 && vals[0] != "" {
+				ctx = useragent.ToContext(ctx, vals[0])
+				break
+			}
+		}
+	}
+	return handler(ctx, req)
+}
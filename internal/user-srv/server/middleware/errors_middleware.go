@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 
@@ -9,8 +10,11 @@ import (
 	"go.uber.org/zap"
 
 	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	ecode "github.com/kyson/e-shop-native/pkg/code"
+	"github.com/kyson/e-shop-native/pkg/locale"
 
 	//"google.golang.org/grpc/codes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/status"
 )
 
@@ -19,6 +23,17 @@ import (
 
 func CustomErrorHandle(logger *zap.Logger) func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
 	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		// 0. 网关自身产生的业务错误（如头像上传）在这里按 Accept-Language 翻译，
+		// 来自 gRPC 服务的错误已经由服务端按转发的 Accept-Language 翻译过
+		lang := locale.Match(r.Header.Get("Accept-Language"), ecode.Locales()...)
+		var ec ecode.Code
+		if errors.As(err, &ec) {
+			err = ec.Localize(lang).GrpcError()
+		}
+		if lang != "" {
+			w.Header().Set("Content-Language", lang)
+		}
+
 		// 1. 将传入的 error 转换为 gRPC 的 status 对象
 		s := status.Convert(err)
 
@@ -27,13 +42,22 @@ func CustomErrorHandle(logger *zap.Logger) func(ctx context.Context, mux *runtim
 		msg := s.Message()
 
 		// 尝试从 status 中提取 details。
-		// 在我们的 ErrorInterceptor 中，我们将 ecode.Detail() 作为 details 附加了。
-		if len(s.Details()) > 0 {
-			details := s.Details()[0]
-			biz_err, ok := details.(*v1.UserErr)
-			if ok {
-				code = biz_err.Code
-				msg = biz_err.Message
+		// 在我们的 ErrorInterceptor 中，我们将 ecode.Detail() 作为 details 附加了，
+		// 参数校验失败时还会附加 google.rpc.BadRequest
+		var violations []*v1.FieldViolation
+		for _, detail := range s.Details() {
+			switch d := detail.(type) {
+			case *v1.UserErr:
+				code = d.Code
+				msg = d.Message
+			case *errdetails.BadRequest:
+				for _, fv := range d.GetFieldViolations() {
+					violations = append(violations, &v1.FieldViolation{
+						Field:   fv.GetField(),
+						Code:    fv.GetReason(),
+						Message: fv.GetDescription(),
+					})
+				}
 			}
 		}
 
@@ -43,8 +67,9 @@ func CustomErrorHandle(logger *zap.Logger) func(ctx context.Context, mux *runtim
 		// 4. 组装自定义的 JSON 错误响应体
 		// 这个结构可以根据您的前端需求进行调整
 		httpErr := &v1.UserErr{
-			Code:    code,
-			Message: msg,
+			Code:       code,
+			Message:    msg,
+			Violations: violations,
 		}
 
 		// 5. 使用 marshaler 将自定义错误结构序列化为 JSON
@@ -2,4 +2,4 @@ package server
 
 import "github.com/google/wire"
 
-var ProviderSet = wire.NewSet(NewHTTPServer, NewGRPCServer, NewAdminServer)
+var ProviderSet = wire.NewSet(NewHTTPServer, NewGRPCServer, NewAdminServer, NewClaimsCheckers)
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	"github.com/kyson/e-shop-native/internal/user-srv/auth"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+)
+
+type AdminUserService struct {
+	uc     biz.AdminUserService
+	tokens biz.TokenService
+	audit  biz.AuditService
+	v1.UnimplementedAdminUserServiceServer
+}
+
+func NewAdminUserService(uc biz.AdminUserService, tokens biz.TokenService, audit biz.AuditService) v1.AdminUserServiceServer {
+	return &AdminUserService{
+		uc:     uc,
+		tokens: tokens,
+		audit:  audit,
+	}
+}
+
+func (s *AdminUserService) ListUsers(ctx context.Context, req *v1.ListUsersRequest) (*v1.ListUsersReply, error) {
+	offset, limit := pageToOffset(req.Page, req.PageSize)
+	filter := &biz.UserFilter{
+		Keyword: req.Keyword,
+		Role:    req.Role,
+		Status:  biz.UserStatus(req.Status),
+		Offset:  offset,
+		Limit:   limit,
+	}
+	users, total, err := s.uc.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &v1.ListUsersReply{
+		Users: make([]*v1.AdminUser, 0, len(users)),
+		Total: total,
+	}
+	for _, user := range users {
+		reply.Users = append(reply.Users, toV1AdminUser(user))
+	}
+	return reply, nil
+}
+
+func (s *AdminUserService) GetUser(ctx context.Context, req *v1.GetUserRequest) (*v1.GetUserReply, error) {
+	user, err := s.uc.GetUser(ctx, uint(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &v1.GetUserReply{User: toV1AdminUser(user)}, nil
+}
+
+func (s *AdminUserService) DisableUser(ctx context.Context, req *v1.DisableUserRequest) (*v1.DisableUserReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	user, err := s.uc.DisableUser(ctx, claims.Id, uint(req.Id), req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	// 访问令牌由鉴权拦截器拒绝，刷新令牌在这里吊销
+	if err := s.tokens.RevokeAllRefreshTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return &v1.DisableUserReply{User: toV1AdminUser(user)}, nil
+}
+
+func (s *AdminUserService) EnableUser(ctx context.Context, req *v1.EnableUserRequest) (*v1.EnableUserReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	user, err := s.uc.EnableUser(ctx, claims.Id, uint(req.Id), req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EnableUserReply{User: toV1AdminUser(user)}, nil
+}
+
+func (s *AdminUserService) DeleteUser(ctx context.Context, req *v1.DeleteUserRequest) (*v1.DeleteUserReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	if err := s.uc.DeleteUser(ctx, claims.Id, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	if err := s.tokens.RevokeAllRefreshTokens(ctx, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	return &v1.DeleteUserReply{}, nil
+}
+
+func (s *AdminUserService) RestoreUser(ctx context.Context, req *v1.RestoreUserRequest) (*v1.RestoreUserReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	user, err := s.uc.RestoreUser(ctx, claims.Id, uint(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &v1.RestoreUserReply{User: toV1AdminUser(user)}, nil
+}
+
+func (s *AdminUserService) ListSecurityEvents(ctx context.Context, req *v1.ListSecurityEventsRequest) (*v1.ListSecurityEventsReply, error) {
+	offset, limit := pageToOffset(req.Page, req.PageSize)
+	events, total, err := s.audit.ListEvents(ctx, &biz.AuditFilter{
+		UserID:  uint(req.UserId),
+		ActorID: uint(req.ActorId),
+		Event:   logevent.Event(req.Event),
+		Offset:  offset,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ListSecurityEventsReply{
+		Events: toV1SecurityEvents(events),
+		Total:  total,
+	}, nil
+}
+
+// pageToOffset 把从 1 开始的页码转换为偏移量，分页大小的上限由 biz 层限制
+func pageToOffset(page, pageSize int32) (int, int) {
+	limit := int(pageSize)
+	if limit <= 0 {
+		limit = biz.DefaultPageSize
+	}
+	return (max(int(page), 1) - 1) * limit, limit
+}
+
+func toV1SecurityEvents(events []*biz.AuditEvent) []*v1.SecurityEvent {
+	out := make([]*v1.SecurityEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, &v1.SecurityEvent{
+			Id:        int64(e.ID),
+			UserId:    int32(e.UserID),
+			ActorId:   int32(e.ActorID),
+			Event:     e.Event.String(),
+			TraceId:   e.TraceID,
+			ClientIp:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			Detail:    e.Detail,
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		})
+	}
+	return out
+}
+
+func toV1AdminUser(user *biz.User) *v1.AdminUser {
+	u := &v1.AdminUser{
+		Id:             int32(user.ID),
+		Username:       user.UserName,
+		Email:          user.Email,
+		Phone:          user.Phone,
+		EmailVerified:  user.EmailVerifiedAt != nil,
+		PhoneVerified:  user.PhoneVerifiedAt != nil,
+		Roles:          user.Roles,
+		Status:         v1.UserStatus_USER_STATUS_ACTIVE,
+		DisabledReason: user.DisabledReason,
+		CreatedAt:      timestamppb.New(user.CreatedAt),
+	}
+	if user.DisabledAt != nil {
+		u.Status = v1.UserStatus_USER_STATUS_DISABLED
+	}
+	if user.DeletedAt != nil {
+		u.Status = v1.UserStatus_USER_STATUS_DELETED
+		u.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
+	return u
+}
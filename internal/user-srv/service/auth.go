@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	"github.com/kyson/e-shop-native/internal/user-srv/auth"
+)
+
+type AuthService struct {
+	introspector *auth.Introspector
+	v1.UnimplementedAuthServiceServer
+}
+
+func NewAuthService(introspector *auth.Introspector) v1.AuthServiceServer {
+	return &AuthService{introspector: introspector}
+}
+
+// Introspect 令牌不可用时只返回 active 为 false，不说明原因
+func (s *AuthService) Introspect(ctx context.Context, req *v1.IntrospectRequest) (*v1.IntrospectReply, error) {
+	claims, err := s.introspector.Introspect(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		return &v1.IntrospectReply{Active: false}, nil
+	}
+
+	reply := &v1.IntrospectReply{
+		Active:    true,
+		TokenType: "Bearer",
+		Sub:       strconv.FormatUint(uint64(claims.Id), 10),
+		Username:  claims.UserName,
+		Jti:       claims.ID,
+		UserId:    int32(claims.Id),
+		Roles:     claims.Roles,
+		SessionId: claims.SessionID,
+	}
+	if claims.ExpiresAt != nil {
+		reply.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		reply.Iat = claims.IssuedAt.Unix()
+	}
+	return reply, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+)
+
+type InternalUserService struct {
+	uc biz.InternalUserService
+	v1.UnimplementedInternalUserServiceServer
+}
+
+func NewInternalUserService(uc biz.InternalUserService) v1.InternalUserServiceServer {
+	return &InternalUserService{uc: uc}
+}
+
+func (s *InternalUserService) BatchGetUsers(ctx context.Context, req *v1.BatchGetUsersRequest) (*v1.BatchGetUsersReply, error) {
+	ids := make([]uint, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		ids = append(ids, uint(id))
+	}
+	users, missing, err := s.uc.BatchGetUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &v1.BatchGetUsersReply{
+		Users:      make(map[int32]*v1.UserSummary, len(users)),
+		MissingIds: make([]int32, 0, len(missing)),
+	}
+	for id, user := range users {
+		reply.Users[int32(id)] = toV1UserSummary(user)
+	}
+	for _, id := range missing {
+		reply.MissingIds = append(reply.MissingIds, int32(id))
+	}
+	return reply, nil
+}
+
+func toV1UserSummary(user *biz.User) *v1.UserSummary {
+	return &v1.UserSummary{
+		Id:                 int32(user.ID),
+		Username:           user.UserName,
+		Nickname:           user.Nickname,
+		AvatarThumbnailUrl: user.AvatarThumbnailURL,
+		Disabled:           user.DisabledAt != nil,
+	}
+}
@@ -2,69 +2,162 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
 	"github.com/kyson/e-shop-native/internal/user-srv/auth"
 	"github.com/kyson/e-shop-native/internal/user-srv/biz"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/logevent"
+	"github.com/kyson/e-shop-native/pkg/phone"
 )
 
 type UserService struct {
-	uc   biz.UserService
-	auth auth.Auth
+	uc          biz.UserService
+	tokens      biz.TokenService
+	resets      biz.PasswordResetService
+	contacts    biz.ContactVerificationService
+	sessions    biz.SessionService
+	twoFactor   biz.TwoFactorService
+	auth        auth.Auth
+	revocations auth.RevocationList
+	audit       biz.AuditService
+	avatars     biz.AvatarService
 	v1.UnimplementedUserServiceServer
 }
 
-func NewUserService(uc biz.UserService, auth auth.Auth) v1.UserServiceServer {
+func NewUserService(uc biz.UserService, tokens biz.TokenService, resets biz.PasswordResetService,
+	contacts biz.ContactVerificationService, sessions biz.SessionService, twoFactor biz.TwoFactorService,
+	auth auth.Auth, revocations auth.RevocationList, audit biz.AuditService, avatars biz.AvatarService) v1.UserServiceServer {
 	return &UserService{
-		uc:   uc,
-		auth: auth,
+		uc:          uc,
+		tokens:      tokens,
+		resets:      resets,
+		contacts:    contacts,
+		sessions:    sessions,
+		twoFactor:   twoFactor,
+		auth:        auth,
+		revocations: revocations,
+		audit:       audit,
+		avatars:     avatars,
 	}
 }
 
 func (s *UserService) Register(ctx context.Context, req *v1.RegisterRequest) (*v1.RegisterReply, error) {
 	user := &biz.User{
-		UserName: req.Username,
-		Password: req.Password,
-		Phone:    req.Phone,
-		Email:    req.Email,
+		UserName:    req.Username,
+		Password:    req.Password,
+		Phone:       req.Phone,
+		PhoneRegion: req.CountryCode,
+		Email:       req.Email,
 	}
 	_, err := s.uc.RegisterUser(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 	return &v1.RegisterReply{
-		User: &v1.User{
-			Id:       int32(user.ID),
-			Username: user.UserName,
-			Phone:    user.Phone,
-			Email:    user.Email,
-		},
+		User: toV1User(user),
 	}, nil
 }
 
 func (s *UserService) Login(ctx context.Context, req *v1.LoginRequest) (*v1.LoginReply, error) {
-	user, err := s.uc.Login(ctx, req.Username, req.Password)
+	// 兼容旧客户端，未传 identifier 时使用 username
+	identifier := req.Identifier
+	if identifier == "" {
+		identifier = req.Username
+	}
+	user, err := s.uc.Login(ctx, identifier, req.Password)
 	if err != nil {
 		return nil, err
 	}
-	// Token
-	token, err := s.auth.GenerateToken(ctx, uint(user.ID), user.UserName)
+
+	// 开启了两步验证时先返回挑战，通过 CompleteLoginChallenge 换取令牌
+	enabled, err := s.twoFactor.Enabled(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
+	if enabled {
+		challenge, err := s.twoFactor.StartLoginChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.LoginReply{
+			TwoFactorRequired: true,
+			Challenge:         challenge,
+		}, nil
+	}
+	if err := s.uc.CompleteLogin(ctx, user.ID); err != nil {
+		return nil, err
+	}
 
+	token, refreshToken, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
 	return &v1.LoginReply{
-		Token: token,
-		User: &v1.User{
-			Id:       int32(user.ID),
-			Username: user.UserName,
-			Phone:    user.Phone,
-			Email:    user.Email,
-		},
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.auth.GetExpireDuration().Seconds()),
+		User:         toV1User(user),
+	}, nil
+}
+
+func (s *UserService) CompleteLoginChallenge(ctx context.Context, req *v1.CompleteLoginChallengeRequest) (*v1.CompleteLoginChallengeReply, error) {
+	if req.Challenge == "" {
+		return nil, apperrors.ErrLoginChallengeInvalid
+	}
+	if req.Code == "" {
+		return nil, apperrors.ErrTwoFactorCodeInvalid
+	}
+	userID, err := s.twoFactor.CompleteLoginChallenge(ctx, req.Challenge, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// 挑战期间用户可能已被禁用
+	user, err := s.uc.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.ErrUserDisabled
+	}
+	if err := s.uc.CompleteLogin(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.CompleteLoginChallengeReply{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.auth.GetExpireDuration().Seconds()),
+		User:         toV1User(user),
 	}, nil
 }
 
+// startSession 创建一个新的会话，并签发绑定到该会话的访问令牌和刷新令牌
+func (s *UserService) startSession(ctx context.Context, user *biz.User) (string, string, error) {
+	session, err := s.sessions.Create(ctx, user.ID)
+	if err != nil {
+		return "", "", err
+	}
+	token, err := s.generateToken(ctx, user, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := s.tokens.IssueRefreshToken(ctx, user.ID, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
 func (s *UserService) GetMyProfile(ctx context.Context, req *v1.GetMyProfileRequest) (*v1.GetMyProfileReply, error) {
 	//这里没有验证Token的有效性，是因为如果每个方法都自己验证的话，就是灾难性的，应该在之前就被验证
 	//读取Token
@@ -78,11 +171,373 @@ func (s *UserService) GetMyProfile(ctx context.Context, req *v1.GetMyProfileRequ
 		return nil, err
 	}
 	return &v1.GetMyProfileReply{
-		User: &v1.User{
-			Id:       int32(user.ID),
-			Username: user.UserName,
-			Phone:    user.Phone,
-			Email:    user.Email,
-		},
+		User: toV1User(user),
+	}, nil
+}
+
+// update_mask 路径到 biz.User 字段名的映射
+var profileMaskFields = map[string]string{
+	"email":    "Email",
+	"phone":    "Phone",
+	"nickname": "Nickname",
+	"gender":   "Gender",
+	"birthday": "Birthday",
+}
+
+func (s *UserService) UpdateMyProfile(ctx context.Context, req *v1.UpdateMyProfileRequest) (*v1.UpdateMyProfileReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	if req.User == nil || len(req.UpdateMask.GetPaths()) == 0 {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("required", "%s 不能为空", "update_mask")
+	}
+
+	fields := make([]string, 0, len(req.UpdateMask.GetPaths()))
+	for _, path := range req.UpdateMask.GetPaths() {
+		field, ok := profileMaskFields[path]
+		if !ok {
+			return nil, apperrors.ErrInvalidArgument.WithMessageKey("unsupported_field", "不支持修改的字段: %s", path)
+		}
+		fields = append(fields, field)
+	}
+
+	update := &biz.User{
+		Phone:       req.User.Phone,
+		PhoneRegion: req.User.CountryCode,
+		Email:       req.User.Email,
+		Nickname:    req.User.Nickname,
+		Gender:      biz.Gender(req.User.Gender),
+	}
+	// 生日只在 update_mask 中包含时解析，空字符串表示清除
+	if slices.Contains(fields, "Birthday") {
+		birthday, err := biz.ParseBirthday(req.User.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		update.Birthday = birthday
+	}
+	user, err := s.uc.UpdateProfile(ctx, claims.Id, update, fields)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.UpdateMyProfileReply{
+		User: toV1User(user),
+	}, nil
+}
+
+func (s *UserService) UploadAvatar(ctx context.Context, req *v1.UploadAvatarRequest) (*v1.UploadAvatarReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+
+	user, err := s.avatars.UploadAvatar(ctx, claims.Id, req.Image)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.UploadAvatarReply{
+		User: toV1User(user),
 	}, nil
 }
+
+func (s *UserService) ChangePassword(ctx context.Context, req *v1.ChangePasswordRequest) (*v1.ChangePasswordReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+
+	// 修改密码后令牌版本递增，之前签发的访问令牌全部失效
+	user, err := s.uc.ChangePassword(ctx, claims.Id, req.OldPassword, req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	// 刷新令牌也全部吊销，其他设备需要重新登录
+	if err := s.tokens.RevokeAllRefreshTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if _, err := s.sessions.RevokeOthers(ctx, user.ID, claims.SessionID); err != nil {
+		return nil, err
+	}
+
+	// 为当前设备签发新的令牌，没有会话的旧令牌创建一个新的会话
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		session, err := s.sessions.Create(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = session.ID
+	}
+	token, err := s.generateToken(ctx, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.tokens.IssueRefreshToken(ctx, user.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ChangePasswordReply{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.auth.GetExpireDuration().Seconds()),
+	}, nil
+}
+
+func (s *UserService) RequestPasswordReset(ctx context.Context, req *v1.RequestPasswordResetRequest) (*v1.RequestPasswordResetReply, error) {
+	if req.Account == "" {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("required", "%s 不能为空", "account")
+	}
+	// 无论账号是否存在都返回成功，防止通过该接口探测账号
+	if err := s.resets.RequestReset(ctx, req.Account); err != nil {
+		return nil, err
+	}
+	return &v1.RequestPasswordResetReply{}, nil
+}
+
+func (s *UserService) ConfirmPasswordReset(ctx context.Context, req *v1.ConfirmPasswordResetRequest) (*v1.ConfirmPasswordResetReply, error) {
+	if req.Account == "" || req.Code == "" {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+	// 重置密码后令牌版本递增，之前签发的访问令牌全部失效
+	user, err := s.resets.ConfirmReset(ctx, req.Account, req.Code, req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	// 刷新令牌和会话也全部吊销，所有设备需要用新密码重新登录
+	if err := s.tokens.RevokeAllRefreshTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if _, err := s.sessions.RevokeOthers(ctx, user.ID, ""); err != nil {
+		return nil, err
+	}
+	return &v1.ConfirmPasswordResetReply{}, nil
+}
+
+func (s *UserService) SendVerificationCode(ctx context.Context, req *v1.SendVerificationCodeRequest) (*v1.SendVerificationCodeReply, error) {
+	if req.Target == "" {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("required", "%s 不能为空", "target")
+	}
+	if err := s.contacts.SendCode(ctx, req.Target); err != nil {
+		return nil, err
+	}
+	return &v1.SendVerificationCodeReply{}, nil
+}
+
+func (s *UserService) VerifyContact(ctx context.Context, req *v1.VerifyContactRequest) (*v1.VerifyContactReply, error) {
+	if req.Target == "" || req.Code == "" {
+		return nil, apperrors.ErrVerificationCodeInvalid
+	}
+	if _, err := s.contacts.Verify(ctx, req.Target, req.Code); err != nil {
+		return nil, err
+	}
+	return &v1.VerifyContactReply{}, nil
+}
+
+func (s *UserService) RefreshToken(ctx context.Context, req *v1.RefreshTokenRequest) (*v1.RefreshTokenReply, error) {
+	if req.RefreshToken == "" {
+		return nil, apperrors.ErrRefreshTokenInvalid
+	}
+	// 轮换刷新令牌，旧令牌被重放时整个 family 会被吊销
+	issued, refreshToken, err := s.tokens.RotateRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	// 刷新令牌的 family ID 即会话ID，同时更新会话的最近活跃时间
+	if err := s.sessions.CheckSession(ctx, issued.UserID, issued.FamilyID); err != nil {
+		if errors.Is(err, apperrors.ErrSessionNotFound) {
+			return nil, apperrors.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	// 重新读取用户信息签发访问令牌，被禁用的用户不能续期
+	user, err := s.uc.GetMyProfile(ctx, issued.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.ErrUserDisabled
+	}
+	token, err := s.generateToken(ctx, user, issued.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.RefreshTokenReply{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.auth.GetExpireDuration().Seconds()),
+	}, nil
+}
+
+func (s *UserService) Logout(ctx context.Context, req *v1.LogoutRequest) (*v1.LogoutReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+
+	// 吊销当前访问令牌，记录在令牌过期后自动清除
+	if err := s.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	// 注销当前会话，会话的刷新令牌一并吊销
+	if claims.SessionID != "" {
+		err := s.sessions.Revoke(ctx, claims.Id, claims.SessionID)
+		if err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+			return nil, err
+		}
+	}
+
+	// 同时吊销刷新令牌，防止用它换取新的访问令牌
+	if req.RefreshToken != "" {
+		if err := s.tokens.RevokeRefreshToken(ctx, claims.Id, req.RefreshToken); err != nil {
+			return nil, err
+		}
+	}
+	s.audit.Record(ctx, &biz.AuditEvent{UserID: claims.Id, ActorID: claims.Id, Event: logevent.EventUserLogout})
+	return &v1.LogoutReply{}, nil
+}
+
+func (s *UserService) ListMySecurityEvents(ctx context.Context, req *v1.ListMySecurityEventsRequest) (*v1.ListMySecurityEventsReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+
+	offset, limit := pageToOffset(req.Page, req.PageSize)
+	events, total, err := s.audit.ListEvents(ctx, &biz.AuditFilter{
+		UserID: claims.Id,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ListMySecurityEventsReply{
+		Events: toV1SecurityEvents(events),
+		Total:  total,
+	}, nil
+}
+
+func (s *UserService) ListMySessions(ctx context.Context, req *v1.ListMySessionsRequest) (*v1.ListMySessionsReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+
+	sessions, err := s.sessions.List(ctx, claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	reply := &v1.ListMySessionsReply{Sessions: make([]*v1.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		reply.Sessions = append(reply.Sessions, &v1.Session{
+			Id:         session.ID,
+			DeviceName: session.DeviceName,
+			ClientIp:   session.ClientIP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastSeenAt: timestamppb.New(session.LastSeenAt),
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+	return reply, nil
+}
+
+func (s *UserService) RevokeSession(ctx context.Context, req *v1.RevokeSessionRequest) (*v1.RevokeSessionReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	if req.Id == "" {
+		return nil, apperrors.ErrInvalidArgument.WithMessageKey("required", "%s 不能为空", "id")
+	}
+
+	// 会话的访问令牌由鉴权拦截器立即拒绝，刷新令牌在这里一并吊销
+	if err := s.sessions.Revoke(ctx, claims.Id, req.Id); err != nil {
+		return nil, err
+	}
+	return &v1.RevokeSessionReply{}, nil
+}
+
+func (s *UserService) RevokeAllOtherSessions(ctx context.Context, req *v1.RevokeAllOtherSessionsRequest) (*v1.RevokeAllOtherSessionsReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	// 没有会话ID的旧令牌无法区分当前会话
+	if claims.SessionID == "" {
+		return nil, apperrors.ErrTokenRevoked
+	}
+
+	revoked, err := s.sessions.RevokeOthers(ctx, claims.Id, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.RevokeAllOtherSessionsReply{Revoked: int32(revoked)}, nil
+}
+
+func (s *UserService) EnrollTwoFactor(ctx context.Context, req *v1.EnrollTwoFactorRequest) (*v1.EnrollTwoFactorReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	secret, uri, err := s.twoFactor.Enroll(ctx, claims.Id, claims.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EnrollTwoFactorReply{Secret: secret, Uri: uri}, nil
+}
+
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, req *v1.ConfirmTwoFactorRequest) (*v1.ConfirmTwoFactorReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	codes, err := s.twoFactor.Confirm(ctx, claims.Id, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ConfirmTwoFactorReply{RecoveryCodes: codes}, nil
+}
+
+func (s *UserService) DisableTwoFactor(ctx context.Context, req *v1.DisableTwoFactorRequest) (*v1.DisableTwoFactorReply, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	if err := s.twoFactor.Disable(ctx, claims.Id, req.Code); err != nil {
+		return nil, err
+	}
+	return &v1.DisableTwoFactorReply{}, nil
+}
+
+// generateToken 为用户签发绑定到会话的访问令牌
+func (s *UserService) generateToken(ctx context.Context, user *biz.User, sessionID string) (string, error) {
+	return s.auth.GenerateToken(ctx, user.ID, user.UserName,
+		auth.WithTokenVersion(user.TokenVersion),
+		auth.WithRoles(user.Roles...),
+		auth.WithSessionID(sessionID),
+	)
+}
+
+func toV1User(user *biz.User) *v1.User {
+	return &v1.User{
+		Id:            int32(user.ID),
+		Username:      user.UserName,
+		Phone:         user.Phone,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+		CountryCode:   phone.RegionOf(user.Phone),
+		PhoneDisplay:  phone.Format(user.Phone),
+
+		Nickname:           user.Nickname,
+		Gender:             v1.Gender(user.Gender),
+		Birthday:           biz.FormatBirthday(user.Birthday),
+		AvatarUrl:          user.AvatarURL,
+		AvatarThumbnailUrl: user.AvatarThumbnailURL,
+	}
+}
@@ -0,0 +1,436 @@
+package validator
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/breached_passwords.txt
+var breachedPasswordsData string
+
+//go:embed data/common_words.txt
+var commonWordsData string
+
+var (
+	// breachedPasswords 泄露过的常用密码，完全相同时直接拒绝
+	breachedPasswords = loadWordList(breachedPasswordsData)
+	// dictionary 估算强度时使用的字典，值为按频率排序的名次
+	dictionary = mergeWordLists(loadWordList(commonWordsData), breachedPasswords)
+)
+
+// 密码强度等级，与 conf.Password.MinStrength 对应
+const (
+	StrengthVeryWeak = iota
+	StrengthWeak
+	StrengthFair
+	StrengthStrong
+	StrengthVeryStrong
+)
+
+// 各强度等级需要的最小熵，位
+var strengthThresholds = []float64{20, 30, 40, 50}
+
+// PasswordStrength 密码强度的估算结果
+type PasswordStrength struct {
+	Score    int     // 0-4，见 StrengthVeryWeak 等
+	Entropy  float64 // 估算的熵，位
+	Feedback string  // 强度最低的部分的说明，用于提示用户
+	hint     hint
+}
+
+// hint 强度不足的说明，key 和 format 用于 ErrPasswordFormat.WithMessageKey，以便按请求的语言显示
+type hint struct {
+	key    string
+	format string
+	args   []any
+}
+
+func newStrength(score int, entropy float64, h hint) PasswordStrength {
+	feedback := h.format
+	if len(h.args) > 0 {
+		feedback = fmt.Sprintf(h.format, h.args...)
+	}
+	return PasswordStrength{Score: score, Entropy: entropy, Feedback: feedback, hint: h}
+}
+
+type matchKind int
+
+const (
+	matchUserInput matchKind = iota + 1
+	matchDictionary
+	matchSequence
+	matchKeyboard
+	matchRepeat
+)
+
+// match 密码中 [start, end) 范围内可以被猜出来的部分，bits 为猜出这一部分需要的熵
+type match struct {
+	kind       matchKind
+	start, end int
+	bits       float64
+	token      string
+}
+
+// IsBreachedPassword 密码是否在泄露的常用密码列表中，不区分大小写
+func IsBreachedPassword(password string) bool {
+	_, ok := breachedPasswords[strings.ToLower(password)]
+	return ok
+}
+
+// EstimatePasswordStrength 按攻击者的猜测方式估算密码的熵：字典单词、连续字符、键盘相邻按键、
+// 重复字符以及用户名、邮箱这些容易被猜到的部分只计算很少的熵，其余字符按字符集大小计算，
+// 取所有划分方式中熵最小的一种。userInputs 为用户名、邮箱等与用户相关的信息
+func EstimatePasswordStrength(password string, userInputs ...string) PasswordStrength {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return newStrength(StrengthVeryWeak, 0, hint{key: "required", format: "密码不能为空"})
+	}
+	lower := []rune(strings.ToLower(password))
+	unleet := []rune(unleetString(string(lower)))
+
+	var matches []match
+	matches = append(matches, userInputMatches(unleet, userInputs)...)
+	matches = append(matches, dictionaryMatches(runes, lower, unleet)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, repeatMatches(runes, charsetBits(runes))...)
+
+	entropy, chosen := minimumEntropy(runes, matches)
+	score := StrengthVeryWeak
+	for _, threshold := range strengthThresholds {
+		if entropy >= threshold {
+			score++
+		}
+	}
+	return newStrength(score, entropy, feedback(chosen))
+}
+
+// minimumEntropy 动态规划求覆盖整个密码的最小熵，没有被任何模式覆盖的字符按字符集计算
+func minimumEntropy(runes []rune, matches []match) (float64, []match) {
+	n := len(runes)
+	perChar := charsetBits(runes)
+	best := make([]float64, n+1)
+	via := make([]*match, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = best[i-1] + perChar
+		via[i] = nil
+		for j := range matches {
+			m := &matches[j]
+			if m.end == i && best[m.start]+m.bits < best[i] {
+				best[i] = best[m.start] + m.bits
+				via[i] = m
+			}
+		}
+	}
+
+	var chosen []match
+	for i := n; i > 0; {
+		if via[i] == nil {
+			i--
+			continue
+		}
+		chosen = append(chosen, *via[i])
+		i = via[i].start
+	}
+	return best[n], chosen
+}
+
+// charsetBits 按密码中出现的字符类别估算每个字符的熵
+func charsetBits(runes []rune) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, c := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.present {
+			size += c.size
+		}
+	}
+	return math.Log2(float64(size))
+}
+
+// leetTable 常见的字母替换
+var leetTable = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't',
+	'@': 'a', '$': 's', '!': 'i',
+}
+
+func unleetString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if c, ok := leetTable[r]; ok {
+			return c
+		}
+		return r
+	}, s)
+}
+
+// userInputMatches 密码中包含用户名、邮箱或邮箱的用户名部分
+func userInputMatches(unleet []rune, userInputs []string) []match {
+	var matches []match
+	for _, input := range expandUserInputs(userInputs) {
+		token := []rune(input)
+		for i := 0; i+len(token) <= len(unleet); i++ {
+			if string(unleet[i:i+len(token)]) == input {
+				matches = append(matches, match{kind: matchUserInput, start: i, end: i + len(token), bits: 1, token: input})
+			}
+		}
+	}
+	return matches
+}
+
+func expandUserInputs(userInputs []string) []string {
+	var inputs []string
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			inputs = append(inputs, unleetString(local))
+		}
+		inputs = append(inputs, unleetString(input))
+	}
+	// 太短的信息没有意义
+	result := inputs[:0]
+	for _, input := range inputs {
+		if len([]rune(input)) >= 3 {
+			result = append(result, input)
+		}
+	}
+	return result
+}
+
+// dictionaryMatches 长度至少为 4 的字典单词，包括大小写变化和字母替换
+func dictionaryMatches(runes, lower, unleet []rune) []match {
+	var matches []match
+	for i := range lower {
+		for j := i + 4; j <= len(lower); j++ {
+			token := string(lower[i:j])
+			rank, ok := dictionary[token]
+			substituted := false
+			if !ok {
+				token = string(unleet[i:j])
+				if rank, ok = dictionary[token]; !ok {
+					continue
+				}
+				substituted = true
+			}
+			bits := math.Log2(float64(rank))
+			if string(runes[i:j]) != string(lower[i:j]) {
+				bits++ // 大小写变化
+			}
+			if substituted {
+				bits++ // 字母替换
+			}
+			matches = append(matches, match{kind: matchDictionary, start: i, end: j, bits: max(bits, 1), token: token})
+		}
+	}
+	return matches
+}
+
+// sequenceMatches 长度至少为 3 的连续字母或数字，如 abc、321
+func sequenceMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i < len(lower)-2; {
+		delta := lower[i+1] - lower[i]
+		if (delta != 1 && delta != -1) || !sameSequenceClass(lower[i], lower[i+1]) {
+			i++
+			continue
+		}
+		j := i + 2
+		for j < len(lower) && lower[j]-lower[j-1] == delta && sameSequenceClass(lower[j-1], lower[j]) {
+			j++
+		}
+		if j-i >= 3 {
+			base := 26.0
+			if unicode.IsDigit(lower[i]) {
+				base = 10
+			}
+			bits := math.Log2(base) + math.Log2(float64(j-i))
+			if delta < 0 {
+				bits++
+			}
+			matches = append(matches, match{kind: matchSequence, start: i, end: j, bits: bits, token: string(lower[i:j])})
+		}
+		i = j - 1
+	}
+	return matches
+}
+
+func sameSequenceClass(a, b rune) bool {
+	isLetter := func(r rune) bool { return r >= 'a' && r <= 'z' }
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	return (isLetter(a) && isLetter(b)) || (isDigit(a) && isDigit(b))
+}
+
+// qwertyRows 美式键盘布局，下一行的第 c 个键与上一行的第 c、c+1 个键相邻
+var qwertyRows = []string{
+	"1234567890-=",
+	"qwertyuiop[]",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// shiftedKeys 按住 Shift 输入的字符对应的按键
+var shiftedKeys = map[rune]rune{
+	'!': '1', '@': '2', '#': '3', '$': '4', '%': '5', '^': '6', '&': '7', '*': '8', '(': '9', ')': '0',
+	'_': '-', '+': '=', '{': '[', '}': ']', ':': ';', '"': '\'', '<': ',', '>': '.', '?': '/',
+}
+
+type keyPosition struct{ row, col int }
+
+var keyPositions = func() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for row, keys := range qwertyRows {
+		for col, key := range keys {
+			positions[key] = keyPosition{row, col}
+		}
+	}
+	return positions
+}()
+
+func keyAdjacent(a, b rune) (keyPosition, bool) {
+	if s, ok := shiftedKeys[a]; ok {
+		a = s
+	}
+	if s, ok := shiftedKeys[b]; ok {
+		b = s
+	}
+	pa, ok := keyPositions[a]
+	if !ok {
+		return keyPosition{}, false
+	}
+	pb, ok := keyPositions[b]
+	if !ok {
+		return keyPosition{}, false
+	}
+	d := keyPosition{pb.row - pa.row, pb.col - pa.col}
+	switch d {
+	case keyPosition{0, 1}, keyPosition{0, -1}, keyPosition{-1, 0}, keyPosition{-1, 1}, keyPosition{1, 0}, keyPosition{1, -1}:
+		return d, true
+	}
+	return d, false
+}
+
+// keyboardMatches 长度至少为 4 的键盘相邻按键，如 qwer、1qaz，每次改变方向多计算 1 位熵
+func keyboardMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i < len(lower)-1; {
+		if _, ok := keyAdjacent(lower[i], lower[i+1]); !ok {
+			i++
+			continue
+		}
+		turns := 0
+		direction, _ := keyAdjacent(lower[i], lower[i+1])
+		j := i + 2
+		for ; j < len(lower); j++ {
+			d, ok := keyAdjacent(lower[j-1], lower[j])
+			if !ok {
+				break
+			}
+			if d != direction {
+				turns++
+				direction = d
+			}
+		}
+		if j-i >= 4 {
+			bits := math.Log2(float64(len(keyPositions))) + math.Log2(float64(j-i)) + float64(turns)
+			matches = append(matches, match{kind: matchKeyboard, start: i, end: j, bits: bits, token: string(lower[i:j])})
+		}
+		i = j - 1
+	}
+	return matches
+}
+
+// repeatMatches 长度至少为 3 的相同字符，如 aaa
+func repeatMatches(runes []rune, perChar float64) []match {
+	var matches []match
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 {
+			bits := perChar + math.Log2(float64(j-i))
+			matches = append(matches, match{kind: matchRepeat, start: i, end: j, bits: bits, token: string(runes[i:j])})
+		}
+		i = j
+	}
+	return matches
+}
+
+// feedback 优先说明与用户信息相关的部分，其次是最长的可猜测部分
+func feedback(chosen []match) hint {
+	var worst *match
+	for i := range chosen {
+		m := &chosen[i]
+		switch {
+		case worst == nil:
+			worst = m
+		case m.kind == matchUserInput && worst.kind != matchUserInput:
+			worst = m
+		case worst.kind != matchUserInput && m.end-m.start > worst.end-worst.start:
+			worst = m
+		}
+	}
+	if worst == nil {
+		return hint{key: "weak", format: "密码强度不足，请使用更长、更不规则的密码"}
+	}
+	switch worst.kind {
+	case matchUserInput:
+		return hint{key: "user_input", format: "密码不能包含用户名或邮箱"}
+	case matchDictionary:
+		return hint{key: "dictionary", format: "密码包含常见单词 %s，容易被猜到", args: []any{worst.token}}
+	case matchSequence:
+		return hint{key: "sequence", format: "密码包含连续的字母或数字（如 abc、123），容易被猜到"}
+	case matchKeyboard:
+		return hint{key: "keyboard", format: "密码包含键盘上相邻的按键（如 qwerty、1qaz），容易被猜到"}
+	default:
+		return hint{key: "repeat", format: "密码包含重复的字符（如 aaa），容易被猜到"}
+	}
+}
+
+func loadWordList(data string) map[string]int {
+	words := make(map[string]int)
+	rank := 0
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ok := words[line]; ok {
+			continue
+		}
+		rank++
+		words[line] = rank
+	}
+	return words
+}
+
+// mergeWordLists 合并字典，同一个单词取最靠前的名次
+func mergeWordLists(lists ...map[string]int) map[string]int {
+	merged := make(map[string]int)
+	for _, list := range lists {
+		for word, rank := range list {
+			if existing, ok := merged[word]; !ok || rank < existing {
+				merged[word] = rank
+			}
+		}
+	}
+	return merged
+}
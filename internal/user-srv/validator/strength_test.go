@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
+	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+)
+
+// 测试密码强度估算
+func TestEstimatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		wantWeak   bool
+		feedback   string
+	}{
+		{"常见单词加数字", "Password1", nil, true, "密码包含常见单词 password，容易被猜到"},
+		{"字母替换", "Pa55w0rd2024", nil, true, "密码包含常见单词 password，容易被猜到"},
+		{"连续字符", "Abcdefgh1234", nil, true, "密码包含连续的字母或数字（如 abc、123），容易被猜到"},
+		{"键盘相邻按键", "Qwsxcderfv12", nil, true, "密码包含键盘上相邻的按键（如 qwerty、1qaz），容易被猜到"},
+		{"重复字符", "Aaaaaaaaaaa1", nil, true, "密码包含重复的字符（如 aaa），容易被猜到"},
+		{"包含用户名", "Kyson_2024x", []string{"kyson_2024", "k@example.com"}, true, "密码不能包含用户名或邮箱"},
+		{"包含邮箱用户名", "Lucy.Ho!99", []string{"someone", "lucy.ho@example.com"}, true, "密码不能包含用户名或邮箱"},
+		{"随机字符", "Gx7#mQ2vLp9w", nil, false, ""},
+		{"较长的不规则密码", "Tr0ub4dor&3Horse", nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strength := EstimatePasswordStrength(tt.password, tt.userInputs...)
+			assert.Equal(t, tt.wantWeak, strength.Score < StrengthStrong, "entropy %.1f", strength.Entropy)
+			if tt.wantWeak {
+				assert.Equal(t, tt.feedback, strength.Feedback)
+			}
+		})
+	}
+}
+
+// 泄露过的常用密码不区分大小写
+func TestIsBreachedPassword(t *testing.T) {
+	assert.True(t, IsBreachedPassword("password1"))
+	assert.True(t, IsBreachedPassword("Password1"))
+	assert.True(t, IsBreachedPassword("P@ssw0rd"))
+	assert.False(t, IsBreachedPassword("Gx7#mQ2vLp9w"))
+}
+
+// 注册和修改密码时检查密码强度
+func TestValidatePasswordStrength(t *testing.T) {
+	v := newTestValidator(t, &conf.Password{MinStrength: StrengthStrong})
+	user := &biz.User{UserName: "testuser123", Password: "Password1", Phone: "+8613800138000", Email: "test@example.com"}
+	assert.Equal(t, withViolation("password", apperrors.ErrPasswordFormat.WithMessageKey("breached", "该密码是已泄露的常用密码，请更换")),
+		v.Validate(user))
+
+	user.Password = "Testuser123!"
+	assert.Equal(t, withViolation("password", apperrors.ErrPasswordFormat.WithMessageKey("user_input", "密码不能包含用户名或邮箱")),
+		v.ValidatePartial(user, "Password"))
+
+	// 不校验密码时不检查强度
+	assert.NoError(t, v.ValidatePartial(user, "Email"))
+
+	// 最低强度为 0 时只拒绝泄露过的密码
+	v = newTestValidator(t, &conf.Password{})
+	assert.NoError(t, v.ValidatePartial(user, "Password"))
+	user.Password = "Password1"
+	assert.Error(t, v.ValidatePartial(user, "Password"))
+}
@@ -1,165 +1,283 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
-	"sync"
+	"slices"
+	"strings"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 
 	"github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/code"
+	"github.com/kyson/e-shop-native/pkg/phone"
 )
 
-type ValidatorUsecase struct{}
-
-func NewValidator() biz.UserValidator {
-	return &ValidatorUsecase{}
+type ValidatorUsecase struct {
+	validate *validator.Validate
+	policy   *conf.Validation
+	// 密码的最低强度等级，0 表示只拒绝泄露过的常用密码
+	minStrength int
+	// 允许的手机号地区，以及没有国家码时使用的默认地区
+	phones *phone.Parser
 }
 
-func (v *ValidatorUsecase) Validate(user *biz.User) error {
-	validate, err := getValidator()
-	if err != nil {
-		return err
+// usernamePattern 用户名只允许字母、数字和下划线
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// NewValidator 按 conf.Validation 中的规则创建校验器，规则不合法时返回错误
+func NewValidator(c *conf.Password, vc *conf.Validation) (biz.UserValidator, error) {
+	if err := checkPolicy(vc); err != nil {
+		return nil, fmt.Errorf("invalid validation config: %w", err)
 	}
-	err = validate.Struct(user)
+	phones, err := phone.NewParser(vc.Phone.DefaultRegion, vc.Phone.Regions...)
 	if err != nil {
-		return TranslateValidationError(err)
+		return nil, fmt.Errorf("invalid validation config: %w", err)
 	}
-	return nil
+	v := &ValidatorUsecase{
+		validate:    validator.New(),
+		policy:      vc,
+		minStrength: c.MinStrength,
+		phones:      phones,
+	}
+	if err := v.register(); err != nil {
+		return nil, fmt.Errorf("failed to register validation: %w", err)
+	}
+	return v, nil
 }
 
-var (
-	validate *validator.Validate
-	once     sync.Once
-)
+func checkPolicy(vc *conf.Validation) error {
+	switch {
+	case vc == nil || vc.Username == nil || vc.Password == nil || vc.Phone == nil:
+		return errors.New("username, password and phone rules are required")
+	case vc.Username.MinLength <= 0 || vc.Username.MinLength > vc.Username.MaxLength:
+		return fmt.Errorf("username length %d-%d is invalid", vc.Username.MinLength, vc.Username.MaxLength)
+	case vc.Password.MinLength <= 0 || vc.Password.MinLength > vc.Password.MaxLength:
+		return fmt.Errorf("password length %d-%d is invalid", vc.Password.MinLength, vc.Password.MaxLength)
+	case vc.NicknameMaxLength <= 0:
+		return fmt.Errorf("nickname max length %d is invalid", vc.NicknameMaxLength)
+	}
+	return nil
+}
 
-func getValidator() (*validator.Validate, error) {
-	var err error
-	// validator.New() 是一个昂贵的、一次性的初始化操作。如果在每次HTTP请求或每次需要验证时都去调用 validator.New()，
-	// 将会带来巨大且完全不必要的性能开销。
-	once.Do(func() {
-		validate = validator.New()
-		// 注册自定义验证器
-		err = validate.RegisterValidation("username", ValidateUsername)
-		if err != nil {
-			return
+// register 注册自定义的校验函数，并按规则生成 biz.User 各字段的校验标签
+func (v *ValidatorUsecase) register() error {
+	validations := map[string]validator.Func{
+		"username":        v.validateUsername,
+		"password":        validatePasswordCharset,
+		"password_upper":  containsRune(unicode.IsUpper),
+		"password_lower":  containsRune(unicode.IsLower),
+		"password_digit":  containsRune(unicode.IsDigit),
+		"password_symbol": containsRune(isSymbol),
+		"phone":           v.validatePhone,
+	}
+	for tag, fn := range validations {
+		if err := v.validate.RegisterValidation(tag, fn); err != nil {
+			return err
 		}
-		err = validate.RegisterValidation("password", ValidatePassword)
-		if err != nil {
-			return
+	}
+
+	p := v.policy
+	password := fmt.Sprintf("required,min=%d,max=%d,password", p.Password.MinLength, p.Password.MaxLength)
+	for _, rule := range []struct {
+		tag      string
+		required bool
+	}{
+		{"password_upper", p.Password.RequireUpper},
+		{"password_lower", p.Password.RequireLower},
+		{"password_digit", p.Password.RequireDigit},
+		{"password_symbol", p.Password.RequireSymbol},
+	} {
+		if rule.required {
+			password += "," + rule.tag
 		}
-		err = validate.RegisterValidation("phone", ValidatePhone)
-	})
-	return validate, fmt.Errorf("failed to register validation: %w", err)
+	}
+	v.validate.RegisterStructValidationMapRules(map[string]string{
+		"UserName": fmt.Sprintf("required,min=%d,max=%d,username", p.Username.MinLength, p.Username.MaxLength),
+		"Password": password,
+		"Phone":    "required,phone",
+		"Email":    "required,email",
+		"Nickname": fmt.Sprintf("max=%d", p.NicknameMaxLength),
+	}, biz.User{})
+	return nil
 }
 
-func ValidateUsername(fl validator.FieldLevel) bool {
-	username := fl.Field().String()
+func (v *ValidatorUsecase) Validate(user *biz.User) error {
+	if err := v.validate.Struct(user); err != nil {
+		return TranslateValidationError(err)
+	}
+	return v.checkPasswordStrength(user)
+}
 
-	// 只允许字母、数字和下划线，长度3-20个字符
-	// if len(username) < 3 || len(username) > 20 {
-	// 	return false
-	// }
-	//regular := `^[a-zA-Z0-9_]{3,20}$` // 只允许字母、数字和下划线，限制大小
-	regular := `^[a-zA-Z0-9_]+$` // 只允许字母、数字和下划线
-	matched, err := regexp.MatchString(regular, username)
-	if err != nil {
-		return false
+func (v *ValidatorUsecase) ValidatePartial(user *biz.User, fields ...string) error {
+	if err := v.validate.StructPartial(user, fields...); err != nil {
+		return TranslateValidationError(err)
 	}
-	return matched
+	if slices.Contains(fields, "Password") {
+		return v.checkPasswordStrength(user)
+	}
+	return nil
+}
+
+// checkPasswordStrength 格式校验通过后再检查密码是否泄露过以及强度是否足够，
+// user 中的用户名、邮箱用于判断密码是否包含个人信息
+func (v *ValidatorUsecase) checkPasswordStrength(user *biz.User) error {
+	if IsBreachedPassword(user.Password) {
+		return withViolation("password", apperrors.ErrPasswordFormat.WithMessageKey("breached", "该密码是已泄露的常用密码，请更换"))
+	}
+	if v.minStrength <= 0 {
+		return nil
+	}
+	strength := EstimatePasswordStrength(user.Password, user.UserName, user.Email)
+	if strength.Score < v.minStrength {
+		return withViolation("password", apperrors.ErrPasswordFormat.WithMessageKey(strength.hint.key, strength.hint.format, strength.hint.args...))
+	}
+	return nil
 }
 
-func ValidatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
+func (v *ValidatorUsecase) DetectIdentifier(identifier string) (biz.IdentifierType, error) {
+	if v.validate.Var(identifier, "email") == nil {
+		return biz.IdentifierEmail, nil
+	}
+	// 用户名不能是手机号，所以本地格式的手机号也可以识别
+	if _, err := v.phones.Parse(identifier, ""); err == nil {
+		return biz.IdentifierPhone, nil
+	}
+	return biz.IdentifierUsername, nil
+}
 
-	// 密码至少8个字符，包含至少一个大写字母、一个小写字母和一个数字
-	// if len(password) < 8 || len(password) > 64 {
-	// 	return false
-	// }
+// NormalizePhone 把用户输入的手机号转换为 E.164 格式，没有国家码时按 region 解析，
+// region 为空时使用默认地区。空字符串原样返回，由后续的校验处理
+func (v *ValidatorUsecase) NormalizePhone(number, region string) (string, error) {
+	if number == "" {
+		return "", nil
+	}
+	n, err := v.phones.Parse(number, region)
+	if errors.Is(err, phone.ErrUnsupportedRegion) {
+		return "", apperrors.ErrPhoneFormat.WithMessageKey("region", "暂不支持该国家或地区的手机号")
+	}
+	if err != nil {
+		return "", apperrors.ErrPhoneFormat
+	}
+	return n.E164(), nil
+}
 
-	//regular := `^(?=.*[a-z])(?=.*[A-Z])(?=.*\d)[a-zA-Z\d]{8,64}$`
+// validateUsername 用户名只允许字母、数字和下划线，且不能是手机号，否则登录时无法区分
+func (v *ValidatorUsecase) validateUsername(fl validator.FieldLevel) bool {
+	username := fl.Field().String()
+	return usernamePattern.MatchString(username) && !phone.IsNationalNumber(username)
+}
 
-	// Go's regexp does not support lookahead, so check conditions manually
-	validChars := regexp.MustCompile(`^[\S]+$`).MatchString(password) // 只允许字母、数字、特殊字符，且不包含空白字符
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)     // 至少一个小写字母
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)     // 至少一个大写字母
-	hasDigit := regexp.MustCompile(`\d`).MatchString(password)        // 至少一个数字
+// validatePasswordCharset 密码不能包含空白字符，必须包含的字符类型由 password_upper 等标签校验
+func validatePasswordCharset(fl validator.FieldLevel) bool {
+	return !strings.ContainsFunc(fl.Field().String(), unicode.IsSpace)
+}
 
-	if !hasLower || !hasUpper || !hasDigit || !validChars {
-		return false
+func containsRune(f func(rune) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return strings.ContainsFunc(fl.Field().String(), f)
 	}
-	return true
 }
 
-func ValidatePhone(fl validator.FieldLevel) bool {
-	phone := fl.Field().String()
+// isSymbol 字母、数字、空白以外的字符都视为特殊字符
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
 
-	// 简单的手机号格式验证（中国手机号）
-	regular := `^1[3-9]\d{9}$`
-	matched, err := regexp.MatchString(regular, phone)
-	if err != nil {
-		return false
-	}
-	return matched
+// validatePhone 保存前已经通过 NormalizePhone 转换为 E.164 格式，地区必须在允许的范围内
+func (v *ValidatorUsecase) validatePhone(fl validator.FieldLevel) bool {
+	n, err := phone.ParseE164(fl.Field().String())
+	return err == nil && v.phones.Allows(n.Region)
+}
+
+// fieldNames biz.User 字段对应的请求字段名，用于 google.rpc.BadRequest
+var fieldNames = map[string]string{
+	"UserName": "username",
+	"Password": "password",
+	"Phone":    "phone",
+	"Email":    "email",
+	"Nickname": "nickname",
 }
 
-// 错误判断转换
+// TranslateValidationError 把所有校验失败的字段转换为业务错误，返回第一个字段的错误，
+// 并通过 WithViolations 附加全部字段的错误
 func TranslateValidationError(err error) error {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		for _, e := range errs {
-			return translateFieldError(e)
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return fmt.Errorf("failed to translate validation error: %w", err)
+	}
+	violations := make([]code.FieldViolation, 0, len(errs))
+	for _, fe := range errs {
+		fieldErr, err := translateFieldError(fe)
+		if err != nil {
+			return err
 		}
+		violations = append(violations, code.FieldViolation{Field: fieldNames[fe.Field()], Err: fieldErr})
 	}
-	return fmt.Errorf("failed to translate validation error: %w", err)
+	return violations[0].Err.WithViolations(violations...)
+}
+
+// withViolation 单个字段的错误同样附加到 google.rpc.BadRequest 中
+func withViolation(field string, err code.Code) code.Code {
+	return err.WithViolations(code.FieldViolation{Field: field, Err: err})
 }
 
-func translateFieldError(fe validator.FieldError) error {
+func translateFieldError(fe validator.FieldError) (code.Code, error) {
 	switch fe.Field() {
 	case "UserName":
 		switch fe.Tag() {
 		case "required":
-			return apperrors.ErrUsernameFormat.WithMessage("用户名不能为空")
+			return apperrors.ErrUsernameFormat.WithMessageKey("required", "用户名不能为空"), nil
 		case "min":
-			return apperrors.ErrUsernameFormat.WithMessage("用户名长度不能小于3个字符")
+			return apperrors.ErrUsernameFormat.WithMessageKey("min", "用户名长度不能小于%s个字符", fe.Param()), nil
 		case "max":
-			return apperrors.ErrUsernameFormat.WithMessage("用户名长度不能大于20个字符")
+			return apperrors.ErrUsernameFormat.WithMessageKey("max", "用户名长度不能大于%s个字符", fe.Param()), nil
 		case "username":
-			return apperrors.ErrUsernameFormat.WithMessage("用户名格式错误,支持字母、数字、下划线")
+			return apperrors.ErrUsernameFormat.WithMessageKey("charset", "用户名格式错误,支持字母、数字、下划线"), nil
 		default:
-			return apperrors.ErrUsernameFormat
+			return apperrors.ErrUsernameFormat, nil
 		}
 	case "Password":
 		switch fe.Tag() {
 		case "required":
-			return apperrors.ErrPasswordFormat.WithMessage("密码不能为空")
+			return apperrors.ErrPasswordFormat.WithMessageKey("required", "密码不能为空"), nil
 		case "min":
-			return apperrors.ErrPasswordFormat.WithMessage("密码长度不能小于8个字符")
+			return apperrors.ErrPasswordFormat.WithMessageKey("min", "密码长度不能小于%s个字符", fe.Param()), nil
 		case "max":
-			return apperrors.ErrPasswordFormat.WithMessage("密码长度不能大于64个字符")
+			return apperrors.ErrPasswordFormat.WithMessageKey("max", "密码长度不能大于%s个字符", fe.Param()), nil
 		case "password":
-			return apperrors.ErrPasswordFormat.WithMessage("密码格式错误,支持字母、数字、特殊字符,且必须包含大小写字母和数字")
+			return apperrors.ErrPasswordFormat.WithMessageKey("charset", "密码不能包含空白字符"), nil
+		case "password_upper":
+			return apperrors.ErrPasswordFormat.WithMessageKey("upper", "密码必须包含大写字母"), nil
+		case "password_lower":
+			return apperrors.ErrPasswordFormat.WithMessageKey("lower", "密码必须包含小写字母"), nil
+		case "password_digit":
+			return apperrors.ErrPasswordFormat.WithMessageKey("digit", "密码必须包含数字"), nil
+		case "password_symbol":
+			return apperrors.ErrPasswordFormat.WithMessageKey("symbol", "密码必须包含特殊字符"), nil
 		default:
-			return apperrors.ErrPasswordFormat
+			return apperrors.ErrPasswordFormat, nil
 		}
 	case "Phone":
 		switch fe.Tag() {
 		case "required":
-			return apperrors.ErrPhoneFormat.WithMessage("手机号不能为空")
-		case "phone":
-			return apperrors.ErrPhoneFormat
+			return apperrors.ErrPhoneFormat.WithMessageKey("required", "手机号不能为空"), nil
 		default:
-			return apperrors.ErrPhoneFormat
+			return apperrors.ErrPhoneFormat, nil
 		}
 	case "Email":
 		switch fe.Tag() {
 		case "required":
-			return apperrors.ErrEmailFormat.WithMessage("邮箱不能为空")
-		case "email":
-			return apperrors.ErrEmailFormat.WithMessage("邮箱格式错误")
+			return apperrors.ErrEmailFormat.WithMessageKey("required", "邮箱不能为空"), nil
 		default:
-			return apperrors.ErrEmailFormat
+			return apperrors.ErrEmailFormat, nil
 		}
+	case "Nickname":
+		return apperrors.ErrInvalidArgument.WithMessageKey("nickname_too_long", "昵称不能超过%s个字符", fe.Param()), nil
 	}
-	return fmt.Errorf("failed to translate field error: %w", fe)
+	return nil, fmt.Errorf("failed to translate field error: %w", fe)
 }
@@ -4,22 +4,38 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	biz "github.com/kyson/e-shop-native/internal/user-srv/biz"
+	"github.com/kyson/e-shop-native/internal/user-srv/conf"
 	apperrors "github.com/kyson/e-shop-native/internal/user-srv/errors"
+	"github.com/kyson/e-shop-native/pkg/code"
 )
 
-// 测试用户名格式
-func TestUsername(t *testing.T) {
-	validate, err := getValidator()
-	if err != nil {
-		t.Fatalf("failed to create validator: %v", err)
+// newTestPolicy 与 config.yaml 中的默认规则一致，只允许部分地区的手机号
+func newTestPolicy() *conf.Validation {
+	return &conf.Validation{
+		Username:          &conf.UsernameRule{MinLength: 3, MaxLength: 20},
+		Password:          &conf.PasswordRule{MinLength: 8, MaxLength: 64, RequireUpper: true, RequireLower: true, RequireDigit: true},
+		Phone:             &conf.Phone{DefaultRegion: "CN", Regions: []string{"CN", "HK", "US", "GB"}},
+		NicknameMaxLength: 32,
 	}
+}
 
-	type TestUser struct {
-		UserName string `validate:"required,min=3,max=20,username"`
-	}
+func newTestValidator(t *testing.T, c *conf.Password) biz.UserValidator {
+	v, err := NewValidator(c, newTestPolicy())
+	require.NoError(t, err)
+	return v
+}
+
+// validateField 只校验字段格式，不检查密码强度
+func validateField(t *testing.T, user *biz.User, field string) error {
+	v := newTestValidator(t, &conf.Password{}).(*ValidatorUsecase)
+	return v.validate.StructPartial(user, field)
+}
 
+// 测试用户名格式
+func TestUsername(t *testing.T) {
 	tests := []struct {
 		name     string
 		username string
@@ -35,15 +51,13 @@ func TestUsername(t *testing.T) {
 		{"无效-包含空格", "test user", true},
 		{"无效-包含中文", "测试用户", true},
 		{"无效-空字符串", "", true},
+		{"无效-手机号", "15019458680", true},
+		{"无效-其他地区的手机号", "07700900123", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user := &TestUser{
-				UserName: tt.username,
-			}
-
 			// 判断验证结果是否符合预期
-			err := validate.Struct(user)
+			err := validateField(t, &biz.User{UserName: tt.username}, "UserName")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestUsername() error = %v, wantErr = %v", err, tt.wantErr)
 			}
@@ -53,15 +67,6 @@ func TestUsername(t *testing.T) {
 
 // 测试密码格式
 func TestPassword(t *testing.T) {
-	validate, err := getValidator()
-	if err != nil {
-		t.Fatalf("failed to create validator: %v", err)
-	}
-
-	type TestUser struct {
-		Password string `validate:"required,min=8,max=64,password"`
-	}
-
 	tests := []struct {
 		name     string
 		password string
@@ -83,11 +88,8 @@ func TestPassword(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user := &TestUser{
-				Password: tt.password,
-			}
 			// 判断验证结果是否符合预期
-			err := validate.Struct(user)
+			err := validateField(t, &biz.User{Password: tt.password}, "Password")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestPassword() error = %v, wantErr = %v", err, tt.wantErr)
 			}
@@ -97,36 +99,29 @@ func TestPassword(t *testing.T) {
 
 // 测试手机号格式
 func TestPhone(t *testing.T) {
-	validate, err := getValidator()
-	if err != nil {
-		t.Fatalf("failed to create validator: %v", err)
-	}
-
-	type TestUser struct {
-		Phone string `validate:"required,phone"`
-	}
-
 	tests := []struct {
 		name    string
 		phone   string
 		wantErr bool
 	}{
-		{"有效手机号-标准格式", "13800138000", false},
-		{"无效-11开头", "11800138000", true},
-		{"无效-12开头", "12800138000", true},
-		{"无效-太短", "1380013800", true},
-		{"无效-太长", "138001380000", true},
-		{"无效-包含字母", "13800abc000", true},
-		{"无效-包含特殊字符", "13800@#%000", true},
+		{"有效手机号-标准格式", "+8613800138000", false},
+		{"有效手机号-香港", "+85261234567", false},
+		{"有效手机号-美国", "+12025550123", false},
+		{"无效-没有国家码", "13800138000", true},
+		{"无效-11开头", "+8611800138000", true},
+		{"无效-12开头", "+8612800138000", true},
+		{"无效-太短", "+861380013800", true},
+		{"无效-太长", "+86138001380000", true},
+		{"无效-包含分隔符", "+86 138 0013 8000", true},
+		{"无效-包含长途前缀", "+4407700900123", true},
+		{"无效-包含字母", "+8613800abc000", true},
+		{"无效-包含特殊字符", "+8613800@#%000", true},
 		{"无效-空字符串", "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user := &TestUser{
-				Phone: tt.phone,
-			}
 			// 判断验证结果是否符合预期
-			err := validate.Struct(user)
+			err := validateField(t, &biz.User{Phone: tt.phone}, "Phone")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestPhone() error = %v, wantErr = %v", err, tt.wantErr)
 			}
@@ -136,15 +131,6 @@ func TestPhone(t *testing.T) {
 
 // 测试邮箱格式
 func TestEmail(t *testing.T) {
-	validate, err := getValidator()
-	if err != nil {
-		t.Fatalf("failed to create validator: %v", err)
-	}
-
-	type TestUser struct {
-		Email string `validate:"required,email"`
-	}
-
 	tests := []struct {
 		name    string
 		email   string
@@ -161,11 +147,8 @@ func TestEmail(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user := &TestUser{
-				Email: tt.email,
-			}
 			// 判断验证结果是否符合预期
-			err := validate.Struct(user)
+			err := validateField(t, &biz.User{Email: tt.email}, "Email")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestEmail() error = %v, wantErr = %v", err, tt.wantErr)
 			}
@@ -175,94 +158,101 @@ func TestEmail(t *testing.T) {
 
 // 测试validator错误转换成业务错误
 func TestTranslateValidationError(t *testing.T) {
-	type TestUser struct {
-		UserName string `validate:"required,min=3,max=20,username"`
-		Password string `validate:"required,min=8,max=64,password"`
-		Phone    string `validate:"required,phone"`
-		Email    string `validate:"required,email"`
-	}
-
 	tests := []struct {
 		name    string
-		user    TestUser
+		user    biz.User
 		wantErr error
 	}{
 		// Add test cases here
 		{
 			name: "用户名为空",
-			user: TestUser{UserName: "",
+			user: biz.User{UserName: "",
 				Password: "pAssword123",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
 				Email:    "test@example.com",
 			},
-			wantErr: apperrors.ErrUsernameFormat.WithMessage("用户名不能为空"),
+			wantErr: apperrors.ErrUsernameFormat.WithMessageKey("required", "用户名不能为空"),
 		},
 		{
 			name: "用户名格式错误",
-			user: TestUser{UserName: "test@123",
+			user: biz.User{UserName: "test@123",
 				Password: "pAssword123",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
 				Email:    "test@example.com"},
-			wantErr: apperrors.ErrUsernameFormat.WithMessage("用户名格式错误,支持字母、数字、下划线"),
+			wantErr: apperrors.ErrUsernameFormat.WithMessageKey("charset", "用户名格式错误,支持字母、数字、下划线"),
 		},
 		{
 			name: "密码为空",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
 				Email:    "test@example.com"},
-			wantErr: apperrors.ErrPasswordFormat.WithMessage("密码不能为空"),
+			wantErr: apperrors.ErrPasswordFormat.WithMessageKey("required", "密码不能为空"),
 		},
 		{
 			name: "密码格式错误",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "password",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
+				Email:    "test@example.com",
+			},
+			wantErr: apperrors.ErrPasswordFormat.WithMessageKey("upper", "密码必须包含大写字母"),
+		},
+		{
+			name: "密码包含空白字符",
+			user: biz.User{UserName: "testuser123",
+				Password: "pAss word123",
+				Phone:    "+8613800138000",
 				Email:    "test@example.com",
 			},
-			wantErr: apperrors.ErrPasswordFormat.WithMessage("密码格式错误,支持字母、数字、特殊字符,且必须包含大小写字母和数字"),
+			wantErr: apperrors.ErrPasswordFormat.WithMessageKey("charset", "密码不能包含空白字符"),
 		},
 		{
 			name: "手机号为空",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "pAssword123",
 				Phone:    "",
 				Email:    "test@example.com"},
-			wantErr: apperrors.ErrPhoneFormat.WithMessage("手机号不能为空"),
+			wantErr: apperrors.ErrPhoneFormat.WithMessageKey("required", "手机号不能为空"),
 		},
 		{
 			name: "手机号格式错误",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "pAssword123",
-				Phone:    "1380013800",
+				Phone:    "+861380013800",
 				Email:    "test@example.com"},
-			wantErr: apperrors.ErrPhoneFormat.WithMessage("手机号格式错误"),
+			wantErr: apperrors.ErrPhoneFormat,
+		},
+		{
+			name: "手机号地区不允许",
+			user: biz.User{UserName: "testuser123",
+				Password: "pAssword123",
+				Phone:    "+819012345678",
+				Email:    "test@example.com"},
+			wantErr: apperrors.ErrPhoneFormat,
 		},
 		{
 			name: "邮箱为空",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "pAssword123",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
 				Email:    ""},
-			wantErr: apperrors.ErrEmailFormat.WithMessage("邮箱不能为空"),
+			wantErr: apperrors.ErrEmailFormat.WithMessageKey("required", "邮箱不能为空"),
 		},
 		{
 			name: "邮箱格式错误",
-			user: TestUser{UserName: "testuser123",
+			user: biz.User{UserName: "testuser123",
 				Password: "pAssword123",
-				Phone:    "13800138000",
+				Phone:    "+8613800138000",
 				Email:    "test@example"},
-			wantErr: apperrors.ErrEmailFormat.WithMessage("邮箱格式错误"),
+			wantErr: apperrors.ErrEmailFormat,
 		},
 	}
 
+	v := newTestValidator(t, &conf.Password{}).(*ValidatorUsecase)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			validate, err := getValidator()
-			if err != nil {
-				t.Fatalf("failed to create validator: %v", err)
-			}
-			err = validate.Struct(tt.user)
+			err := v.validate.Struct(&tt.user)
 			err = TranslateValidationError(err)
 			if err.Error() != tt.wantErr.Error() {
 				t.Errorf("TranslateValidationError() error = %v, wantErr %v", err, tt.wantErr)
@@ -273,16 +263,180 @@ func TestTranslateValidationError(t *testing.T) {
 
 // GoodPath
 func TestValidator(t *testing.T) {
-	validate := NewValidator()
+	validate := newTestValidator(t, &conf.Password{MinStrength: StrengthStrong})
 	assert.NotNil(t, validate)
 
 	user := &biz.User{
 		UserName: "testuser123",
-		Password: "pAssword123",
-		Phone:    "13800138000",
+		Password: "Gx7#mQ2vLp9w",
+		Phone:    "+8613800138000",
 		Email:    "test@example.com",
 	}
 
 	err := validate.Validate(user)
 	assert.NoError(t, err)
 }
+
+// 只验证部分字段
+func TestValidatePartial(t *testing.T) {
+	validate := newTestValidator(t, &conf.Password{MinStrength: StrengthStrong})
+
+	// 用户名、密码不合法，但不在验证范围内
+	user := &biz.User{
+		UserName: "a",
+		Password: "weak",
+		Phone:    "+8613800138000",
+		Email:    "test@example.com",
+	}
+	assert.NoError(t, validate.ValidatePartial(user, "Phone", "Email"))
+
+	user.Email = "test@example"
+	assert.Equal(t, withViolation("email", apperrors.ErrEmailFormat), validate.ValidatePartial(user, "Email"))
+}
+
+// 测试登录标识类型判断
+func TestDetectIdentifier(t *testing.T) {
+	v := newTestValidator(t, &conf.Password{MinStrength: StrengthStrong})
+	tests := []struct {
+		name       string
+		identifier string
+		want       biz.IdentifierType
+	}{
+		{"用户名", "testuser", biz.IdentifierUsername},
+		{"纯数字用户名", "12345", biz.IdentifierUsername},
+		{"邮箱", "test@example.com", biz.IdentifierEmail},
+		{"手机号", "15019458680", biz.IdentifierPhone},
+		{"E.164 格式的手机号", "+8615019458680", biz.IdentifierPhone},
+		{"其他地区的手机号", "+44 7700 900123", biz.IdentifierPhone},
+		{"不合法的手机号", "12019458680", biz.IdentifierUsername},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.DetectIdentifier(tt.identifier)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// 手机号转换为 E.164 格式
+func TestNormalizePhone(t *testing.T) {
+	v := newTestValidator(t, &conf.Password{})
+	tests := []struct {
+		name    string
+		phone   string
+		region  string
+		want    string
+		wantErr error
+	}{
+		{"默认地区", "150 1945 8680", "", "+8615019458680", nil},
+		{"E.164 格式", "+8615019458680", "", "+8615019458680", nil},
+		{"00 开头的国际格式", "0085261234567", "", "+85261234567", nil},
+		{"指定地区", "(202) 555-0123", "US", "+12025550123", nil},
+		{"地区代码不区分大小写", "61234567", "hk", "+85261234567", nil},
+		{"去掉长途前缀", "07700 900123", "GB", "+447700900123", nil},
+		{"国家码后保留长途前缀", "+44 07700 900123", "", "+447700900123", nil},
+		{"空字符串", "", "", "", nil},
+		{"与地区不符", "15019458680", "US", "", apperrors.ErrPhoneFormat},
+		{"格式错误", "1380013800", "", "", apperrors.ErrPhoneFormat},
+		{"不允许的地区", "+81 90 1234 5678", "", "",
+			apperrors.ErrPhoneFormat.WithMessageKey("region", "暂不支持该国家或地区的手机号")},
+		{"不允许的地区代码", "9012345678", "JP", "",
+			apperrors.ErrPhoneFormat.WithMessageKey("region", "暂不支持该国家或地区的手机号")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.NormalizePhone(tt.phone, tt.region)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// 一次返回所有校验失败的字段
+func TestValidateAllViolations(t *testing.T) {
+	v := newTestValidator(t, &conf.Password{})
+	user := &biz.User{UserName: "ab", Password: "password1", Phone: "+8613800138000", Email: "test@example"}
+
+	usernameErr := apperrors.ErrUsernameFormat.WithMessageKey("min", "用户名长度不能小于%s个字符", "3")
+	want := usernameErr.WithViolations(
+		code.FieldViolation{Field: "username", Err: usernameErr},
+		code.FieldViolation{Field: "password", Err: apperrors.ErrPasswordFormat.WithMessageKey("upper", "密码必须包含大写字母")},
+		code.FieldViolation{Field: "email", Err: apperrors.ErrEmailFormat},
+	)
+	assert.Equal(t, want, v.Validate(user))
+
+	// 密码强度不足同样作为字段错误返回
+	user = &biz.User{UserName: "testuser123", Password: "Password1", Phone: "+8613800138000", Email: "test@example.com"}
+	breached := apperrors.ErrPasswordFormat.WithMessageKey("breached", "该密码是已泄露的常用密码，请更换")
+	assert.Equal(t, withViolation("password", breached), v.Validate(user))
+}
+
+// 按配置的规则校验
+func TestValidationPolicy(t *testing.T) {
+	policy := newTestPolicy()
+	policy.Username = &conf.UsernameRule{MinLength: 5, MaxLength: 8}
+	policy.Password = &conf.PasswordRule{MinLength: 10, MaxLength: 20, RequireSymbol: true}
+	policy.Phone = &conf.Phone{DefaultRegion: "US", Regions: []string{"US"}}
+	policy.NicknameMaxLength = 4
+	v, err := NewValidator(&conf.Password{}, policy)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		user    *biz.User
+		field   string
+		wantErr error
+	}{
+		{"用户名太短", &biz.User{UserName: "abcd"}, "UserName",
+			apperrors.ErrUsernameFormat.WithMessageKey("min", "用户名长度不能小于%s个字符", "5")},
+		{"用户名太长", &biz.User{UserName: "abcdefghi"}, "UserName",
+			apperrors.ErrUsernameFormat.WithMessageKey("max", "用户名长度不能大于%s个字符", "8")},
+		{"用户名合法", &biz.User{UserName: "abcde"}, "UserName", nil},
+		{"密码太短", &biz.User{Password: "x#9kq!"}, "Password",
+			apperrors.ErrPasswordFormat.WithMessageKey("min", "密码长度不能小于%s个字符", "10")},
+		{"密码缺少特殊字符", &biz.User{Password: "xk9qmvtrwz"}, "Password",
+			apperrors.ErrPasswordFormat.WithMessageKey("symbol", "密码必须包含特殊字符")},
+		{"密码不要求大小写和数字", &biz.User{Password: "xk#qmvtrwz"}, "Password", nil},
+		{"手机号地区不允许", &biz.User{Phone: "+8613800138000"}, "Phone", apperrors.ErrPhoneFormat},
+		{"手机号合法", &biz.User{Phone: "+12025550123"}, "Phone", nil},
+		{"昵称太长", &biz.User{Nickname: "五个字昵称"}, "Nickname",
+			apperrors.ErrInvalidArgument.WithMessageKey("nickname_too_long", "昵称不能超过%s个字符", "4")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidatePartial(tt.user, tt.field)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var got code.Code
+			require.ErrorAs(t, err, &got)
+			require.Len(t, got.Violations(), 1)
+			assert.Equal(t, tt.wantErr, got.Violations()[0].Err)
+		})
+	}
+}
+
+// 规则不合法时无法创建
+func TestNewValidatorInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *conf.Validation)
+	}{
+		{"缺少手机号规则", func(p *conf.Validation) { p.Phone = nil }},
+		{"用户名最小长度为 0", func(p *conf.Validation) { p.Username.MinLength = 0 }},
+		{"密码最小长度大于最大长度", func(p *conf.Validation) { p.Password.MinLength = 80 }},
+		{"昵称长度为 0", func(p *conf.Validation) { p.NicknameMaxLength = 0 }},
+		{"未知的手机号地区", func(p *conf.Validation) { p.Phone.Regions = []string{"XX"} }},
+		{"默认地区不在允许范围内", func(p *conf.Validation) { p.Phone.DefaultRegion = "JP" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newTestPolicy()
+			tt.modify(policy)
+			_, err := NewValidator(&conf.Password{}, policy)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package clientip
+
+import (
+	"context"
+)
+
+type clientIPKey struct{}
+
+func ToContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(string)
+	return ip, ok
+}
@@ -0,0 +1,69 @@
+package code
+
+import (
+	"sort"
+	"sync"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+)
+
+// DefaultLocale 错误码定义中使用的语言，请求没有指定语言或没有匹配的消息目录时使用
+const DefaultLocale = "zh-CN"
+
+// Catalog 某个语言的消息目录，key 为业务错误码，或者 "错误码.key" 对应 WithMessageKey 的信息。
+// 带参数的信息使用与默认语言相同顺序的 fmt 格式化动词
+type Catalog map[string]string
+
+var (
+	_catalogs   = make(map[string]Catalog)
+	_catalogMux sync.RWMutex
+)
+
+// RegisterCatalog 注册 locale 语言的消息目录，多次注册同一语言时合并
+func RegisterCatalog(locale string, catalog Catalog) {
+	_catalogMux.Lock()
+	defer _catalogMux.Unlock()
+
+	merged, ok := _catalogs[locale]
+	if !ok {
+		merged = make(Catalog, len(catalog))
+		_catalogs[locale] = merged
+	}
+	for key, message := range catalog {
+		merged[key] = message
+	}
+}
+
+// Locales 返回支持的语言，默认语言排在第一个
+func Locales() []string {
+	_catalogMux.RLock()
+	defer _catalogMux.RUnlock()
+
+	locales := make([]string, 0, len(_catalogs)+1)
+	for locale := range _catalogs {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{DefaultLocale}, locales...)
+}
+
+func lookupMessage(locale, key string) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	_catalogMux.RLock()
+	defer _catalogMux.RUnlock()
+
+	message, ok := _catalogs[locale][key]
+	return message, ok
+}
+
+func init() {
+	RegisterCatalog("en", Catalog{
+		v1.ErrorCode_INTERNAL.String():         "Internal error",
+		v1.ErrorCode_UNKNOWN.String():          "Unknown error",
+		v1.ErrorCode_INVALID_ARGUMENT.String(): "Invalid argument",
+	})
+}
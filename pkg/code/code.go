@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"sync"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 
 	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
 )
@@ -17,12 +19,26 @@ type Code interface {
 	Message() string
 	//GrpcCode() codes.Code
 	GrpcError() error
+	// WithMessage 使用固定的错误信息，不做本地化
 	WithMessage(message string) Code
+	// WithMessageKey 使用消息目录中 "错误码.key" 对应的错误信息，format 和 args 为默认语言的信息
+	WithMessageKey(key string, format string, args ...any) Code
 	WithError(err error) Code
+	// WithViolations 附加参数校验失败的字段，返回时转换为 google.rpc.BadRequest
+	WithViolations(violations ...FieldViolation) Code
+	Violations() []FieldViolation
+	// Localize 返回 locale 语言的错误，消息目录中没有对应的信息时保持不变
+	Localize(locale string) Code
 	// 为了实现errors 的As和Is
 	Unwrap() error
 }
 
+// FieldViolation 校验失败的字段，Field 为请求中的字段名，Err 为该字段的错误
+type FieldViolation struct {
+	Field string
+	Err   Code
+}
+
 type ecode struct {
 	// 错误码，业务唯一
 	code string
@@ -32,6 +48,13 @@ type ecode struct {
 	grpccode codes.Code
 	// 内部错误
 	err error
+	// 消息目录中的 key 和格式化参数，为空时使用错误码对应的信息
+	key  string
+	args []any
+	// 使用 WithMessage 指定的信息，不做本地化
+	fixed bool
+	// 参数校验失败的所有字段
+	violations []FieldViolation
 }
 
 var (
@@ -74,7 +97,19 @@ func (e *ecode) GrpcError() error {
 		Message: e.Message(),
 	}
 	// 附加 detail
-	stWithDetails, detailErr := st.WithDetails(detail)
+	details := []protoadapt.MessageV1{detail}
+	if len(e.violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Err.Message(),
+				Reason:      v.Err.Code(),
+			})
+		}
+		details = append(details, badRequest)
+	}
+	stWithDetails, detailErr := st.WithDetails(details...)
 	if detailErr != nil {
 		// 如果附加 detail 失败（虽然很少见），则返回不带 detail 的原始 status 错误
 		// ⚠️ 这里需要完善异常的处理机制
@@ -85,25 +120,105 @@ func (e *ecode) GrpcError() error {
 
 func (e *ecode) WithMessage(message string) Code {
 	return &ecode{
-		code:     e.code,
-		message:  message,
-		grpccode: e.grpccode,
-		err:      e.err,
+		code:       e.code,
+		message:    message,
+		grpccode:   e.grpccode,
+		err:        e.err,
+		fixed:      true,
+		violations: e.violations,
+	}
+}
+
+func (e *ecode) WithMessageKey(key string, format string, args ...any) Code {
+	return &ecode{
+		code:       e.code,
+		message:    formatMessage(format, args),
+		grpccode:   e.grpccode,
+		err:        e.err,
+		key:        key,
+		args:       args,
+		violations: e.violations,
 	}
 }
+
 func (e *ecode) WithError(err error) Code {
 	return &ecode{
-		code:     e.code,
-		message:  e.message,
-		grpccode: e.grpccode,
-		err:      err,
+		code:       e.code,
+		message:    e.message,
+		grpccode:   e.grpccode,
+		err:        err,
+		key:        e.key,
+		args:       e.args,
+		fixed:      e.fixed,
+		violations: e.violations,
+	}
+}
+
+func (e *ecode) WithViolations(violations ...FieldViolation) Code {
+	return &ecode{
+		code:       e.code,
+		message:    e.message,
+		grpccode:   e.grpccode,
+		err:        e.err,
+		key:        e.key,
+		args:       e.args,
+		fixed:      e.fixed,
+		violations: violations,
+	}
+}
+
+func (e *ecode) Violations() []FieldViolation {
+	return e.violations
+}
+
+func (e *ecode) Localize(locale string) Code {
+	violations := e.violations
+	if len(violations) > 0 {
+		violations = make([]FieldViolation, len(e.violations))
+		for i, v := range e.violations {
+			violations[i] = FieldViolation{Field: v.Field, Err: v.Err.Localize(locale)}
+		}
+	}
+	message := e.message
+	if format, ok := lookupMessage(locale, e.catalogKey()); ok && !e.fixed {
+		message = formatMessage(format, e.args)
+	}
+	return &ecode{
+		code:       e.code,
+		message:    message,
+		grpccode:   e.grpccode,
+		err:        e.err,
+		key:        e.key,
+		args:       e.args,
+		fixed:      e.fixed,
+		violations: violations,
+	}
+}
+
+func (e *ecode) catalogKey() string {
+	if e.key == "" {
+		return e.code
+	}
+	return e.code + "." + e.key
+}
+
+func formatMessage(format string, args []any) string {
+	if len(args) == 0 {
+		return format
 	}
+	return fmt.Sprintf(format, args...)
 }
 
 func (e *ecode) Unwrap() error {
 	return e.err
 }
 
+// Is 错误码相同即认为是同一个错误，WithMessage、WithError 生成的错误也能被 errors.Is 识别
+func (e *ecode) Is(target error) bool {
+	t, ok := target.(*ecode)
+	return ok && t.code == e.code
+}
+
 // 将一个error 转换为ecode
 func FromError(err error) Code {
 	// code
@@ -130,4 +245,6 @@ func FromError(err error) Code {
 var (
 	ErrInternal = New(v1.ErrorCode_INTERNAL.String(), "内部错误", codes.Internal)
 	ErrUnknown  = New(v1.ErrorCode_UNKNOWN.String(), "未知错误", codes.Unknown)
+
+	ErrInvalidArgument = New(v1.ErrorCode_INVALID_ARGUMENT.String(), "参数错误", codes.InvalidArgument)
 )
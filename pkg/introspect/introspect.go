@@ -0,0 +1,224 @@
+// Package introspect 其他服务通过用户服务的 AuthService.Introspect 校验访问令牌，
+// 不需要共享签名密钥，令牌吊销、用户禁用等状态由用户服务判断。
+// 结果在本地缓存一小段时间，吊销后的令牌最多在缓存时间内仍被接受
+package introspect
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+
+	defaultCacheTTL   = 10 * time.Second
+	defaultMaxEntries = 10000
+)
+
+// ErrInactive 令牌无效、已过期、已被吊销或者用户不可用
+var ErrInactive = errors.New("token is not active")
+
+// Claims 有效令牌中的用户信息
+type Claims struct {
+	UserID    uint
+	Username  string
+	Roles     []string
+	SessionID string
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// HasRole 用户是否拥有 roles 中的任意一个角色
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(c.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+type cacheEntry struct {
+	claims    *Claims // 为 nil 时表示令牌不可用
+	expiresAt time.Time
+}
+
+// Client 调用用户服务内省令牌，并缓存结果
+type Client struct {
+	client     v1.AuthServiceClient
+	serviceKey string
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type Option func(*Client)
+
+// WithCacheTTL 结果的本地缓存时间，0 表示不缓存，默认 10 秒
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.ttl = ttl
+	}
+}
+
+// WithMaxEntries 最多缓存的令牌数，默认 10000
+func WithMaxEntries(n int) Option {
+	return func(c *Client) {
+		c.maxEntries = n
+	}
+}
+
+// NewClient conn 为用户服务的 gRPC 连接，serviceKey 为用户服务 auth.services 中配置的本服务密钥
+func NewClient(conn grpc.ClientConnInterface, serviceKey string, opts ...Option) *Client {
+	c := &Client{
+		client:     v1.NewAuthServiceClient(conn),
+		serviceKey: serviceKey,
+		ttl:        defaultCacheTTL,
+		maxEntries: defaultMaxEntries,
+		now:        time.Now,
+		cache:      make(map[string]cacheEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Introspect 令牌有效时返回 claims，不可用时返回 ErrInactive，调用用户服务失败时返回对应的错误
+func (c *Client) Introspect(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrInactive
+	}
+	if entry, ok := c.lookup(token); ok {
+		if entry.claims == nil {
+			return nil, ErrInactive
+		}
+		return entry.claims, nil
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+c.serviceKey)
+	reply, err := c.client.Introspect(ctx, &v1.IntrospectRequest{Token: token, TokenTypeHint: "access_token"})
+	if err != nil {
+		return nil, err
+	}
+	if !reply.Active {
+		c.store(token, nil)
+		return nil, ErrInactive
+	}
+	claims := &Claims{
+		UserID:    uint(reply.UserId),
+		Username:  reply.Username,
+		Roles:     reply.Roles,
+		SessionID: reply.SessionId,
+		TokenID:   reply.Jti,
+		ExpiresAt: time.Unix(reply.Exp, 0),
+	}
+	c.store(token, claims)
+	return claims, nil
+}
+
+func (c *Client) lookup(token string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cache[token]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.cache, token)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store 有效令牌的缓存时间不超过令牌本身的过期时间
+func (c *Client) store(token string, claims *Claims) {
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	expiresAt := now.Add(c.ttl)
+	if claims != nil && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.cache[token] = cacheEntry{claims: claims, expiresAt: expiresAt}
+}
+
+// evict 先删除过期的结果，仍然超过上限时清空缓存
+func (c *Client) evict(now time.Time) {
+	for token, entry := range c.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(c.cache, token)
+		}
+	}
+	if len(c.cache) >= c.maxEntries {
+		clear(c.cache)
+	}
+}
+
+type claimsKey struct{}
+
+// ToContext 和 FromContext 保存通过内省校验的 claims
+func ToContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// UnaryServerInterceptor 读取 authorization 中的 Bearer 令牌并内省，有效时把 Claims 保存到 context，
+// publicMethods 中的方法（gRPC 完整方法名）不需要登录
+func UnaryServerInterceptor(c *Client, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		authHeaders := md.Get(authorizationHeader)
+		if len(authHeaders) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "请先登录")
+		}
+		token, ok := strings.CutPrefix(authHeaders[0], bearerPrefix)
+		if !ok || token == "" {
+			return nil, status.Error(codes.Unauthenticated, "Token 格式错误")
+		}
+
+		claims, err := c.Introspect(ctx, token)
+		if errors.Is(err, ErrInactive) {
+			return nil, status.Error(codes.Unauthenticated, "Token 无效或已过期")
+		}
+		if err != nil {
+			// 用户服务不可用时拒绝请求，不放行未校验的令牌
+			return nil, status.Error(codes.Unavailable, "暂时无法校验 Token，请稍后重试")
+		}
+		return handler(ToContext(ctx, claims), req)
+	}
+}
@@ -0,0 +1,148 @@
+package introspect
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	v1 "github.com/kyson/e-shop-native/api/protobuf/user/v1"
+)
+
+// fakeAuthServer 只有 tokens 中的令牌有效，并检查调用方的服务密钥
+type fakeAuthServer struct {
+	v1.UnimplementedAuthServiceServer
+	tokens map[string]*v1.IntrospectReply
+	calls  atomic.Int32
+}
+
+func (s *fakeAuthServer) Introspect(ctx context.Context, req *v1.IntrospectRequest) (*v1.IntrospectReply, error) {
+	s.calls.Add(1)
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth := md.Get("authorization"); len(auth) == 0 || auth[0] != "Bearer service-key" {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	if reply, ok := s.tokens[req.Token]; ok {
+		return reply, nil
+	}
+	return &v1.IntrospectReply{Active: false}, nil
+}
+
+func newTestClient(t *testing.T, serviceKey string, opts ...Option) (*Client, *fakeAuthServer) {
+	fake := &fakeAuthServer{tokens: map[string]*v1.IntrospectReply{
+		"valid-token": {
+			Active:   true,
+			UserId:   42,
+			Username: "alice",
+			Roles:    []string{"customer"},
+			Exp:      time.Now().Add(time.Hour).Unix(),
+		},
+	}}
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	v1.RegisterAuthServiceServer(server, fake)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn, serviceKey, opts...), fake
+}
+
+func TestClient_Introspect(t *testing.T) {
+	c, fake := newTestClient(t, "service-key")
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	claims, err := c.Introspect(ctx, "valid-token")
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.UserID)
+	assert.Equal(t, "alice", claims.Username)
+	assert.True(t, claims.HasRole("admin", "customer"))
+
+	_, err = c.Introspect(ctx, "invalid-token")
+	assert.ErrorIs(t, err, ErrInactive)
+
+	// 缓存时间内不再调用用户服务，无效的令牌同样缓存
+	_, err = c.Introspect(ctx, "valid-token")
+	require.NoError(t, err)
+	_, err = c.Introspect(ctx, "invalid-token")
+	assert.ErrorIs(t, err, ErrInactive)
+	assert.Equal(t, int32(2), fake.calls.Load())
+
+	// 缓存过期后重新内省，令牌已被吊销
+	delete(fake.tokens, "valid-token")
+	now = now.Add(defaultCacheTTL)
+	_, err = c.Introspect(ctx, "valid-token")
+	assert.ErrorIs(t, err, ErrInactive)
+	assert.Equal(t, int32(3), fake.calls.Load())
+}
+
+func TestClient_IntrospectError(t *testing.T) {
+	// 服务密钥错误时返回用户服务的错误，不缓存
+	c, fake := newTestClient(t, "wrong-key")
+	for i := 0; i < 2; i++ {
+		_, err := c.Introspect(context.Background(), "valid-token")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+	assert.Equal(t, int32(2), fake.calls.Load())
+}
+
+func TestClient_CacheLimit(t *testing.T) {
+	c, _ := newTestClient(t, "service-key", WithMaxEntries(2))
+	for _, token := range []string{"a", "b", "c"} {
+		_, err := c.Introspect(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInactive)
+	}
+	assert.LessOrEqual(t, len(c.cache), 2)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c, _ := newTestClient(t, "service-key")
+	interceptor := UnaryServerInterceptor(c, "/test.Service/Public")
+
+	withToken := func(header string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", header))
+	}
+	tests := []struct {
+		name     string
+		method   string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{"公开方法", "/test.Service/Public", context.Background(), codes.OK},
+		{"没有令牌", "/test.Service/Protected", context.Background(), codes.Unauthenticated},
+		{"格式错误", "/test.Service/Protected", withToken("valid-token"), codes.Unauthenticated},
+		{"无效的令牌", "/test.Service/Protected", withToken("Bearer invalid-token"), codes.Unauthenticated},
+		{"有效的令牌", "/test.Service/Protected", withToken("Bearer valid-token"), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims *Claims
+			handler := func(ctx context.Context, req any) (any, error) {
+				claims, _ = FromContext(ctx)
+				return "ok", nil
+			}
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK && tt.method != "/test.Service/Public" {
+				require.NotNil(t, claims)
+				assert.Equal(t, uint(42), claims.UserID)
+			}
+		})
+	}
+}
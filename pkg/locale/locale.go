@@ -0,0 +1,78 @@
+package locale
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type localeKey struct{}
+
+func ToContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext 返回请求的语言，没有时返回空字符串，表示使用默认语言
+func FromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// Match 按 Accept-Language 中的优先级从 supported 中选择语言，
+// 先精确匹配（en-US 匹配 en-US），再按主语言匹配（en-US 匹配 en），都不匹配时返回空字符串
+func Match(acceptLanguage string, supported ...string) string {
+	for _, tag := range parse(acceptLanguage) {
+		for _, s := range supported {
+			if strings.EqualFold(tag, s) {
+				return s
+			}
+		}
+		for _, s := range supported {
+			if strings.EqualFold(base(tag), base(s)) {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// parse 解析 Accept-Language，按 q 值从高到低返回语言标签，忽略 * 和 q=0 的语言
+func parse(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: strings.ReplaceAll(tag, "_", "-"), q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func base(tag string) string {
+	b, _, _ := strings.Cut(tag, "-")
+	return b
+}
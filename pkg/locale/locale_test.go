@@ -0,0 +1,32 @@
+package locale_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyson/e-shop-native/pkg/locale"
+)
+
+func TestMatch(t *testing.T) {
+	supported := []string{"zh-CN", "en"}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"en-US,en;q=0.9,zh-CN;q=0.8", "en"},
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh-CN"},
+		{"zh-TW", "zh-CN"},
+		{"fr-FR, en;q=0.5", "en"},
+		{"en;q=0.3, zh-CN;q=0.7", "zh-CN"},
+		{"en;q=0, fr", ""},
+		{"EN_us", "en"},
+		{"*", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, locale.Match(tt.header, supported...))
+		})
+	}
+}
@@ -27,14 +27,34 @@ const (
 // --- 用户服务事件 ---
 const (
 	// Info Level
-	EventUserCreated Event = "user_create_success"
-	EventUserLogin   Event = "user_login_success"
+	EventUserCreated           Event = "user_create_success"
+	EventUserLogin             Event = "user_login_success"
+	EventUserLogout            Event = "user_logout_success"
+	EventUserPasswordChanged   Event = "user_password_change_success"
+	EventUserPasswordReset     Event = "user_password_reset_success"
+	EventUserTokensRevoked     Event = "user_token_revoke_success"
+	EventUserSessionRevoked    Event = "user_session_revoke_success"
+	EventUserTwoFactorEnabled  Event = "user_2fa_enable_success"
+	EventUserTwoFactorDisabled Event = "user_2fa_disable_success"
+	EventUserRecoveryCodeUsed  Event = "user_2fa_recovery_code_success"
 
 	// Warn Level
 	EventUserLoginPasswordIncorrect Event = "user_login_fail_password_incorrect"
 	EventUserLoginNotFound          Event = "user_login_fail_user_not_found"
+	EventUserLoginLocked            Event = "user_login_fail_locked"
+	EventUserLoginDisabled          Event = "user_login_fail_disabled"
+	EventUserRefreshTokenReused     Event = "user_refresh_token_fail_reused"
+	EventUserTwoFactorCodeInvalid   Event = "user_2fa_verify_fail_code_invalid"
 
 	// Error Level
 	EventDBUserQueryFailed  Event = "db_user_query_failed"
 	EventDBUserCreateFailed Event = "db_user_create_failed"
 )
+
+// --- 管理后台事件 ---
+const (
+	EventAdminUserDisabled Event = "admin_user_disable_success"
+	EventAdminUserEnabled  Event = "admin_user_enable_success"
+	EventAdminUserDeleted  Event = "admin_user_delete_success"
+	EventAdminUserRestored Event = "admin_user_restore_success"
+)
@@ -0,0 +1,255 @@
+// Package phone 手机号的解析、校验和格式化，统一保存为 E.164 格式（如 +8615012345678）。
+// 只包含常用国家和地区的手机号规则，用于短信验证，不支持固定电话
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidNumber     = errors.New("invalid phone number")
+	ErrUnsupportedRegion = errors.New("unsupported phone region")
+)
+
+// Region 国家或地区的手机号规则
+type Region struct {
+	Code        string         // ISO 3166-1 二位代码
+	CallingCode string         // 国家码，不含 +
+	TrunkPrefix string         // 本地格式中的长途前缀，如英国的 0，转换为 E.164 时去掉
+	Mobile      *regexp.Regexp // 去掉国家码和长途前缀后的号码
+	Groups      []int          // 展示时的分组长度，剩余的数字作为最后一组
+}
+
+// regions 按 Code 索引的手机号规则，共用国家码的地区只收录一个，避免无法区分
+var regions = map[string]*Region{
+	"CN": {Code: "CN", CallingCode: "86", Mobile: regexp.MustCompile(`^1[3-9]\d{9}$`), Groups: []int{3, 4}},
+	"HK": {Code: "HK", CallingCode: "852", Mobile: regexp.MustCompile(`^[4-79]\d{7}$`), Groups: []int{4}},
+	"MO": {Code: "MO", CallingCode: "853", Mobile: regexp.MustCompile(`^6\d{7}$`), Groups: []int{4}},
+	"TW": {Code: "TW", CallingCode: "886", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^9\d{8}$`), Groups: []int{3, 3}},
+	"US": {Code: "US", CallingCode: "1", Mobile: regexp.MustCompile(`^[2-9]\d{2}[2-9]\d{6}$`), Groups: []int{3, 3}},
+	"GB": {Code: "GB", CallingCode: "44", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^7\d{9}$`), Groups: []int{4}},
+	"JP": {Code: "JP", CallingCode: "81", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^[789]0\d{8}$`), Groups: []int{2, 4}},
+	"KR": {Code: "KR", CallingCode: "82", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^1[016-9]\d{7,8}$`), Groups: []int{2, 4}},
+	"SG": {Code: "SG", CallingCode: "65", Mobile: regexp.MustCompile(`^[89]\d{7}$`), Groups: []int{4}},
+	"AU": {Code: "AU", CallingCode: "61", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^4\d{8}$`), Groups: []int{3, 3}},
+	"DE": {Code: "DE", CallingCode: "49", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^1[5-7]\d{8,9}$`), Groups: []int{3}},
+	"FR": {Code: "FR", CallingCode: "33", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^[67]\d{8}$`), Groups: []int{1, 2, 2, 2}},
+}
+
+// Regions 返回支持的所有地区代码
+func Regions() []string {
+	codes := make([]string, 0, len(regions))
+	for code := range regions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Number 解析后的手机号
+type Number struct {
+	Region         string
+	CallingCode    string
+	NationalNumber string // 不含国家码和长途前缀
+}
+
+// E164 返回 +国家码+号码 格式，用于保存和查询
+func (n Number) E164() string {
+	return "+" + n.CallingCode + n.NationalNumber
+}
+
+// International 返回分组后的国际格式，用于展示，如 +86 150 1234 5678
+func (n Number) International() string {
+	var b strings.Builder
+	b.WriteString("+" + n.CallingCode)
+	rest := n.NationalNumber
+	if r, ok := regions[n.Region]; ok {
+		for _, size := range r.Groups {
+			if len(rest) <= size {
+				break
+			}
+			b.WriteString(" " + rest[:size])
+			rest = rest[size:]
+		}
+	}
+	b.WriteString(" " + rest)
+	return b.String()
+}
+
+// Parser 只接受 allowed 中地区的手机号，本地格式的号码按 defaultRegion 解析
+type Parser struct {
+	defaultRegion string
+	allowed       []*Region
+}
+
+// NewParser allowed 为空时允许所有支持的地区
+func NewParser(defaultRegion string, allowed ...string) (*Parser, error) {
+	if len(allowed) == 0 {
+		allowed = Regions()
+	}
+	p := &Parser{defaultRegion: strings.ToUpper(defaultRegion)}
+	for _, code := range allowed {
+		r, ok := regions[strings.ToUpper(code)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRegion, code)
+		}
+		p.allowed = append(p.allowed, r)
+	}
+	if p.defaultRegion != "" && p.region(p.defaultRegion) == nil {
+		return nil, fmt.Errorf("default region %s is not allowed: %w", p.defaultRegion, ErrUnsupportedRegion)
+	}
+	return p, nil
+}
+
+func (p *Parser) region(code string) *Region {
+	for _, r := range p.allowed {
+		if r.Code == code {
+			return r
+		}
+	}
+	return nil
+}
+
+// Allows 是否允许 region 地区的手机号
+func (p *Parser) Allows(region string) bool {
+	return p.region(strings.ToUpper(region)) != nil
+}
+
+// Parse 解析 E.164 格式（+ 或 00 开头）或者 region 地区本地格式的号码，region 为空时使用默认地区。
+// 号码中的空格、连字符、点和括号会被忽略
+func (p *Parser) Parse(raw, region string) (Number, error) {
+	digits, international := clean(raw)
+	if digits == "" {
+		return Number{}, ErrInvalidNumber
+	}
+	if international {
+		return p.parseInternational(digits)
+	}
+
+	if region == "" {
+		region = p.defaultRegion
+	}
+	r := p.region(strings.ToUpper(region))
+	if r == nil {
+		return Number{}, ErrUnsupportedRegion
+	}
+	national := strings.TrimPrefix(digits, r.TrunkPrefix)
+	if !r.Mobile.MatchString(national) {
+		return Number{}, ErrInvalidNumber
+	}
+	return Number{Region: r.Code, CallingCode: r.CallingCode, NationalNumber: national}, nil
+}
+
+func (p *Parser) parseInternational(digits string) (Number, error) {
+	r, national, err := matchInternational(digits, p.allowed)
+	if err == nil {
+		return Number{Region: r.Code, CallingCode: r.CallingCode, NationalNumber: national}, nil
+	}
+	// 号码本身合法，只是地区不在允许的范围内
+	if _, _, e := matchInternational(digits, allRegions()); e == nil {
+		return Number{}, ErrUnsupportedRegion
+	}
+	return Number{}, err
+}
+
+func matchInternational(digits string, candidates []*Region) (*Region, string, error) {
+	for _, r := range candidates {
+		national, ok := strings.CutPrefix(digits, r.CallingCode)
+		if !ok {
+			continue
+		}
+		// 有些用户会在国家码后面保留长途前缀，如 +44 07700 900123
+		national = strings.TrimPrefix(national, r.TrunkPrefix)
+		if r.Mobile.MatchString(national) {
+			return r, national, nil
+		}
+	}
+	return nil, "", ErrInvalidNumber
+}
+
+func allRegions() []*Region {
+	all := make([]*Region, 0, len(regions))
+	for _, code := range Regions() {
+		all = append(all, regions[code])
+	}
+	return all
+}
+
+// clean 去掉分隔符，返回数字以及是否为国际格式
+func clean(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(raw, "+"):
+		raw, international = raw[1:], true
+	case strings.HasPrefix(raw, "00"):
+		raw, international = raw[2:], true
+	}
+	var b strings.Builder
+	for _, c := range raw {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), international
+}
+
+// ParseE164 解析已保存的 E.164 号码，不限制地区
+func ParseE164(e164 string) (Number, error) {
+	digits, ok := strings.CutPrefix(e164, "+")
+	if !ok {
+		return Number{}, ErrInvalidNumber
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Number{}, ErrInvalidNumber
+		}
+	}
+	r, national, err := matchInternational(digits, allRegions())
+	if err != nil || r.CallingCode+national != digits {
+		return Number{}, ErrInvalidNumber
+	}
+	return Number{Region: r.Code, CallingCode: r.CallingCode, NationalNumber: national}, nil
+}
+
+// IsE164 是否为支持的地区的 E.164 号码
+func IsE164(s string) bool {
+	_, err := ParseE164(s)
+	return err == nil
+}
+
+// IsNationalNumber 是否为任意支持地区的本地格式手机号
+func IsNationalNumber(s string) bool {
+	for _, r := range regions {
+		if r.Mobile.MatchString(strings.TrimPrefix(s, r.TrunkPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format 把 E.164 号码格式化为分组的国际格式，无法解析时原样返回
+func Format(e164 string) string {
+	n, err := ParseE164(e164)
+	if err != nil {
+		return e164
+	}
+	return n.International()
+}
+
+// RegionOf 返回 E.164 号码所属的地区代码，无法解析时返回空字符串
+func RegionOf(e164 string) string {
+	n, err := ParseE164(e164)
+	if err != nil {
+		return ""
+	}
+	return n.Region
+}
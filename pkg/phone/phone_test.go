@@ -0,0 +1,95 @@
+package phone_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyson/e-shop-native/pkg/phone"
+)
+
+func TestParse(t *testing.T) {
+	p, err := phone.NewParser("CN", "CN", "HK", "US", "GB", "FR")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		raw     string
+		region  string
+		want    string
+		wantErr error
+	}{
+		{"默认地区", "15019458680", "", "+8615019458680", nil},
+		{"带分隔符", "150-1945-8680", "", "+8615019458680", nil},
+		{"E.164 格式", "+8615019458680", "", "+8615019458680", nil},
+		{"00 开头", "00852 6123 4567", "", "+85261234567", nil},
+		{"指定地区", "(202) 555-0123", "US", "+12025550123", nil},
+		{"去掉长途前缀", "07700 900123", "GB", "+447700900123", nil},
+		{"国家码后保留长途前缀", "+33 06 12 34 56 78", "", "+33612345678", nil},
+		{"与默认地区不符", "2025550123", "", "", phone.ErrInvalidNumber},
+		{"号码太短", "1501945868", "", "", phone.ErrInvalidNumber},
+		{"包含字母", "1501945868a", "", "", phone.ErrInvalidNumber},
+		{"未知的国家码", "+999123456789", "", "", phone.ErrInvalidNumber},
+		{"不允许的国家码", "+81 90 1234 5678", "", "", phone.ErrUnsupportedRegion},
+		{"不允许的地区", "9012345678", "JP", "", phone.ErrUnsupportedRegion},
+		{"空字符串", "", "", "", phone.ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := p.Parse(tt.raw, tt.region)
+			assert.Equal(t, tt.wantErr, err)
+			if err == nil {
+				assert.Equal(t, tt.want, n.E164())
+			}
+		})
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	_, err := phone.NewParser("CN", "CN", "XX")
+	assert.ErrorIs(t, err, phone.ErrUnsupportedRegion)
+
+	_, err = phone.NewParser("US", "CN")
+	assert.ErrorIs(t, err, phone.ErrUnsupportedRegion)
+
+	// 没有配置地区时允许所有地区
+	p, err := phone.NewParser("cn")
+	require.NoError(t, err)
+	n, err := p.Parse("+81 90-1234-5678", "")
+	require.NoError(t, err)
+	assert.Equal(t, "JP", n.Region)
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		e164   string
+		want   string
+		region string
+	}{
+		{"+8615019458680", "+86 150 1945 8680", "CN"},
+		{"+85261234567", "+852 6123 4567", "HK"},
+		{"+12025550123", "+1 202 555 0123", "US"},
+		{"+447700900123", "+44 7700 900123", "GB"},
+		{"+33612345678", "+33 6 12 34 56 78", "FR"},
+		{"+819012345678", "+81 90 1234 5678", "JP"},
+		// 无法解析的号码原样返回
+		{"15019458680", "15019458680", ""},
+		{"+4407700900123", "+4407700900123", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.e164, func(t *testing.T) {
+			assert.Equal(t, tt.want, phone.Format(tt.e164))
+			assert.Equal(t, tt.region, phone.RegionOf(tt.e164))
+			assert.Equal(t, tt.region != "", phone.IsE164(tt.e164))
+		})
+	}
+}
+
+func TestIsNationalNumber(t *testing.T) {
+	assert.True(t, phone.IsNationalNumber("15019458680"))
+	assert.True(t, phone.IsNationalNumber("07700900123"))
+	assert.True(t, phone.IsNationalNumber("2025550123"))
+	assert.False(t, phone.IsNationalNumber("12345"))
+	assert.False(t, phone.IsNationalNumber("testuser"))
+}
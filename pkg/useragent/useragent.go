@@ -0,0 +1,76 @@
+package useragent
+
+import (
+	"context"
+	"strings"
+)
+
+type userAgentKey struct{}
+
+func ToContext(ctx context.Context, ua string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, ua)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	ua, ok := ctx.Value(userAgentKey{}).(string)
+	return ua, ok
+}
+
+// 按顺序匹配，Edge、Opera 的 User-Agent 中同时包含 Chrome，Chrome 的包含 Safari
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// iPhone、iPad、Android 的 User-Agent 中同时包含 Mac OS X、Linux
+var platforms = []struct {
+	token string
+	name  string
+}{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceName 根据 User-Agent 生成便于用户识别的设备名称，例如 "Chrome on Windows"，
+// 无法识别浏览器时使用第一个产品标识，例如 grpc-go/1.60.0 返回 "grpc-go"
+func DeviceName(ua string) string {
+	var browser, platform string
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, p := range platforms {
+		if strings.Contains(ua, p.token) {
+			platform = p.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	product, _, _ := strings.Cut(strings.TrimSpace(ua), "/")
+	product, _, _ = strings.Cut(product, " ")
+	if product == "" {
+		return "Unknown device"
+	}
+	return product
+}